@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var ctx = context.Background()
+
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("failed to migrate audit table: %v", err)
+	}
+	return db
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := Diff(
+		[]byte(`{"id":1,"email":"a@x.com","age":30,"tags":["a"],"big":9007199254740993}`),
+		[]byte(`{"id":1, "email":"b@x.com","age":30,"tags":["a"],"big":9007199254740993,"nickname":null}`),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Field: "email", Before: []byte(`"a@x.com"`), After: []byte(`"b@x.com"`)},
+		{Field: "nickname", After: []byte(`null`)},
+	}, changes)
+
+	changes, err = Diff(nil, []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{Field: "id", After: []byte(`1`)}}, changes)
+
+	changes, err = Diff([]byte(`{"id":1}`), []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = Diff([]byte(`not json`), nil)
+	assert.Error(t, err)
+}
+
+func TestRecordAndFind(t *testing.T) {
+	db := setupDB(t)
+
+	alice := WithActor(ctx, "alice")
+	assert.NoError(t, Record(alice, db, "User", "1", OpCreate, nil, []byte(`{"id":1,"email":"a"}`)))
+	assert.NoError(t, Record(ctx, db, "User", "1", OpUpdate, []byte(`{"id":1,"email":"a"}`), []byte(`{"id":1,"email":"b"}`)))
+	assert.NoError(t, Record(alice, db, "User", "1", OpUpdate, []byte(`{"id":1}`), []byte(`{"id":1}`)))
+	assert.NoError(t, Record(alice, db, "User", "2", OpCreate, nil, []byte(`{"id":2}`)))
+	assert.NoError(t, Record(alice, db, "Order", "1", OpDelete, []byte(`{"id":1}`), nil))
+
+	entries, err := Find(ctx, db, Query{EntityType: "User", EntityID: "1"})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, OpCreate, entries[0].Operation)
+		assert.Equal(t, "alice", entries[0].Actor)
+		assert.Len(t, entries[0].Changes, 2)
+		assert.Equal(t, OpUpdate, entries[1].Operation)
+		assert.Empty(t, entries[1].Actor)
+		assert.Equal(t, []Change{{Field: "email", Before: []byte(`"a"`), After: []byte(`"b"`)}}, entries[1].Changes)
+	}
+
+	entries, err = Find(ctx, db, Query{Actor: "alice", Operation: OpCreate})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = Find(ctx, db, Query{Limit: 2, Offset: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "2", entries[0].EntityID)
+		assert.Equal(t, "Order", entries[1].EntityType)
+	}
+
+	entries, err = Find(ctx, db, Query{Since: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestActorFrom(t *testing.T) {
+	_, ok := ActorFrom(ctx)
+	assert.False(t, ok)
+	_, ok = ActorFrom(WithActor(ctx, ""))
+	assert.False(t, ok)
+	actor, ok := ActorFrom(WithActor(ctx, "svc"))
+	assert.True(t, ok)
+	assert.Equal(t, "svc", actor)
+}
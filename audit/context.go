@@ -0,0 +1,19 @@
+package audit
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the actor recorded on the changes
+// made with it, such as a user ID or a service name.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFrom(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
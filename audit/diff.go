@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Change is the value of one JSON member of an entity before and after a
+// change. Before is null for creations and After for deletions.
+type Change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Diff compares two JSON objects member by member and returns the members
+// whose value differs, sorted by name. Either document may be nil.
+func Diff(before []byte, after []byte) ([]Change, error) {
+	b, err := members(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := members(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(b)+len(a))
+	for name := range b {
+		names = append(names, name)
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []Change{}
+	for _, name := range names {
+		if bytes.Equal(b[name], a[name]) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Before: b[name], After: a[name]})
+	}
+	return changes, nil
+}
+
+// members decodes a JSON object, re-encoding each member so equal values
+// compare equal byte for byte.
+func members(doc []byte) (map[string]json.RawMessage, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &raw); err != nil {
+		return nil, err
+	}
+	for name, value := range raw {
+		var v interface{}
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&v); err != nil {
+			return nil, err
+		}
+		normalized, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		raw[name] = normalized
+	}
+	return raw, nil
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Operations recorded by the generic repository. They have the same values
+// as the operations of outbox events.
+const (
+	OpCreate      = "create"
+	OpUpdate      = "update"
+	OpUpdateField = "update_field"
+	OpDelete      = "delete"
+	OpRestore     = "restore"
+)
+
+// Entry records one change of an entity: who made it, when, and the
+// members that changed.
+type Entry struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EntityType string    `gorm:"size:255;index:idx_audit_entity;not null" json:"entity_type"`
+	EntityID   string    `gorm:"size:255;index:idx_audit_entity;not null" json:"entity_id"`
+	Operation  string    `gorm:"size:32;not null" json:"operation"`
+	Actor      string    `gorm:"size:255;index" json:"actor,omitempty"`
+	Changes    []Change  `gorm:"serializer:json" json:"changes"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+func (Entry) TableName() string {
+	return "audit_entries"
+}
+
+// Migrate creates or updates the audit table.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Entry{})
+}
+
+// Record writes the entry of a change through db, which should be the
+// transaction of the change, taking the actor from ctx. before and after
+// are the JSON documents of the entity around the change; nothing is
+// written when they have no differences.
+func Record(ctx context.Context, db *gorm.DB, entityType string, entityID string, operation string, before []byte, after []byte) error {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 && operation != OpCreate && operation != OpDelete {
+		return nil
+	}
+
+	actor, _ := ActorFrom(ctx)
+	return db.WithContext(ctx).Create(&Entry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  operation,
+		Actor:      actor,
+		Changes:    changes,
+		CreatedAt:  time.Now().UTC(),
+	}).Error
+}
+
+// Query selects audit entries. Zero fields do not filter.
+type Query struct {
+	EntityType string
+	EntityID   string
+	Actor      string
+	Operation  string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// Find returns the entries matching q, oldest first.
+func Find(ctx context.Context, db *gorm.DB, q Query) ([]Entry, error) {
+	query := db.WithContext(ctx).Model(&Entry{})
+	if q.EntityType != "" {
+		query = query.Where("entity_type = ?", q.EntityType)
+	}
+	if q.EntityID != "" {
+		query = query.Where("entity_id = ?", q.EntityID)
+	}
+	if q.Actor != "" {
+		query = query.Where("actor = ?", q.Actor)
+	}
+	if q.Operation != "" {
+		query = query.Where("operation = ?", q.Operation)
+	}
+	if !q.Since.IsZero() {
+		query = query.Where("created_at >= ?", q.Since)
+	}
+	if !q.Until.IsZero() {
+		query = query.Where("created_at < ?", q.Until)
+	}
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+	if q.Offset > 0 {
+		query = query.Offset(q.Offset)
+	}
+
+	entries := []Entry{}
+	err := query.Order("id").Find(&entries).Error
+	return entries, err
+}
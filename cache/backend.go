@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores cached values by key. Implementations must be safe for
+// concurrent use; a shared backend such as Redis encodes values itself.
+type Backend[V any] interface {
+	// Get reports false, and no error, when key is absent or expired.
+	Get(ctx context.Context, key string) (V, bool, error)
+	// Set stores value under key, expiring it after ttl unless ttl is 0.
+	Set(ctx context.Context, key string, value V, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Clear(ctx context.Context) error
+}
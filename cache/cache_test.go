@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+
+func TestMemory_GetSetDelete(t *testing.T) {
+	m := NewMemory[string](0)
+	assert.Equal(t, DefaultCapacity, m.capacity)
+
+	_, ok, err := m.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, m.Set(ctx, "a", "1", 0))
+	assert.NoError(t, m.Set(ctx, "b", "2", 0))
+	assert.NoError(t, m.Set(ctx, "a", "3", 0))
+	value, ok, err := m.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "3", value)
+	assert.Equal(t, 2, m.Len())
+
+	assert.NoError(t, m.Delete(ctx, "a", "missing"))
+	_, ok, _ = m.Get(ctx, "a")
+	assert.False(t, ok)
+
+	assert.NoError(t, m.Clear(ctx))
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestMemory_EvictsLeastRecentlyUsed(t *testing.T) {
+	m := NewMemory[int](2)
+
+	assert.NoError(t, m.Set(ctx, "a", 1, 0))
+	assert.NoError(t, m.Set(ctx, "b", 2, 0))
+	_, _, _ = m.Get(ctx, "a")
+	assert.NoError(t, m.Set(ctx, "c", 3, 0))
+
+	assert.Equal(t, 2, m.Len())
+	_, ok, _ := m.Get(ctx, "b")
+	assert.False(t, ok)
+	_, ok, _ = m.Get(ctx, "a")
+	assert.True(t, ok)
+	_, ok, _ = m.Get(ctx, "c")
+	assert.True(t, ok)
+}
+
+func TestMemory_TTL(t *testing.T) {
+	m := NewMemory[int](10)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	assert.NoError(t, m.Set(ctx, "short", 1, time.Minute))
+	assert.NoError(t, m.Set(ctx, "forever", 2, 0))
+
+	now = now.Add(59 * time.Second)
+	_, ok, _ := m.Get(ctx, "short")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok, _ = m.Get(ctx, "short")
+	assert.False(t, ok)
+	assert.Equal(t, 1, m.Len())
+	_, ok, _ = m.Get(ctx, "forever")
+	assert.True(t, ok)
+}
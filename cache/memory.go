@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity bounds a Memory backend created with a capacity below 1.
+const DefaultCapacity = 10000
+
+// Memory is an in-process Backend that keeps at most its capacity of
+// entries, evicting the least recently used one to make room. Expired
+// entries are dropped when they are read or evicted.
+type Memory[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	now      func() time.Time
+}
+
+type memoryEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func NewMemory[V any](capacity int) *Memory[V] {
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	return &Memory[V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (m *Memory[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var zero V
+	element, ok := m.entries[key]
+	if !ok {
+		return zero, false, nil
+	}
+	entry := element.Value.(*memoryEntry[V])
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		m.remove(element)
+		return zero, false, nil
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (m *Memory[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = m.now().Add(ttl)
+	}
+
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry[V])
+		entry.value = value
+		entry.expires = expires
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry[V]{key: key, value: value, expires: expires})
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+func (m *Memory[V]) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := m.entries[key]; ok {
+			m.remove(element)
+		}
+	}
+	return nil
+}
+
+func (m *Memory[V]) Clear(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.order.Init()
+	m.entries = make(map[string]*list.Element)
+	return nil
+}
+
+// Len returns the number of entries held, expired ones included until they
+// are dropped.
+func (m *Memory[V]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+func (m *Memory[V]) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry[V]).key)
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func formatETag(version uint64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// parseETag reads the version of a strong ETag. If-Match compares strongly
+// (RFC 9110, section 13.1.1), so weak ones are invalid.
+func parseETag(tag string) (uint64, error) {
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "W/") {
+		return 0, fmt.Errorf("%w: If-Match %q is a weak ETag", models.ErrInvalidVersion, tag)
+	}
+	version, err := strconv.ParseUint(strings.Trim(tag, `"`), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: If-Match %q", models.ErrInvalidVersion, tag)
+	}
+	return version, nil
+}
+
+// ifMatchContext returns the request context carrying the version from the
+// If-Match header, if the client sent one.
+func ifMatchContext(c *gin.Context) (context.Context, error) {
+	ctx := requestContext(c)
+	if c.Request == nil {
+		return ctx, nil
+	}
+	header := c.GetHeader("If-Match")
+	if header == "" || header == "*" {
+		return ctx, nil
+	}
+	version, err := parseETag(header)
+	if err != nil {
+		return ctx, err
+	}
+	return repository.WithExpectedVersion(ctx, version), nil
+}
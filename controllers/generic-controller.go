@@ -2,33 +2,40 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/alvarotor/entitier-go/logger"
 	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/problem"
 	"github.com/alvarotor/entitier-go/repository"
+	"github.com/alvarotor/entitier-go/services"
+	"github.com/alvarotor/entitier-go/validation"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// StatusClientClosedRequest is reported when the client went away before
+// the repository call completed.
+const StatusClientClosedRequest = models.StatusClientClosedRequest
+
 type controllerGeneric[T any, X string | uint] struct {
-	repo repository.IGenericRepo[T, X]
-	log  logger.Logger
+	service services.IGenericService[T, X]
+	log     logger.Logger
+	cfg     Config
 }
 
-func NewGenericController[T any, X string | uint](log logger.Logger, db *gorm.DB) IControllerGeneric[T, X] {
-	repo := repository.NewGenericRepository[T, X](
-		db,
-	)
+func NewGenericController[T any, X string | uint](log logger.Logger, service services.IGenericService[T, X], opts ...Option) IControllerGeneric[T, X] {
 	return &controllerGeneric[T, X]{
-		repo: repo,
-		log:  log,
+		service: service,
+		log:     log,
+		cfg:     newConfig(opts...),
 	}
 }
 
 func (u *controllerGeneric[T, X]) Create(ctx context.Context, model T) (T, error) {
-	m, err := u.repo.Create(ctx, model)
+	m, err := u.service.Create(ctx, model)
 	if err != nil {
 		u.log.Error("create", err.Error())
 		return m, err
@@ -37,6 +44,195 @@ func (u *controllerGeneric[T, X]) Create(ctx context.Context, model T) (T, error
 	return m, nil
 }
 
+func (u *controllerGeneric[T, X]) CreateHandler(c *gin.Context) {
+	var model T
+	if err := c.ShouldBindJSON(&model); err != nil {
+		handleError(c, u.log, "create", validation.FromBinding(err, &model), http.StatusBadRequest)
+		return
+	}
+
+	m, err := u.service.Create(requestContext(c), model)
+	if err != nil {
+		handleError(c, u.log, "create", err, http.StatusInternalServerError)
+		return
+	}
+
+	if version, ok := repository.VersionOf(&m); ok {
+		c.Header("ETag", formatETag(version))
+	}
+	c.JSON(http.StatusCreated, gin.H{"item": m})
+}
+
+func (u *controllerGeneric[T, X]) UpdateHandler(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "update", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	var model T
+	if err := c.ShouldBindJSON(&model); err != nil {
+		handleError(c, u.log, "update", validation.FromBinding(err, &model), http.StatusBadRequest)
+		return
+	}
+	if err := repository.SetPrimaryKey(&model, id.(X)); err != nil {
+		handleError(c, u.log, "update", err, http.StatusBadRequest)
+		return
+	}
+
+	ctx, err := ifMatchContext(c)
+	if err != nil {
+		handleError(c, u.log, "update", err, http.StatusBadRequest)
+		return
+	}
+
+	err = u.service.Update(ctx, id.(X), model)
+	if err != nil {
+		handleError(c, u.log, "update", err, http.StatusInternalServerError)
+		return
+	}
+
+	p, err := u.service.Get(ctx, id.(X), "")
+	if err != nil {
+		handleError(c, u.log, "update", err, http.StatusInternalServerError)
+		return
+	}
+
+	if version, ok := repository.VersionOf(p); ok {
+		c.Header("ETag", formatETag(version))
+	}
+	c.JSON(http.StatusOK, gin.H{"item": p})
+}
+
+// Patch applies the request body to the entity whose ID is in the URL, as
+// an RFC 7386 merge patch (also assumed for plain application/json) or an
+// RFC 6902 JSON patch depending on the Content-Type.
+func (u *controllerGeneric[T, X]) Patch(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "patch", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	patch := models.Patch{Type: c.ContentType()}
+	if patch.Type == gin.MIMEJSON {
+		patch.Type = models.MergePatchType
+	}
+	if patch.Type != models.MergePatchType && patch.Type != models.JSONPatchType {
+		handleError(c, u.log, "patch", models.ErrUnsupportedPatchType, http.StatusUnsupportedMediaType)
+		return
+	}
+	document, err := c.GetRawData()
+	if err != nil {
+		handleError(c, u.log, "patch", err, http.StatusBadRequest)
+		return
+	}
+	patch.Document = document
+
+	ctx, err := ifMatchContext(c)
+	if err != nil {
+		handleError(c, u.log, "patch", err, http.StatusBadRequest)
+		return
+	}
+
+	p, err := u.service.Patch(ctx, id.(X), patch)
+	if err != nil {
+		handleError(c, u.log, "patch", err, http.StatusInternalServerError)
+		return
+	}
+
+	if version, ok := repository.VersionOf(p); ok {
+		c.Header("ETag", formatETag(version))
+	}
+	c.JSON(http.StatusOK, gin.H{"item": p})
+}
+
+// CreateBulk creates every item of a JSON array body. Items are reported
+// individually, so one invalid row does not prevent the others from being
+// created; the response is 201 when all succeed and 207 otherwise.
+func (u *controllerGeneric[T, X]) CreateBulk(c *gin.Context) {
+	var items []T
+	if err := c.ShouldBindJSON(&items); err != nil {
+		handleError(c, u.log, "createbulk", err, http.StatusBadRequest)
+		return
+	}
+	if len(items) == 0 {
+		handleError(c, u.log, "createbulk", models.ErrModelCannotBeEmpty, http.StatusBadRequest)
+		return
+	}
+
+	results, err := u.service.CreateMany(requestContext(c), items, u.cfg.batchSize())
+	if err != nil {
+		handleError(c, u.log, "createbulk", err, http.StatusInternalServerError)
+		return
+	}
+
+	failed := 0
+	response := make([]gin.H, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			failed++
+			u.log.Error("createbulk", result.Err.Error())
+			response[i] = gin.H{"index": result.Index, "err": result.Err.Error(), "code": models.AsError(result.Err).Code}
+			continue
+		}
+		response[i] = gin.H{"index": result.Index, "item": result.Item}
+	}
+
+	status := http.StatusCreated
+	if failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"results":   response,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
+
+// Upsert creates or replaces the entity identified by the URL with the JSON
+// body. It is idempotent: repeating the request leaves the same state. It
+// answers 201 when the entity was created and 200 when it was replaced,
+// honouring If-Match like UpdateHandler.
+func (u *controllerGeneric[T, X]) Upsert(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "upsert", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	var model T
+	if err := c.ShouldBindJSON(&model); err != nil {
+		handleError(c, u.log, "upsert", validation.FromBinding(err, &model), http.StatusBadRequest)
+		return
+	}
+	if err := repository.SetPrimaryKey(&model, id.(X)); err != nil {
+		handleError(c, u.log, "upsert", err, http.StatusBadRequest)
+		return
+	}
+	ctx, err := ifMatchContext(c)
+	if err != nil {
+		handleError(c, u.log, "upsert", err, http.StatusBadRequest)
+		return
+	}
+
+	var created bool
+	m, err := u.service.Upsert(repository.WithUpsertCreated(ctx, &created), model, nil, nil)
+	if err != nil {
+		handleError(c, u.log, "upsert", err, http.StatusInternalServerError)
+		return
+	}
+
+	if version, ok := repository.VersionOf(&m); ok {
+		c.Header("ETag", formatETag(version))
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"item": m})
+}
+
 func (u *controllerGeneric[T, X]) Get(c *gin.Context) {
 	id, exists := c.Get("validatedID")
 	if !exists {
@@ -52,25 +248,26 @@ func (u *controllerGeneric[T, X]) Get(c *gin.Context) {
 		preloadArg = ""
 	}
 
-	p, err := u.repo.Get(c, id.(X), preloadArg)
+	p, err := u.service.Get(requestContext(c), id.(X), preloadArg)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			handleError(c, u.log, "get", models.ErrNotFound, http.StatusNotFound)
-		} else {
-			handleError(c, u.log, "get", err, http.StatusInternalServerError)
-		}
+		handleError(c, u.log, "get", err, http.StatusInternalServerError)
 		return
 	}
 
+	if version, ok := repository.VersionOf(p); ok {
+		c.Header("ETag", formatETag(version))
+	}
 	c.JSON(http.StatusOK, gin.H{"item": p})
 }
 
 func (u *controllerGeneric[T, X]) GetAll(c *gin.Context) {
-	ps, err := u.repo.GetAll(c)
-	if errors.Is(err, models.ErrNotFound) {
-		handleError(c, u.log, "getall", err, http.StatusNotFound)
+	opts, err := parseListOptions(c)
+	if err != nil {
+		handleError(c, u.log, "getall", err, http.StatusBadRequest)
 		return
 	}
+
+	ps, err := u.service.GetAll(requestContext(c), opts...)
 	if err != nil {
 		handleError(c, u.log, "getall", err, http.StatusInternalServerError)
 		return
@@ -79,14 +276,81 @@ func (u *controllerGeneric[T, X]) GetAll(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"all": ps})
 }
 
+func (u *controllerGeneric[T, X]) GetAllPaged(c *gin.Context) {
+	page, pageSize, err := parsePageParams(c, u.cfg)
+	if err != nil {
+		handleError(c, u.log, "getallpaged", err, http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseListOptions(c)
+	if err != nil {
+		handleError(c, u.log, "getallpaged", err, http.StatusBadRequest)
+		return
+	}
+
+	ps, total, err := u.service.GetAllPaged(requestContext(c), page, pageSize, opts...)
+	if err != nil {
+		handleError(c, u.log, "getallpaged", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items":      ps,
+		"pagination": buildPagination(c, page, pageSize, total),
+	})
+}
+
+func (u *controllerGeneric[T, X]) GetAllCursor(c *gin.Context) {
+	limit, err := parseLimitParam(c, u.cfg)
+	if err != nil {
+		handleError(c, u.log, "getallcursor", err, http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		handleError(c, u.log, "getallcursor", err, http.StatusBadRequest)
+		return
+	}
+
+	ps, next, err := u.service.GetAllCursor(requestContext(c), c.Query("cursor"), limit, c.Query("sort"), opts...)
+	if err != nil {
+		handleError(c, u.log, "getallcursor", err, http.StatusInternalServerError)
+		return
+	}
+
+	response := gin.H{"items": ps}
+	if next != "" {
+		response["next_cursor"] = next
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 func (u *controllerGeneric[T, X]) Delete(c *gin.Context) {
 	id, exists := c.Get("validatedID")
 	if !exists {
-		c.JSON(http.StatusBadRequest, gin.H{"err": models.ErrMustProvideValidID.Error()})
+		handleError(c, u.log, "delete", models.ErrMustProvideValidID, http.StatusBadRequest)
 		return
 	}
 
-	err := u.repo.Delete(c, id.(X), true)
+	permanently := false
+	if raw := c.Query("permanent"); raw != "" {
+		p, err := strconv.ParseBool(raw)
+		if err != nil {
+			handleError(c, u.log, "delete", models.ErrInvalidPermanentFlag, http.StatusBadRequest)
+			return
+		}
+		permanently = p
+	}
+
+	ctx, err := ifMatchContext(c)
+	if err != nil {
+		handleError(c, u.log, "delete", err, http.StatusBadRequest)
+		return
+	}
+
+	err = u.service.Delete(ctx, id.(X), permanently)
 	if err != nil {
 		handleError(c, u.log, "delete", err, http.StatusInternalServerError)
 		return
@@ -95,10 +359,158 @@ func (u *controllerGeneric[T, X]) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "deleted"})
 }
 
+func (u *controllerGeneric[T, X]) Restore(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "restore", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	err := u.service.Restore(requestContext(c), id.(X))
+	if err != nil {
+		handleError(c, u.log, "restore", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "restored"})
+}
+
+// History lists the audit entries of the entity whose ID is in the URL,
+// oldest first, including those of deleted entities.
+func (u *controllerGeneric[T, X]) History(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "history", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	entries, err := u.service.History(requestContext(c), id.(X))
+	if err != nil {
+		handleError(c, u.log, "history", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": entries})
+}
+
+// GetAsOf returns the entity whose ID is in the URL as it was at the RFC
+// 3339 time of the at query parameter.
+func (u *controllerGeneric[T, X]) GetAsOf(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "getasof", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, c.Query("at"))
+	if err != nil {
+		handleError(c, u.log, "getasof", fmt.Errorf("%w: at %q", models.ErrInvalidTimestamp, c.Query("at")), http.StatusBadRequest)
+		return
+	}
+
+	p, err := u.service.GetAsOf(requestContext(c), id.(X), at)
+	if err != nil {
+		handleError(c, u.log, "getasof", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": p})
+}
+
+// ListVersions lists the previous versions of the entity whose ID is in the
+// URL, oldest first, including those of deleted entities.
+func (u *controllerGeneric[T, X]) ListVersions(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "listversions", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	versions, err := u.service.ListVersions(requestContext(c), id.(X))
+	if err != nil {
+		handleError(c, u.log, "listversions", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": versions})
+}
+
+// Revert writes the version in the URL back over the entity whose ID is in
+// the URL, honouring If-Match, and returns the entity as stored afterwards.
+func (u *controllerGeneric[T, X]) Revert(c *gin.Context) {
+	id, exists := c.Get("validatedID")
+	if !exists {
+		handleError(c, u.log, "revert", models.ErrMustProvideValidID, http.StatusBadRequest)
+		return
+	}
+
+	version, err := strconv.ParseUint(c.Param("version"), 10, 64)
+	if err != nil || version == 0 {
+		handleError(c, u.log, "revert", fmt.Errorf("%w: %q", models.ErrInvalidVersion, c.Param("version")), http.StatusBadRequest)
+		return
+	}
+
+	ctx, err := ifMatchContext(c)
+	if err != nil {
+		handleError(c, u.log, "revert", err, http.StatusBadRequest)
+		return
+	}
+
+	p, err := u.service.Revert(ctx, id.(X), version)
+	if err != nil {
+		handleError(c, u.log, "revert", err, http.StatusInternalServerError)
+		return
+	}
+
+	if version, ok := repository.VersionOf(p); ok {
+		c.Header("ETag", formatETag(version))
+	}
+	c.JSON(http.StatusOK, gin.H{"item": p})
+}
+
+func (u *controllerGeneric[T, X]) GetAllTrashed(c *gin.Context) {
+	opts, err := parseListOptions(c)
+	if err != nil {
+		handleError(c, u.log, "getalltrashed", err, http.StatusBadRequest)
+		return
+	}
+
+	ps, err := u.service.GetAllTrashed(requestContext(c), opts...)
+	if err != nil {
+		handleError(c, u.log, "getalltrashed", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"all": ps})
+}
+
+// Purge permanently removes rows soft deleted longer ago than the
+// older_than query parameter, a Go duration such as 720h. Without it every
+// soft-deleted row is purged.
+func (u *controllerGeneric[T, X]) Purge(c *gin.Context) {
+	var olderThan time.Duration
+	if raw := c.Query("older_than"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			handleError(c, u.log, "purge", models.ErrInvalidDuration, http.StatusBadRequest)
+			return
+		}
+		olderThan = d
+	}
+
+	purged, err := u.service.Purge(requestContext(c), time.Now().Add(-olderThan))
+	if err != nil {
+		handleError(c, u.log, "purge", err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
+
 func (u *controllerGeneric[T, X]) Update(ctx context.Context, id X, model T) (int, error) {
-	err := u.repo.Update(ctx, id, model)
+	err := u.service.Update(ctx, id, model)
 	if err != nil {
-		return http.StatusInternalServerError, err
+		return httpError(err, http.StatusInternalServerError).Status, err
 	}
 
 	return http.StatusOK, nil
@@ -106,5 +518,25 @@ func (u *controllerGeneric[T, X]) Update(ctx context.Context, id X, model T) (in
 
 func handleError(c *gin.Context, log logger.Logger, id string, err error, statusCode int) {
 	log.Error(id, err.Error())
-	c.JSON(statusCode, gin.H{"err": err.Error()})
+	problem.Write(c, httpError(err, statusCode))
+}
+
+// requestContext returns the context of the underlying HTTP request so that
+// client disconnects and deadlines reach the repository.
+func requestContext(c *gin.Context) context.Context {
+	if c.Request != nil {
+		return c.Request.Context()
+	}
+	return c
+}
+
+// httpError maps err with models.AsError. Errors the mapper does not know,
+// such as JSON binding failures, keep the client error status chosen by the
+// handler.
+func httpError(err error, statusCode int) *models.Error {
+	e := models.AsError(err)
+	if e.Code == models.CodeInternal && statusCode < http.StatusInternalServerError {
+		return models.NewError(models.CodeInvalidRequest, statusCode, err)
+	}
+	return e
 }
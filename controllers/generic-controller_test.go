@@ -6,12 +6,21 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/middleware"
 	"github.com/alvarotor/entitier-go/mocks"
 	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/openapi"
+	"github.com/alvarotor/entitier-go/repository"
+	"github.com/alvarotor/entitier-go/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
@@ -23,8 +32,16 @@ func createMockGinContext() (*gin.Context, *httptest.ResponseRecorder) {
 	return c, w
 }
 
+func problemBody(status int, code string, detail string, instance string) string {
+	body := fmt.Sprintf(`{"type":"about:blank","title":%q,"status":%d,"detail":%q,"code":%q`, http.StatusText(status), status, detail, code)
+	if instance != "" {
+		body += fmt.Sprintf(`,"instance":%q`, instance)
+	}
+	return body + "}"
+}
+
 func TestController_GetAll_Success(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	testModels := []*mocks.TestModel{
@@ -33,8 +50,8 @@ func TestController_GetAll_Success(t *testing.T) {
 	}
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
@@ -50,14 +67,14 @@ func TestController_GetAll_Success(t *testing.T) {
 }
 
 func TestController_GetAll_NotFound(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	mockLogger.On("Error", "getall", models.ErrNotFound.Error()).Return(nil)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
@@ -68,20 +85,20 @@ func TestController_GetAll_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	expectedBody := fmt.Sprintf(`{"err":"%s"}`, models.ErrNotFound.Error())
+	expectedBody := problemBody(http.StatusNotFound, models.CodeNotFound, models.ErrNotFound.Error(), "")
 	assert.JSONEq(t, expectedBody, w.Body.String())
 }
 
 func TestController_GetAll_InternalError(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	err := errors.New("database error")
 	mockLogger.On("Error", "getall", err.Error()).Return(nil)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
@@ -92,12 +109,12 @@ func TestController_GetAll_InternalError(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	expectedBody := fmt.Sprintf(`{"err":"%s"}`, err.Error())
+	expectedBody := problemBody(http.StatusInternalServerError, models.CodeInternal, err.Error(), "")
 	assert.JSONEq(t, expectedBody, w.Body.String())
 }
 
 func TestController_Create_Success(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	inputModel := mocks.TestModel{Email: "test@example.com"}
@@ -106,8 +123,8 @@ func TestController_Create_Success(t *testing.T) {
 	mockService.On("Create", ctx, inputModel).Return(createdModel, nil)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	result, err := ctrl.Create(ctx, inputModel)
@@ -117,7 +134,7 @@ func TestController_Create_Success(t *testing.T) {
 }
 
 func TestController_Create_Failure(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	inputModel := mocks.TestModel{Email: "test@example.com"}
@@ -127,8 +144,8 @@ func TestController_Create_Failure(t *testing.T) {
 	mockService.On("Create", ctx, inputModel).Return(inputModel, err)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	result, errCreate := ctrl.Create(ctx, inputModel)
@@ -139,14 +156,14 @@ func TestController_Create_Failure(t *testing.T) {
 }
 
 func TestController_Get_Success(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	testModel := &mocks.TestModel{ID: 1, Email: "test1@example.com"}
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
@@ -174,29 +191,29 @@ func TestController_Get_NotFoundVariants(t *testing.T) {
 		{
 			"Model Not Found",
 			models.ErrNotFound,
-			`{"err":"` + models.ErrNotFound.Error() + `"}`,
-			http.StatusInternalServerError,
+			problemBody(http.StatusNotFound, models.CodeNotFound, models.ErrNotFound.Error(), ""),
+			http.StatusNotFound,
 			models.ErrNotFound.Error(),
 		},
 		{
 			"GORM Record Not Found",
 			gorm.ErrRecordNotFound,
-			`{"err":"` + models.ErrNotFound.Error() + `"}`,
+			problemBody(http.StatusNotFound, models.CodeNotFound, gorm.ErrRecordNotFound.Error(), ""),
 			http.StatusNotFound,
-			models.ErrNotFound.Error(),
+			gorm.ErrRecordNotFound.Error(),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 			mockLogger := &mocks.Logger{}
 
 			mockLogger.On("Error", "get", tt.loggedError).Return(nil)
 
 			ctrl := &controllerGeneric[mocks.TestModel, uint]{
-				repo: mockService,
-				log:  mockLogger,
+				service: mockService,
+				log:     mockLogger,
 			}
 
 			c, w := createMockGinContext()
@@ -214,19 +231,19 @@ func TestController_Get_NotFoundVariants(t *testing.T) {
 }
 
 func TestController_Delete_Success(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
 
 	c.Set("validatedID", uint(1))
 
-	mockService.On("Delete", c, uint(1), true).Return(nil)
+	mockService.On("Delete", c, uint(1), false).Return(nil)
 
 	ctrl.Delete(c)
 
@@ -237,32 +254,32 @@ func TestController_Delete_Success(t *testing.T) {
 }
 
 func TestController_Delete_Failure(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	mockLogger.On("Error", "delete", models.ErrNotFound.Error()).Return(nil)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
 
 	c.Set("validatedID", uint(1))
 
-	mockService.On("Delete", c, uint(1), true).Return(models.ErrNotFound)
+	mockService.On("Delete", c, uint(1), false).Return(models.ErrNotFound)
 
 	ctrl.Delete(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	expectedBody := fmt.Sprintf(`{"err":"%s"}`, models.ErrNotFound.Error())
+	expectedBody := problemBody(http.StatusNotFound, models.CodeNotFound, models.ErrNotFound.Error(), "")
 	assert.JSONEq(t, expectedBody, w.Body.String())
 }
 
 func TestController_Update_Success(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	model := mocks.TestModel{ID: 1, Email: "test@example.com"}
@@ -270,8 +287,8 @@ func TestController_Update_Success(t *testing.T) {
 	mockService.On("Update", ctx, uint(1), model).Return(nil)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	status, err := ctrl.Update(ctx, uint(1), model)
@@ -281,7 +298,7 @@ func TestController_Update_Success(t *testing.T) {
 }
 
 func TestController_Update_Failure(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	model := mocks.TestModel{ID: 1, Email: "test@example.com"}
@@ -290,8 +307,8 @@ func TestController_Update_Failure(t *testing.T) {
 	mockService.On("Update", ctx, uint(1), model).Return(errUpdate)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	status, err := ctrl.Update(ctx, uint(1), model)
@@ -302,14 +319,14 @@ func TestController_Update_Failure(t *testing.T) {
 }
 
 func TestController_Get_ValidatedIDDoesNotExist(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
 	mockLogger.On("Error", "get", models.ErrMustProvideValidID.Error()).Return(nil)
 
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
@@ -317,17 +334,19 @@ func TestController_Get_ValidatedIDDoesNotExist(t *testing.T) {
 	ctrl.Get(c)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := fmt.Sprintf(`{"err":"%s"}`, models.ErrMustProvideValidID.Error())
+	expectedBody := problemBody(http.StatusBadRequest, models.CodeInvalidID, models.ErrMustProvideValidID.Error(), "")
 	assert.JSONEq(t, expectedBody, w.Body.String())
 }
 
 func TestController_Delete_ValidatedIDDoesNotExist(t *testing.T) {
-	mockService := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
 	mockLogger := &mocks.Logger{}
 
+	mockLogger.On("Error", "delete", models.ErrMustProvideValidID.Error()).Return(nil)
+
 	ctrl := &controllerGeneric[mocks.TestModel, uint]{
-		repo: mockService,
-		log:  mockLogger,
+		service: mockService,
+		log:     mockLogger,
 	}
 
 	c, w := createMockGinContext()
@@ -335,6 +354,1501 @@ func TestController_Delete_ValidatedIDDoesNotExist(t *testing.T) {
 	ctrl.Delete(c)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	expectedBody := fmt.Sprintf(`{"err":"%s"}`, models.ErrMustProvideValidID.Error())
+	expectedBody := problemBody(http.StatusBadRequest, models.CodeInvalidID, models.ErrMustProvideValidID.Error(), "")
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_GetAllPaged_Success(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	testModels := []*mocks.TestModel{
+		{ID: 3, Email: "test3@example.com"},
+		{ID: 4, Email: "test4@example.com"},
+	}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+		cfg:     newConfig(),
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?page=2&page_size=2", nil)
+
+	mockService.On("GetAllPaged", c.Request.Context(), 2, 2).Return(testModels, int64(5), nil)
+
+	ctrl.GetAllPaged(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	expectedBody := `{
+		"items":[{"ID":3,"Email":"test3@example.com"},{"ID":4,"Email":"test4@example.com"}],
+		"pagination":{
+			"total":5,"page":2,"page_size":2,"pages":3,
+			"next":"/users?page=3&page_size=2",
+			"prev":"/users?page=1&page_size=2"
+		}
+	}`
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_GetAllPaged_ClampsPageSize(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+		cfg:     newConfig(WithMaxPageSize(50)),
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?page_size=1000", nil)
+
+	mockService.On("GetAllPaged", c.Request.Context(), 1, 50).Return([]*mocks.TestModel{}, int64(0), nil)
+
+	ctrl.GetAllPaged(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedBody := `{"items":[],"pagination":{"total":0,"page":1,"page_size":50,"pages":0}}`
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_GetAllPaged_InvalidParams(t *testing.T) {
+	for _, query := range []string{"page=0", "page=abc", "page_size=-1"} {
+		t.Run(query, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "getallpaged", models.ErrInvalidPagination.Error()).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodGet, "/users?"+query, nil)
+
+			ctrl.GetAllPaged(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			expectedBody := problemBody(http.StatusBadRequest, models.CodeInvalidPagination, models.ErrInvalidPagination.Error(), "/users")
+			assert.JSONEq(t, expectedBody, w.Body.String())
+			mockService.AssertNotCalled(t, "GetAllPaged")
+		})
+	}
+}
+
+func TestController_GetAllPaged_InternalError(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	err := errors.New("database error")
+	mockLogger.On("Error", "getallpaged", err.Error()).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users", nil)
+
+	mockService.On("GetAllPaged", c.Request.Context(), 1, DefaultPageSize).Return(nil, int64(0), err)
+
+	ctrl.GetAllPaged(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestController_GetAllCursor_Success(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	testModels := []*mocks.TestModel{
+		{ID: 1, Email: "test1@example.com"},
+	}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?cursor=abc&limit=1&sort=-email", nil)
+
+	mockService.On("GetAllCursor", c.Request.Context(), "abc", 1, "-email").Return(testModels, "def", nil)
+
+	ctrl.GetAllCursor(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedBody := `{"items":[{"ID":1,"Email":"test1@example.com"}],"next_cursor":"def"}`
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_GetAllCursor_LastPage(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users", nil)
+
+	mockService.On("GetAllCursor", c.Request.Context(), "", DefaultPageSize, "").Return([]*mocks.TestModel{}, "", nil)
+
+	ctrl.GetAllCursor(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[]}`, w.Body.String())
+}
+
+func TestController_GetAllCursor_InvalidCursor(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "getallcursor", models.ErrInvalidCursor.Error()).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?cursor=bad", nil)
+
+	mockService.On("GetAllCursor", c.Request.Context(), "bad", DefaultPageSize, "").Return(nil, "", models.ErrInvalidCursor)
+
+	ctrl.GetAllCursor(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	expectedBody := problemBody(http.StatusBadRequest, models.CodeInvalidCursor, models.ErrInvalidCursor.Error(), "/users")
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_GetAll_Filters(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	testModels := []*mocks.TestModel{
+		{ID: 1, Email: "foo@x.com"},
+	}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?email=foo@x.com&ID[gte]=1&sort=email", nil)
+
+	mockService.On("GetAll", c.Request.Context(),
+		models.Filter{Field: "ID", Operator: models.FilterGte, Value: "1"},
+		models.Filter{Field: "email", Operator: models.FilterEq, Value: "foo@x.com"},
+		models.Sort{Field: "email"},
+	).Return(testModels, nil)
+
+	ctrl.GetAll(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	expectedBody := `{"all":[{"ID":1,"Email":"foo@x.com"}]}`
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_GetAll_InvalidFilter(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "getall", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?email[regex]=foo", nil)
+
+	unknownOperator := fmt.Errorf("%w: unknown operator regex", models.ErrInvalidFilter)
+	mockService.On("GetAll", c.Request.Context(),
+		models.Filter{Field: "email", Operator: "regex", Value: "foo"},
+	).Return(nil, unknownOperator)
+
+	ctrl.GetAll(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	expectedBody := problemBody(http.StatusBadRequest, models.CodeInvalidFilter, unknownOperator.Error(), "/users")
 	assert.JSONEq(t, expectedBody, w.Body.String())
 }
+
+func TestController_GetAll_MalformedFilterKey(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "getall", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?e-mail=1", nil)
+
+	ctrl.GetAll(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetAll")
+}
+
+func TestController_GetAll_Sort(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?sort=-created_at,email", nil)
+
+	mockService.On("GetAll", c.Request.Context(),
+		models.Sort{Field: "created_at", Desc: true},
+		models.Sort{Field: "email"},
+	).Return([]*mocks.TestModel{{ID: 1, Email: "a@x.com"}}, nil)
+
+	ctrl.GetAll(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestController_GetAll_InvalidSort(t *testing.T) {
+	for _, tt := range []struct {
+		query   string
+		repoErr error
+	}{
+		{"sort=email,,id", nil},
+		{"sort=-", nil},
+		{"sort=password", fmt.Errorf("%w: password", models.ErrUnknownField)},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "getall", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodGet, "/users?"+tt.query, nil)
+
+			if tt.repoErr != nil {
+				mockService.On("GetAll", c.Request.Context(), mock.Anything).Return(nil, tt.repoErr)
+			}
+
+			ctrl.GetAll(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestController_Get_ContextErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+	}{
+		{"Canceled", fmt.Errorf("%w: %w", models.ErrRequestCanceled, context.Canceled), StatusClientClosedRequest},
+		{"Deadline", fmt.Errorf("%w: %w", models.ErrDeadlineExceeded, context.DeadlineExceeded), http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "get", tt.mockError.Error()).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			c.Set("validatedID", uint(1))
+
+			mockService.On("Get", c.Request.Context(), uint(1), "").Return(nil, tt.mockError)
+
+			ctrl.Get(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestController_GetAll_UsesRequestContext(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(reqCtx)
+
+	mockService.On("GetAll", reqCtx).Return([]*mocks.TestModel{{ID: 1, Email: "a@x.com"}}, nil)
+
+	ctrl.GetAll(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestController_Update_ContextCanceled(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	model := mocks.TestModel{ID: 1, Email: "test@example.com"}
+
+	mockService.On("Update", ctx, uint(1), model).Return(models.ErrRequestCanceled)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	status, err := ctrl.Update(ctx, uint(1), model)
+
+	assert.Error(t, err)
+	assert.Equal(t, StatusClientClosedRequest, status)
+}
+
+type versionedModel struct {
+	ID      uint
+	Email   string
+	Version uint `entitier:"version"`
+}
+
+func TestController_Get_ETag(t *testing.T) {
+	mockService := new(mocks.IGenericService[versionedModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[versionedModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Set("validatedID", uint(1))
+
+	mockService.On("Get", c, uint(1), "").Return(&versionedModel{ID: 1, Email: "a@x.com", Version: 3}, nil)
+
+	ctrl.Get(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestController_Delete_IfMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		ifMatch      string
+		mockError    error
+		expectedCode int
+	}{
+		{"Matching version", `"3"`, nil, http.StatusOK},
+		{"Weak ETag", `W/"3"`, nil, http.StatusBadRequest},
+		{"Stale version", `"3"`, models.ErrConflict, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[versionedModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "delete", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[versionedModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+			c.Request.Header.Set("If-Match", tt.ifMatch)
+			c.Set("validatedID", uint(1))
+
+			withVersion := mock.MatchedBy(func(ctx context.Context) bool {
+				version, ok := repository.ExpectedVersion(ctx)
+				return ok && version == 3
+			})
+			mockService.On("Delete", withVersion, uint(1), false).Return(tt.mockError)
+
+			ctrl.Delete(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestController_Delete_InvalidIfMatch(t *testing.T) {
+	mockService := new(mocks.IGenericService[versionedModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "delete", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[versionedModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	c.Request.Header.Set("If-Match", `"abc"`)
+	c.Set("validatedID", uint(1))
+
+	ctrl.Delete(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Delete")
+}
+
+func TestController_Update_Conflict(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	model := mocks.TestModel{ID: 1, Email: "test@example.com"}
+
+	mockService.On("Update", ctx, uint(1), model).Return(models.ErrConflict)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	status, err := ctrl.Update(ctx, uint(1), model)
+
+	assert.True(t, errors.Is(err, models.ErrConflict))
+	assert.Equal(t, http.StatusConflict, status)
+}
+
+func TestController_Delete_Permanent(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodDelete, "/users/1?permanent=true", nil)
+	c.Set("validatedID", uint(1))
+
+	mockService.On("Delete", c.Request.Context(), uint(1), true).Return(nil)
+
+	ctrl.Delete(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestController_Delete_InvalidPermanent(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "delete", models.ErrInvalidPermanentFlag.Error()).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodDelete, "/users/1?permanent=maybe", nil)
+	c.Set("validatedID", uint(1))
+
+	ctrl.Delete(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Delete")
+}
+
+func TestController_Restore(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{"Success", nil, http.StatusOK, `{"message":"restored"}`},
+		{"Not trashed", models.ErrNotFound, http.StatusNotFound, problemBody(http.StatusNotFound, models.CodeNotFound, models.ErrNotFound.Error(), "")},
+		{"Not supported", models.ErrSoftDeleteNotSupported, http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeSoftDeleteDisabled, models.ErrSoftDeleteNotSupported.Error(), "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "restore", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Set("validatedID", uint(1))
+
+			mockService.On("Restore", c, uint(1)).Return(tt.mockError)
+
+			ctrl.Restore(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestController_GetAllTrashed(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodGet, "/users/trash?email=a@x.com", nil)
+
+	mockService.On("GetAllTrashed", c.Request.Context(),
+		models.Filter{Field: "email", Operator: models.FilterEq, Value: "a@x.com"},
+	).Return([]*mocks.TestModel{{ID: 1, Email: "a@x.com"}}, nil)
+
+	ctrl.GetAllTrashed(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"all":[{"ID":1,"Email":"a@x.com"}]}`, w.Body.String())
+}
+
+func TestController_Purge(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodDelete, "/users/trash?older_than=720h", nil)
+
+	before := time.Now().Add(-720 * time.Hour)
+	cutoff := mock.MatchedBy(func(olderThan time.Time) bool {
+		return !olderThan.Before(before) && olderThan.Before(before.Add(time.Minute))
+	})
+	mockService.On("Purge", c.Request.Context(), cutoff).Return(int64(4), nil)
+
+	ctrl.Purge(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"purged":4}`, w.Body.String())
+}
+
+func TestController_Purge_InvalidDuration(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "purge", models.ErrInvalidDuration.Error()).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodDelete, "/users/trash?older_than=month", nil)
+
+	ctrl.Purge(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Purge")
+}
+
+func TestController_CreateBulk(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "createbulk", "duplicated").Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+		cfg:     newConfig(WithBatchSize(50)),
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPost, "/users/bulk", strings.NewReader(`[{"Email":"a@x.com"},{"Email":"b@x.com"}]`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	items := []mocks.TestModel{{Email: "a@x.com"}, {Email: "b@x.com"}}
+	mockService.On("CreateMany", c.Request.Context(), items, 50).Return([]models.BatchResult[mocks.TestModel]{
+		{Index: 0, Item: mocks.TestModel{ID: 1, Email: "a@x.com"}},
+		{Index: 1, Item: mocks.TestModel{Email: "b@x.com"}, Err: errors.New("duplicated")},
+	}, nil)
+
+	ctrl.CreateBulk(c)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	expectedBody := `{
+		"results":[{"index":0,"item":{"ID":1,"Email":"a@x.com"}},{"index":1,"err":"duplicated","code":"internal"}],
+		"succeeded":1,
+		"failed":1
+	}`
+	assert.JSONEq(t, expectedBody, w.Body.String())
+}
+
+func TestController_CreateBulk_AllCreated(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPost, "/users/bulk", strings.NewReader(`[{"Email":"a@x.com"}]`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	mockService.On("CreateMany", c.Request.Context(), []mocks.TestModel{{Email: "a@x.com"}}, DefaultBatchSize).
+		Return([]models.BatchResult[mocks.TestModel]{{Index: 0, Item: mocks.TestModel{ID: 1, Email: "a@x.com"}}}, nil)
+
+	ctrl.CreateBulk(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestController_CreateBulk_InvalidBody(t *testing.T) {
+	for _, body := range []string{`{"Email":"a@x.com"}`, `[]`} {
+		t.Run(body, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "createbulk", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodPost, "/users/bulk", strings.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			ctrl.CreateBulk(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "CreateMany")
+		})
+	}
+}
+
+func TestController_Upsert(t *testing.T) {
+	repo := repository.NewMemoryRepository[versionedModel, uint]()
+	mockLogger := &mocks.Logger{}
+	mockLogger.On("Error", "upsert", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[versionedModel, uint]{
+		service: services.NewGenericService(repo),
+		log:     mockLogger,
+	}
+
+	tests := []struct {
+		name         string
+		ifMatch      string
+		email        string
+		expectedCode int
+		expectedETag string
+	}{
+		{"Created", "", "a@x.com", http.StatusCreated, `"1"`},
+		{"Replaced", "", "b@x.com", http.StatusOK, `"2"`},
+		{"If-Match", `"2"`, "c@x.com", http.StatusOK, `"3"`},
+		{"Stale If-Match", `"2"`, "d@x.com", http.StatusConflict, ""},
+		{"Invalid If-Match", `"abc"`, "d@x.com", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodPut, "/users/5", strings.NewReader(`{"ID":9,"Email":"`+tt.email+`"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			c.Set("validatedID", uint(5))
+
+			ctrl.Upsert(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+
+	stored, err := repo.Get(context.Background(), 5, "")
+	assert.NoError(t, err)
+	assert.Equal(t, versionedModel{ID: 5, Email: "c@x.com", Version: 3}, *stored)
+}
+
+func TestController_Upsert_InvalidBody(t *testing.T) {
+	mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "upsert", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[mocks.TestModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPut, "/users/5", strings.NewReader(`not json`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("validatedID", uint(5))
+
+	ctrl.Upsert(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Upsert")
+}
+
+type boundModel struct {
+	ID    uint
+	Email string `binding:"required,email"`
+}
+
+func TestController_CreateHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockError    error
+		expectedCode int
+	}{
+		{"Created", `{"Email":"a@x.com"}`, nil, http.StatusCreated},
+		{"Duplicated", `{"Email":"a@x.com"}`, models.ErrDuplicatedKeyEmail, http.StatusConflict},
+		{"Database error", `{"Email":"a@x.com"}`, errors.New("database error"), http.StatusInternalServerError},
+		{"Invalid JSON", `{"Email":`, nil, http.StatusBadRequest},
+		{"Validation failed", `{"Email":"not-an-email"}`, nil, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[boundModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "create", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[boundModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			mockService.On("Create", c.Request.Context(), boundModel{Email: "a@x.com"}).
+				Return(boundModel{ID: 1, Email: "a@x.com"}, tt.mockError)
+
+			ctrl.CreateHandler(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusCreated {
+				assert.JSONEq(t, `{"item":{"ID":1,"Email":"a@x.com"}}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestController_UpdateHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockError    error
+		expectedCode int
+	}{
+		{"Updated", `{"ID":9,"Email":"a@x.com"}`, nil, http.StatusOK},
+		{"Not found", `{"Email":"a@x.com"}`, models.ErrNotFound, http.StatusNotFound},
+		{"Conflict", `{"Email":"a@x.com"}`, models.ErrConflict, http.StatusConflict},
+		{"Duplicated", `{"Email":"a@x.com"}`, models.ErrDuplicatedKeyEmail, http.StatusConflict},
+		{"Validation failed", `{"Email":""}`, nil, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[boundModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "update", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[boundModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodPut, "/users/5", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("validatedID", uint(5))
+
+			mockService.On("Update", c.Request.Context(), uint(5), boundModel{ID: 5, Email: "a@x.com"}).Return(tt.mockError)
+			mockService.On("Get", c.Request.Context(), uint(5), "").Return(&boundModel{ID: 5, Email: "a@x.com"}, nil)
+
+			ctrl.UpdateHandler(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, `{"item":{"ID":5,"Email":"a@x.com"}}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestController_UpdateHandler_ValidatedIDDoesNotExist(t *testing.T) {
+	mockService := new(mocks.IGenericService[boundModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "update", models.ErrMustProvideValidID.Error()).Return(nil)
+
+	ctrl := &controllerGeneric[boundModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPut, "/users/5", strings.NewReader(`{"Email":"a@x.com"}`))
+
+	ctrl.UpdateHandler(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestController_Patch(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentType  string
+		patchType    string
+		mockError    error
+		expectedCode int
+	}{
+		{"Merge patch", models.MergePatchType, models.MergePatchType, nil, http.StatusOK},
+		{"Plain JSON", "application/json; charset=utf-8", models.MergePatchType, nil, http.StatusOK},
+		{"JSON patch", models.JSONPatchType, models.JSONPatchType, nil, http.StatusOK},
+		{"Unsupported media type", "text/plain", "", nil, http.StatusUnsupportedMediaType},
+		{"Not found", models.MergePatchType, models.MergePatchType, models.ErrNotFound, http.StatusNotFound},
+		{"Invalid patch", models.MergePatchType, models.MergePatchType, models.ErrInvalidPatch, http.StatusBadRequest},
+		{"Unknown field", models.MergePatchType, models.MergePatchType, models.ErrUnknownField, http.StatusBadRequest},
+		{"Test failed", models.JSONPatchType, models.JSONPatchType, models.ErrPatchTestFailed, http.StatusConflict},
+		{"Version conflict", models.MergePatchType, models.MergePatchType, models.ErrConflict, http.StatusConflict},
+		{"Database error", models.MergePatchType, models.MergePatchType, errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[boundModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "patch", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[boundModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodPatch, "/users/5", strings.NewReader(`{"Email":"a@x.com"}`))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+			c.Set("validatedID", uint(5))
+
+			patch := models.Patch{Type: tt.patchType, Document: []byte(`{"Email":"a@x.com"}`)}
+			mockService.On("Patch", c.Request.Context(), uint(5), patch).Return(&boundModel{ID: 5, Email: "a@x.com"}, tt.mockError)
+
+			ctrl.Patch(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, `{"item":{"ID":5,"Email":"a@x.com"}}`, w.Body.String())
+				mockService.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestController_Patch_ValidatedIDDoesNotExist(t *testing.T) {
+	mockService := new(mocks.IGenericService[boundModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "patch", models.ErrMustProvideValidID.Error()).Return(nil)
+
+	ctrl := &controllerGeneric[boundModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPatch, "/users/5", strings.NewReader(`{}`))
+
+	ctrl.Patch(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func respondWith(name string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		c := args.Get(0).(*gin.Context)
+		id, _ := c.Get("validatedID")
+		c.JSON(http.StatusOK, gin.H{"handler": name, "id": id})
+	}
+}
+
+func TestRegisterResource_DefaultOperations(t *testing.T) {
+	ctrl := new(mocks.IControllerGeneric[mocks.TestModel, uint])
+	ctrl.On("GetAll", mock.Anything).Run(respondWith("GetAll")).Return()
+	ctrl.On("Get", mock.Anything).Run(respondWith("Get")).Return()
+	ctrl.On("CreateHandler", mock.Anything).Run(respondWith("CreateHandler")).Return()
+	ctrl.On("UpdateHandler", mock.Anything).Run(respondWith("UpdateHandler")).Return()
+	ctrl.On("Patch", mock.Anything).Run(respondWith("Patch")).Return()
+	ctrl.On("Delete", mock.Anything).Run(respondWith("Delete")).Return()
+
+	r := gin.New()
+	RegisterResource[mocks.TestModel, uint](r.Group("/api"), "/users", ctrl)
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{http.MethodGet, "/api/users", http.StatusOK, `{"handler":"GetAll","id":null}`},
+		{http.MethodGet, "/api/users/7", http.StatusOK, `{"handler":"Get","id":7}`},
+		{http.MethodPost, "/api/users", http.StatusOK, `{"handler":"CreateHandler","id":null}`},
+		{http.MethodPut, "/api/users/7", http.StatusOK, `{"handler":"UpdateHandler","id":7}`},
+		{http.MethodPatch, "/api/users/7", http.StatusOK, `{"handler":"Patch","id":7}`},
+		{http.MethodDelete, "/api/users/7", http.StatusOK, `{"handler":"Delete","id":7}`},
+		{http.MethodGet, "/api/users/abc", http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeInvalidID, models.ErrIDTypeMismatch.Error(), "/api/users/abc")},
+		{http.MethodGet, "/api/users/paged", http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeInvalidID, models.ErrIDTypeMismatch.Error(), "/api/users/paged")},
+		{http.MethodPost, "/api/users/7/restore", http.StatusNotFound, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRegisterResource_Options(t *testing.T) {
+	ctrl := new(mocks.IControllerGeneric[mocks.TestModel, string])
+	ctrl.On("GetAll", mock.Anything).Run(respondWith("GetAll")).Return()
+	ctrl.On("GetAllPaged", mock.Anything).Run(respondWith("GetAllPaged")).Return()
+	ctrl.On("Restore", mock.Anything).Run(respondWith("Restore")).Return()
+	ctrl.On("Get", mock.Anything).Run(func(args mock.Arguments) {
+		c := args.Get(0).(*gin.Context)
+		c.JSON(http.StatusOK, gin.H{"handler": "Get", "preload": c.GetString("preloadArg")})
+	}).Return()
+
+	var calls []string
+	r := gin.New()
+	RegisterResource[mocks.TestModel, string](r.Group(""), "/users", ctrl,
+		WithOperations(OpListPaged, OpRestore),
+		WithoutOperations(OpDelete, OpPatch),
+		OverrideOperation(OpUpdate, func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"handler": "custom"})
+		}),
+		DecorateOperation(OpGet, middleware.Preload("Orders")),
+		WithResourceMiddleware(func(c *gin.Context) {
+			calls = append(calls, c.Request.Method)
+			c.Next()
+		}),
+	)
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{http.MethodGet, "/users", http.StatusOK, `{"handler":"GetAll","id":null}`},
+		{http.MethodGet, "/users/paged", http.StatusOK, `{"handler":"GetAllPaged","id":null}`},
+		{http.MethodGet, "/users/abc", http.StatusOK, `{"handler":"Get","preload":"Orders"}`},
+		{http.MethodPost, "/users/abc/restore", http.StatusOK, `{"handler":"Restore","id":"abc"}`},
+		{http.MethodPut, "/users/abc", http.StatusOK, `{"handler":"custom"}`},
+		{http.MethodDelete, "/users/abc", http.StatusNotFound, ``},
+		{http.MethodPatch, "/users/abc", http.StatusNotFound, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+	assert.Len(t, calls, 5)
+}
+
+func TestRegisterResource_OpenAPI(t *testing.T) {
+	spec := openapi.NewSpec("users", "1.0.0")
+
+	r := gin.New()
+	RegisterResource[mocks.TestModel, uint](r.Group("/api"), "/users", new(mocks.IControllerGeneric[mocks.TestModel, uint]),
+		WithOperations(OpListPaged, OpListCursor),
+		WithoutOperations(OpPatch),
+		WithOpenAPI(spec),
+	)
+	RegisterResource[boundModel, string](r.Group("/api"), "/accounts", new(mocks.IControllerGeneric[boundModel, string]),
+		WithOpenAPI(spec),
+	)
+
+	doc := spec.Document()
+	assert.ElementsMatch(t, []string{"/api/users", "/api/users/paged", "/api/users/cursor", "/api/users/{id}", "/api/accounts", "/api/accounts/{id}"}, mapKeys(doc.Paths))
+	assert.ElementsMatch(t, []string{"get", "put", "delete"}, mapKeys(doc.Paths["/api/users/{id}"]))
+	assert.ElementsMatch(t, []string{"get", "put", "patch", "delete"}, mapKeys(doc.Paths["/api/accounts/{id}"]))
+
+	get := doc.Paths["/api/users/{id}"]["get"]
+	assert.Equal(t, "get_users", get.OperationID)
+	assert.Equal(t, "integer", get.Parameters[0].Schema.Type)
+	assert.Equal(t, "#/components/schemas/TestModel", get.Responses["200"].Content["application/json"].Schema.Properties["item"].Ref)
+	assert.Equal(t, models.ErrNotFound.Error(), get.Responses["404"].Description)
+	assert.Equal(t, "string", doc.Paths["/api/accounts/{id}"]["get"].Parameters[0].Schema.Type)
+
+	paged := doc.Paths["/api/users/paged"]["get"]
+	assert.Equal(t, "page", paged.Parameters[0].Name)
+	assert.Equal(t, "#/components/schemas/Pagination", paged.Responses["200"].Content["application/json"].Schema.Properties["pagination"].Ref)
+
+	sortDescription := func(op *openapi.Operation) string {
+		for _, param := range op.Parameters {
+			if param.Name == "sort" {
+				return param.Description
+			}
+		}
+		return ""
+	}
+	assert.Contains(t, sortDescription(doc.Paths["/api/users"]["get"]), "Comma separated fields")
+	assert.Contains(t, sortDescription(doc.Paths["/api/users/cursor"]["get"]), "One field")
+
+	assert.Contains(t, doc.Components.Schemas, "TestModel")
+	assert.Contains(t, doc.Components.Schemas, "boundModel")
+	assert.Contains(t, doc.Components.Schemas, "Problem")
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestController_CreateHandler_DuplicateKeyFields(t *testing.T) {
+	mockService := new(mocks.IGenericService[boundModel, uint])
+	mockLogger := &mocks.Logger{}
+
+	mockLogger.On("Error", "create", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[boundModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"Email":"a@x.com"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	dup := &models.DuplicateKeyError{Constraint: "uni_users_email", Columns: []string{"email"}, Fields: []string{"Email"}, Err: gorm.ErrDuplicatedKey}
+	mockService.On("Create", c.Request.Context(), boundModel{Email: "a@x.com"}).Return(boundModel{}, dup)
+
+	ctrl.CreateHandler(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type":"about:blank",
+		"title":"Conflict",
+		"status":409,
+		"detail":"duplicated key: Email already exists",
+		"instance":"/users",
+		"code":"duplicate_key",
+		"details":{"constraint":"uni_users_email","fields":["Email"]}
+	}`, w.Body.String())
+}
+
+func TestController_CreateHandler_ValidationFields(t *testing.T) {
+	type account struct {
+		ID    uint   `json:"id"`
+		Email string `json:"email" binding:"required,email"`
+		Name  string `json:"name" binding:"required"`
+	}
+
+	mockService := new(mocks.IGenericService[account, uint])
+	mockLogger := &mocks.Logger{}
+	mockLogger.On("Error", "create", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[account, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"email":"nope"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	ctrl.CreateHandler(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type":"about:blank",
+		"title":"Unprocessable Entity",
+		"status":422,
+		"detail":"validation failed: email must be a valid email address; name is required",
+		"instance":"/accounts",
+		"code":"validation_failed",
+		"details":{"fields":[
+			{"field":"email","rule":"email","message":"must be a valid email address"},
+			{"field":"name","rule":"required","message":"is required"}
+		]}
+	}`, w.Body.String())
+	mockService.AssertNotCalled(t, "Create")
+}
+
+func TestController_CreateHandler_RepositoryValidation(t *testing.T) {
+	mockService := new(mocks.IGenericService[boundModel, uint])
+	mockLogger := &mocks.Logger{}
+	mockLogger.On("Error", "create", mock.Anything).Return(nil)
+
+	ctrl := &controllerGeneric[boundModel, uint]{
+		service: mockService,
+		log:     mockLogger,
+	}
+
+	c, w := createMockGinContext()
+	c.Request = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"Email":"a@x.com"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	invalid := &models.ValidationError{Fields: []models.FieldError{{Message: "email domain is not allowed"}}}
+	mockService.On("Create", c.Request.Context(), boundModel{Email: "a@x.com"}).Return(boundModel{}, invalid)
+
+	ctrl.CreateHandler(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{
+		"type":"about:blank",
+		"title":"Unprocessable Entity",
+		"status":422,
+		"detail":"validation failed: email domain is not allowed",
+		"instance":"/users",
+		"code":"validation_failed",
+		"details":{"fields":[{"message":"email domain is not allowed"}]}
+	}`, w.Body.String())
+}
+
+func TestController_History(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []audit.Entry{{
+		ID:         1,
+		EntityType: "TestModel",
+		EntityID:   "1",
+		Operation:  audit.OpUpdate,
+		Actor:      "alice",
+		Changes:    []audit.Change{{Field: "Email", Before: []byte(`"a@x.com"`), After: []byte(`"b@x.com"`)}},
+		CreatedAt:  createdAt,
+	}}
+
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{"Success", nil, http.StatusOK, `{"items":[{
+			"id":1,"entity_type":"TestModel","entity_id":"1","operation":"update","actor":"alice",
+			"changes":[{"field":"Email","before":"a@x.com","after":"b@x.com"}],
+			"created_at":"2024-05-01T12:00:00Z"
+		}]}`},
+		{"Not enabled", models.ErrAuditNotEnabled, http.StatusNotImplemented, problemBody(http.StatusNotImplemented, models.CodeAuditDisabled, models.ErrAuditNotEnabled.Error(), "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "history", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Set("validatedID", uint(1))
+
+			if tt.mockError != nil {
+				mockService.On("History", c, uint(1)).Return(nil, tt.mockError)
+			} else {
+				mockService.On("History", c, uint(1)).Return(entries, nil)
+			}
+
+			ctrl.History(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestRegisterResource_History(t *testing.T) {
+	r := gin.New()
+	ctrl := new(mocks.IControllerGeneric[mocks.TestModel, uint])
+	ctrl.On("History", mock.Anything).Run(func(args mock.Arguments) {
+		c := args.Get(0).(*gin.Context)
+		c.JSON(http.StatusOK, gin.H{"id": c.MustGet("validatedID")})
+	}).Return()
+
+	RegisterResource[mocks.TestModel, uint](r.Group("/api"), "/users", ctrl, WithOperations(OpHistory))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/7/history", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":7}`, w.Body.String())
+}
+
+func TestController_GetAsOf(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{"Success", "at=2024-05-01T12:00:00Z", nil, http.StatusOK, `{"item":{"ID":1,"Email":"old@example.com"}}`},
+		{"Invalid time", "at=yesterday", nil, http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeInvalidParameter, `invalid timestamp: at "yesterday"`, "/users/1/as-of")},
+		{"Missing time", "", nil, http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeInvalidParameter, `invalid timestamp: at ""`, "/users/1/as-of")},
+		{"Not found", "at=2024-05-01T12:00:00Z", models.ErrNotFound, http.StatusNotFound, problemBody(http.StatusNotFound, models.CodeNotFound, models.ErrNotFound.Error(), "/users/1/as-of")},
+		{"Not enabled", "at=2024-05-01T12:00:00Z", models.ErrVersioningNotEnabled, http.StatusNotImplemented, problemBody(http.StatusNotImplemented, models.CodeVersioningDisabled, models.ErrVersioningNotEnabled.Error(), "/users/1/as-of")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "getasof", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodGet, "/users/1/as-of?"+tt.query, nil)
+			c.Set("validatedID", uint(1))
+
+			if tt.mockError != nil {
+				mockService.On("GetAsOf", mock.Anything, uint(1), at).Return(nil, tt.mockError)
+			} else {
+				mockService.On("GetAsOf", mock.Anything, uint(1), at).Return(&mocks.TestModel{ID: 1, Email: "old@example.com"}, nil)
+			}
+
+			ctrl.GetAsOf(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestController_ListVersions(t *testing.T) {
+	versions := []history.Version[mocks.TestModel]{{
+		Version:      1,
+		Operation:    history.OpUpdate,
+		SupersededAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Entity:       mocks.TestModel{ID: 1, Email: "a@x.com"},
+	}}
+
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{"Success", nil, http.StatusOK, `{"items":[{
+			"version":1,"operation":"update","superseded_at":"2024-05-01T12:00:00Z",
+			"entity":{"ID":1,"Email":"a@x.com"}
+		}]}`},
+		{"Not enabled", models.ErrVersioningNotEnabled, http.StatusNotImplemented, problemBody(http.StatusNotImplemented, models.CodeVersioningDisabled, models.ErrVersioningNotEnabled.Error(), "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "listversions", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Set("validatedID", uint(1))
+
+			if tt.mockError != nil {
+				mockService.On("ListVersions", c, uint(1)).Return(nil, tt.mockError)
+			} else {
+				mockService.On("ListVersions", c, uint(1)).Return(versions, nil)
+			}
+
+			ctrl.ListVersions(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestController_Revert(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      string
+		ifMatch      string
+		mockError    error
+		expectedCode int
+		expectedBody string
+	}{
+		{"Success", "2", "", nil, http.StatusOK, `{"item":{"ID":1,"Email":"a@x.com"}}`},
+		{"If-Match", "2", `"4"`, nil, http.StatusOK, `{"item":{"ID":1,"Email":"a@x.com"}}`},
+		{"Invalid version", "two", "", nil, http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeInvalidVersion, `invalid version: "two"`, "/users/1/versions/two/revert")},
+		{"Version zero", "0", "", nil, http.StatusBadRequest, problemBody(http.StatusBadRequest, models.CodeInvalidVersion, `invalid version: "0"`, "/users/1/versions/0/revert")},
+		{"Version not found", "2", "", models.ErrVersionNotFound, http.StatusNotFound, problemBody(http.StatusNotFound, models.CodeNotFound, models.ErrVersionNotFound.Error(), "/users/1/versions/2/revert")},
+		{"Conflict", "2", `"3"`, models.ErrConflict, http.StatusConflict, problemBody(http.StatusConflict, models.CodeVersionConflict, models.ErrConflict.Error(), "/users/1/versions/2/revert")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.IGenericService[mocks.TestModel, uint])
+			mockLogger := &mocks.Logger{}
+
+			mockLogger.On("Error", "revert", mock.Anything).Return(nil)
+
+			ctrl := &controllerGeneric[mocks.TestModel, uint]{
+				service: mockService,
+				log:     mockLogger,
+			}
+
+			c, w := createMockGinContext()
+			c.Request = httptest.NewRequest(http.MethodPost, "/users/1/versions/"+tt.version+"/revert", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+			c.Params = gin.Params{{Key: "version", Value: tt.version}}
+			c.Set("validatedID", uint(1))
+
+			call := mockService.On("Revert", mock.Anything, uint(1), uint64(2))
+			if tt.mockError != nil {
+				call.Return(nil, tt.mockError)
+			} else {
+				call.Return(&mocks.TestModel{ID: 1, Email: "a@x.com"}, nil)
+			}
+
+			ctrl.Revert(c)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			if tt.ifMatch != "" && tt.expectedCode != http.StatusBadRequest {
+				ctx := mockService.Calls[0].Arguments.Get(0).(context.Context)
+				version, ok := repository.ExpectedVersion(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, strings.Trim(tt.ifMatch, `"`), fmt.Sprint(version))
+			}
+		})
+	}
+}
+
+func TestRegisterResource_Versioning(t *testing.T) {
+	r := gin.New()
+	ctrl := new(mocks.IControllerGeneric[mocks.TestModel, uint])
+	respond := func(name string) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			c := args.Get(0).(*gin.Context)
+			c.JSON(http.StatusOK, gin.H{"handler": name, "id": c.MustGet("validatedID"), "version": c.Param("version")})
+		}
+	}
+	ctrl.On("GetAsOf", mock.Anything).Run(respond("as_of")).Return()
+	ctrl.On("ListVersions", mock.Anything).Run(respond("versions")).Return()
+	ctrl.On("Revert", mock.Anything).Run(respond("revert")).Return()
+
+	spec := openapi.NewSpec("users", "1.0.0")
+	RegisterResource[mocks.TestModel, uint](r.Group("/api"), "/users", ctrl, WithOperations(OpGetAsOf, OpListVersions, OpRevert), WithOpenAPI(spec))
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/api/users/7/as-of?at=2024-05-01T12:00:00Z", `{"handler":"as_of","id":7,"version":""}`},
+		{http.MethodGet, "/api/users/7/versions", `{"handler":"versions","id":7,"version":""}`},
+		{http.MethodPost, "/api/users/7/versions/3/revert", `{"handler":"revert","id":7,"version":"3"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, tt.path)
+		assert.JSONEq(t, tt.body, w.Body.String(), tt.path)
+	}
+
+	doc := spec.Document()
+	revert := doc.Paths["/api/users/{id}/versions/{version}/revert"]["post"]
+	if assert.NotNil(t, revert) {
+		assert.Equal(t, "version", revert.Parameters[1].Name)
+		assert.Equal(t, models.ErrVersionNotFound.Error(), revert.Responses["404"].Description)
+	}
+	asOf := doc.Paths["/api/users/{id}/as-of"]["get"]
+	if assert.NotNil(t, asOf) {
+		assert.True(t, asOf.Parameters[1].Required)
+		assert.Equal(t, "date-time", asOf.Parameters[1].Schema.Format)
+	}
+	assert.Contains(t, doc.Paths, "/api/users/{id}/versions")
+}
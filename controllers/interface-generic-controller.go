@@ -8,8 +8,22 @@ import (
 
 type IControllerGeneric[T any, X string | uint] interface {
 	GetAll(*gin.Context)
+	GetAllPaged(*gin.Context)
+	GetAllCursor(*gin.Context)
 	Create(context.Context, T) (T, error)
+	CreateHandler(*gin.Context)
+	CreateBulk(*gin.Context)
+	Upsert(*gin.Context)
 	Get(*gin.Context)
 	Delete(*gin.Context)
+	Restore(*gin.Context)
+	GetAllTrashed(*gin.Context)
+	Purge(*gin.Context)
 	Update(context.Context, X, T) (int, error)
+	UpdateHandler(*gin.Context)
+	Patch(*gin.Context)
+	History(*gin.Context)
+	GetAsOf(*gin.Context)
+	ListVersions(*gin.Context)
+	Revert(*gin.Context)
 }
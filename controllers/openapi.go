@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/openapi"
+	"github.com/alvarotor/entitier-go/problem"
+)
+
+// WithOpenAPI documents every mounted operation of the resource in spec.
+// Overridden operations are documented as the handler they replace.
+func WithOpenAPI(spec *openapi.Spec) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.spec = spec
+	}
+}
+
+func idSchema[X string | uint]() *openapi.Schema {
+	var id X
+	if _, ok := any(id).(uint); ok {
+		return &openapi.Schema{Type: "integer", Format: "int64", Minimum: new(float64)}
+	}
+	return &openapi.Schema{Type: "string"}
+}
+
+func errorResponse(spec *openapi.Spec, errs ...error) openapi.Response {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	if len(messages) == 0 {
+		messages = append(messages, "unexpected error")
+	}
+	ref := spec.AddSchema("Problem", &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"type":     {Type: "string"},
+			"title":    {Type: "string"},
+			"status":   {Type: "integer"},
+			"detail":   {Type: "string"},
+			"instance": {Type: "string"},
+			"code":     {Type: "string"},
+			"details":  {Type: "object"},
+		},
+		Required: []string{"type", "title", "status", "code"},
+	})
+	return openapi.Response{
+		Description: strings.Join(messages, "; "),
+		Content:     map[string]openapi.MediaType{problem.ContentType: {Schema: ref}},
+	}
+}
+
+func objectSchema(properties map[string]*openapi.Schema, required ...string) *openapi.Schema {
+	return &openapi.Schema{Type: "object", Properties: properties, Required: required}
+}
+
+func queryParameter(name string, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func headerParameter(name string, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "header", Description: description, Schema: &openapi.Schema{Type: "string"}}
+}
+
+// describeOperation builds the OpenAPI operation served by the default
+// handler of op for a resource of T identified by X.
+func describeOperation[T any, X string | uint](spec *openapi.Spec, tag string, op Operation) *openapi.Operation {
+	model := reflect.TypeOf(new(T)).Elem()
+	item := spec.SchemaOf(model)
+	items := &openapi.Schema{Type: "array", Items: item}
+	itemResponse := objectSchema(map[string]*openapi.Schema{"item": item}, "item")
+	etag := map[string]openapi.Header{"ETag": {
+		Description: "Version of versioned models, to send back in If-Match.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}}
+
+	idParam := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: idSchema[X]()}
+	ifMatch := headerParameter("If-Match", "Version the write expects, as returned in the ETag header.")
+	sortParam := queryParameter("sort", "Comma separated fields to order by, prefixed with - for descending order.", &openapi.Schema{Type: "string"})
+	listParams := append(spec.FilterParameters(model), sortParam)
+	invalidID := errorResponse(spec, models.ErrMustProvideValidID, models.ErrIDTypeMismatch)
+	notFound := errorResponse(spec, models.ErrNotFound)
+
+	operation := &openapi.Operation{
+		OperationID: string(op) + "_" + tag,
+		Tags:        []string{tag},
+		Responses: map[string]openapi.Response{
+			"500": errorResponse(spec),
+			"504": errorResponse(spec, models.ErrDeadlineExceeded),
+		},
+	}
+	responses := operation.Responses
+
+	switch op {
+	case OpList, OpListTrashed:
+		operation.Summary = "List " + tag
+		if op == OpListTrashed {
+			operation.Summary = "List soft-deleted " + tag
+		}
+		operation.Parameters = listParams
+		responses["200"] = openapi.Response{Description: "Matching entities.", Content: openapi.JSONContent(objectSchema(map[string]*openapi.Schema{"all": items}, "all"))}
+		responses["400"] = errorResponse(spec, models.ErrInvalidFilter, models.ErrInvalidSort, models.ErrUnknownField)
+		responses["404"] = notFound
+	case OpListPaged:
+		operation.Summary = "List a page of " + tag
+		operation.Parameters = append([]openapi.Parameter{
+			queryParameter("page", "Page number, starting at 1.", &openapi.Schema{Type: "integer", Minimum: new(float64)}),
+			queryParameter("page_size", "Number of entities per page.", &openapi.Schema{Type: "integer", Minimum: new(float64)}),
+		}, listParams...)
+		responses["200"] = openapi.Response{Description: "A page of matching entities.", Content: openapi.JSONContent(objectSchema(map[string]*openapi.Schema{
+			"items":      items,
+			"pagination": spec.SchemaOf(reflect.TypeOf(models.Pagination{})),
+		}, "items", "pagination"))}
+		responses["400"] = errorResponse(spec, models.ErrInvalidPagination, models.ErrInvalidFilter, models.ErrInvalidSort, models.ErrUnknownField)
+	case OpListCursor:
+		operation.Summary = "List " + tag + " with keyset pagination"
+		// Keyset pagination seeks on the primary key and at most one other
+		// column, so its sort takes a single field.
+		operation.Parameters = append([]openapi.Parameter{
+			queryParameter("cursor", "The next_cursor of the previous response.", &openapi.Schema{Type: "string"}),
+			queryParameter("limit", "Maximum number of entities to return.", &openapi.Schema{Type: "integer", Minimum: new(float64)}),
+			queryParameter("sort", "One field to order by before the primary key, prefixed with - for descending order.", &openapi.Schema{Type: "string"}),
+		}, spec.FilterParameters(model)...)
+		responses["200"] = openapi.Response{Description: "Matching entities.", Content: openapi.JSONContent(objectSchema(map[string]*openapi.Schema{
+			"items":       items,
+			"next_cursor": {Type: "string"},
+		}, "items"))}
+		responses["400"] = errorResponse(spec, models.ErrInvalidCursor, models.ErrInvalidPagination, models.ErrInvalidFilter, models.ErrInvalidSort)
+	case OpGet:
+		operation.Summary = "Get one of " + tag
+		operation.Parameters = []openapi.Parameter{idParam}
+		responses["200"] = openapi.Response{Description: "The entity.", Headers: etag, Content: openapi.JSONContent(itemResponse)}
+		responses["400"] = invalidID
+		responses["404"] = notFound
+	case OpCreate:
+		operation.Summary = "Create one of " + tag
+		operation.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSONContent(item)}
+		responses["201"] = openapi.Response{Description: "The created entity.", Headers: etag, Content: openapi.JSONContent(itemResponse)}
+		responses["400"] = errorResponse(spec, models.ErrModelCannotBeEmpty)
+		responses["409"] = errorResponse(spec, models.ErrDuplicateKey)
+		responses["422"] = errorResponse(spec, models.ErrValidation)
+	case OpCreateBulk:
+		operation.Summary = "Create many " + tag
+		operation.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSONContent(items)}
+		result := objectSchema(map[string]*openapi.Schema{
+			"results": {Type: "array", Items: objectSchema(map[string]*openapi.Schema{
+				"index": {Type: "integer"},
+				"item":  item,
+				"err":   {Type: "string"},
+				"code":  {Type: "string"},
+			}, "index")},
+			"succeeded": {Type: "integer"},
+			"failed":    {Type: "integer"},
+		}, "results", "succeeded", "failed")
+		responses["201"] = openapi.Response{Description: "Every entity was created.", Content: openapi.JSONContent(result)}
+		responses["207"] = openapi.Response{Description: "Some entities failed, see err in results.", Content: openapi.JSONContent(result)}
+		responses["400"] = errorResponse(spec, models.ErrModelCannotBeEmpty)
+	case OpUpdate:
+		operation.Summary = "Update one of " + tag
+		operation.Parameters = []openapi.Parameter{idParam, ifMatch}
+		operation.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSONContent(item)}
+		responses["200"] = openapi.Response{Description: "The updated entity.", Headers: etag, Content: openapi.JSONContent(itemResponse)}
+		responses["400"] = errorResponse(spec, models.ErrMustProvideValidID, models.ErrIDTypeMismatch, models.ErrInvalidVersion)
+		responses["404"] = notFound
+		responses["409"] = errorResponse(spec, models.ErrConflict, models.ErrDuplicateKey)
+		responses["422"] = errorResponse(spec, models.ErrValidation)
+	case OpPatch:
+		operation.Summary = "Patch one of " + tag
+		operation.Parameters = []openapi.Parameter{idParam, ifMatch}
+		patchOperation := objectSchema(map[string]*openapi.Schema{
+			"op":    {Type: "string", Enum: []interface{}{"add", "remove", "replace", "move", "copy", "test"}},
+			"path":  {Type: "string"},
+			"from":  {Type: "string"},
+			"value": {},
+		}, "op", "path")
+		operation.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			models.MergePatchType: {Schema: &openapi.Schema{Type: "object"}},
+			models.JSONPatchType:  {Schema: &openapi.Schema{Type: "array", Items: patchOperation}},
+			"application/json":    {Schema: &openapi.Schema{Type: "object"}},
+		}}
+		responses["200"] = openapi.Response{Description: "The patched entity.", Headers: etag, Content: openapi.JSONContent(itemResponse)}
+		responses["400"] = errorResponse(spec, models.ErrMustProvideValidID, models.ErrInvalidPatch, models.ErrUnknownField, models.ErrInvalidVersion)
+		responses["404"] = notFound
+		responses["409"] = errorResponse(spec, models.ErrConflict, models.ErrPatchTestFailed, models.ErrDuplicateKey)
+		responses["415"] = errorResponse(spec, models.ErrUnsupportedPatchType)
+		responses["422"] = errorResponse(spec, models.ErrValidation)
+	case OpDelete:
+		operation.Summary = "Delete one of " + tag
+		operation.Parameters = []openapi.Parameter{
+			idParam,
+			ifMatch,
+			queryParameter("permanent", "Remove the row instead of soft deleting it.", &openapi.Schema{Type: "boolean"}),
+		}
+		responses["200"] = openapi.Response{Description: "The entity was deleted.", Content: openapi.JSONContent(objectSchema(map[string]*openapi.Schema{"message": {Type: "string"}}))}
+		responses["400"] = errorResponse(spec, models.ErrMustProvideValidID, models.ErrInvalidPermanentFlag, models.ErrInvalidVersion)
+		responses["404"] = notFound
+		responses["409"] = errorResponse(spec, models.ErrConflict)
+	case OpRestore:
+		operation.Summary = "Restore one of " + tag
+		operation.Parameters = []openapi.Parameter{idParam}
+		responses["200"] = openapi.Response{Description: "The entity was restored.", Content: openapi.JSONContent(objectSchema(map[string]*openapi.Schema{"message": {Type: "string"}}))}
+		responses["400"] = errorResponse(spec, models.ErrMustProvideValidID, models.ErrSoftDeleteNotSupported)
+		responses["404"] = notFound
+	case OpHistory:
+		operation.Summary = "List the changes of one of " + tag
+		operation.Parameters = []openapi.Parameter{idParam}
+		change := objectSchema(map[string]*openapi.Schema{
+			"field":  {Type: "string"},
+			"before": {Description: "Value before the change, absent for creations."},
+			"after":  {Description: "Value after the change, absent for deletions."},
+		}, "field")
+		entry := spec.AddSchema("AuditEntry", objectSchema(map[string]*openapi.Schema{
+			"id":          {Type: "integer"},
+			"entity_type": {Type: "string"},
+			"entity_id":   {Type: "string"},
+			"operation":   {Type: "string", Enum: []interface{}{audit.OpCreate, audit.OpUpdate, audit.OpUpdateField, audit.OpDelete}},
+			"actor":       {Type: "string"},
+			"changes":     {Type: "array", Items: change},
+			"created_at":  {Type: "string", Format: "date-time"},
+		}, "id", "entity_type", "entity_id", "operation", "changes", "created_at"))
+		history := objectSchema(map[string]*openapi.Schema{"items": {Type: "array", Items: entry}}, "items")
+		responses["200"] = openapi.Response{Description: "The audit entries of the entity, oldest first.", Content: openapi.JSONContent(history)}
+		responses["400"] = invalidID
+		responses["501"] = errorResponse(spec, models.ErrAuditNotEnabled)
+	case OpGetAsOf:
+		operation.Summary = "Get one of " + tag + " as it was at a point in time"
+		operation.Parameters = []openapi.Parameter{
+			idParam,
+			{Name: "at", In: "query", Required: true, Description: "RFC 3339 time to read the entity at.", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+		}
+		responses["200"] = openapi.Response{Description: "The entity as it was at that time.", Content: openapi.JSONContent(itemResponse)}
+		responses["400"] = errorResponse(spec, models.ErrMustProvideValidID, models.ErrInvalidTimestamp)
+		responses["404"] = notFound
+		responses["501"] = errorResponse(spec, models.ErrVersioningNotEnabled)
+	case OpListVersions:
+		operation.Summary = "List the previous versions of one of " + tag
+		operation.Parameters = []openapi.Parameter{idParam}
+		version := objectSchema(map[string]*openapi.Schema{
+			"version":       {Type: "integer", Minimum: new(float64)},
+			"operation":     {Type: "string", Enum: []interface{}{history.OpUpdate, history.OpUpdateField, history.OpDelete}},
+			"superseded_at": {Type: "string", Format: "date-time"},
+			"entity":        item,
+		}, "version", "operation", "superseded_at", "entity")
+		versions := objectSchema(map[string]*openapi.Schema{"items": {Type: "array", Items: version}}, "items")
+		responses["200"] = openapi.Response{Description: "The previous versions of the entity, oldest first.", Content: openapi.JSONContent(versions)}
+		responses["400"] = invalidID
+		responses["501"] = errorResponse(spec, models.ErrVersioningNotEnabled)
+	case OpRevert:
+		operation.Summary = "Revert one of " + tag + " to a previous version"
+		operation.Parameters = []openapi.Parameter{
+			idParam,
+			{Name: "version", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer", Minimum: new(float64)}},
+			ifMatch,
+		}
+		responses["200"] = openapi.Response{Description: "The reverted entity.", Headers: etag, Content: openapi.JSONContent(itemResponse)}
+		responses["400"] = errorResponse(spec, models.ErrMustProvideValidID, models.ErrInvalidVersion)
+		responses["404"] = errorResponse(spec, models.ErrVersionNotFound)
+		responses["409"] = errorResponse(spec, models.ErrConflict, models.ErrDuplicateKey)
+		responses["422"] = errorResponse(spec, models.ErrValidation)
+		responses["501"] = errorResponse(spec, models.ErrVersioningNotEnabled)
+	case OpPurge:
+		operation.Summary = "Purge soft-deleted " + tag
+		operation.Parameters = []openapi.Parameter{
+			queryParameter("older_than", "Only purge entities deleted longer ago than this Go duration, e.g. 720h.", &openapi.Schema{Type: "string"}),
+		}
+		responses["200"] = openapi.Response{Description: "Number of purged entities.", Content: openapi.JSONContent(objectSchema(map[string]*openapi.Schema{"purged": {Type: "integer"}}, "purged"))}
+		responses["400"] = errorResponse(spec, models.ErrInvalidDuration, models.ErrSoftDeleteNotSupported)
+	}
+
+	return operation
+}
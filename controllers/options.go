@@ -0,0 +1,66 @@
+package controllers
+
+const (
+	DefaultPageSize    = 20
+	DefaultMaxPageSize = 100
+	DefaultBatchSize   = 100
+)
+
+type Config struct {
+	DefaultPageSize int
+	MaxPageSize     int
+	BatchSize       int
+}
+
+type Option func(*Config)
+
+func WithDefaultPageSize(size int) Option {
+	return func(cfg *Config) {
+		cfg.DefaultPageSize = size
+	}
+}
+
+func WithMaxPageSize(size int) Option {
+	return func(cfg *Config) {
+		cfg.MaxPageSize = size
+	}
+}
+
+func WithBatchSize(size int) Option {
+	return func(cfg *Config) {
+		cfg.BatchSize = size
+	}
+}
+
+func newConfig(opts ...Option) Config {
+	cfg := Config{
+		DefaultPageSize: DefaultPageSize,
+		MaxPageSize:     DefaultMaxPageSize,
+		BatchSize:       DefaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+func (cfg Config) pageSizes() (int, int) {
+	def, max := cfg.DefaultPageSize, cfg.MaxPageSize
+	if max < 1 {
+		max = DefaultMaxPageSize
+	}
+	if def < 1 {
+		def = DefaultPageSize
+	}
+	if def > max {
+		def = max
+	}
+	return def, max
+}
+
+func (cfg Config) batchSize() int {
+	if cfg.BatchSize < 1 {
+		return DefaultBatchSize
+	}
+	return cfg.BatchSize
+}
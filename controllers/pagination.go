@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/gin-gonic/gin"
+)
+
+func parsePageParams(c *gin.Context, cfg Config) (int, int, error) {
+	defaultSize, maxSize := cfg.pageSizes()
+
+	page := 1
+	if raw := c.Query("page"); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil || p < 1 {
+			return 0, 0, models.ErrInvalidPagination
+		}
+		page = p
+	}
+
+	pageSize := defaultSize
+	if raw := c.Query("page_size"); raw != "" {
+		s, err := strconv.Atoi(raw)
+		if err != nil || s < 1 {
+			return 0, 0, models.ErrInvalidPagination
+		}
+		pageSize = s
+	}
+	if pageSize > maxSize {
+		pageSize = maxSize
+	}
+
+	return page, pageSize, nil
+}
+
+func parseLimitParam(c *gin.Context, cfg Config) (int, error) {
+	defaultSize, maxSize := cfg.pageSizes()
+
+	limit := defaultSize
+	if raw := c.Query("limit"); raw != "" {
+		l, err := strconv.Atoi(raw)
+		if err != nil || l < 1 {
+			return 0, models.ErrInvalidPagination
+		}
+		limit = l
+	}
+	if limit > maxSize {
+		limit = maxSize
+	}
+
+	return limit, nil
+}
+
+func buildPagination(c *gin.Context, page int, pageSize int, total int64) models.Pagination {
+	pages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	p := models.Pagination{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Pages:    pages,
+	}
+	if page < pages {
+		p.Next = pageLink(c, page+1, pageSize)
+	}
+	if page > 1 && pages > 0 {
+		prev := page - 1
+		if prev > pages {
+			prev = pages
+		}
+		p.Prev = pageLink(c, prev, pageSize)
+	}
+	return p
+}
+
+func pageLink(c *gin.Context, page int, pageSize int) string {
+	u := url.URL{}
+	if c.Request != nil && c.Request.URL != nil {
+		u = *c.Request.URL
+	}
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("page_size", strconv.Itoa(pageSize))
+	return (&url.URL{Path: u.Path, RawQuery: q.Encode()}).String()
+}
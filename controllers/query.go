@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/gin-gonic/gin"
+)
+
+var reservedQueryParams = map[string]bool{
+	"page":      true,
+	"page_size": true,
+	"cursor":    true,
+	"limit":     true,
+	"sort":      true,
+}
+
+var (
+	filterKeyPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(?:\[([a-z]+)\])?$`)
+	sortFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// parseQueryOptions turns query parameters such as email=foo@x.com or
+// age[gte]=18 into repository filters. Field names and operators are
+// validated against the model by the repository.
+func parseQueryOptions(c *gin.Context) ([]models.QueryOption, error) {
+	var opts []models.QueryOption
+	if c.Request == nil || c.Request.URL == nil {
+		return opts, nil
+	}
+
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if !reservedQueryParams[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		match := filterKeyPattern.FindStringSubmatch(key)
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", models.ErrInvalidFilter, key)
+		}
+		operator := match[2]
+		if operator == "" {
+			operator = models.FilterEq
+		}
+		for _, value := range query[key] {
+			opts = append(opts, models.Filter{Field: match[1], Operator: operator, Value: value})
+		}
+	}
+
+	return opts, nil
+}
+
+// parseSortOptions turns sort=-created_at,email into repository sort
+// options, descending when the field is prefixed with a minus sign.
+func parseSortOptions(c *gin.Context) ([]models.QueryOption, error) {
+	var opts []models.QueryOption
+	raw := c.Query("sort")
+	if raw == "" {
+		return opts, nil
+	}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		field := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+		if !sortFieldPattern.MatchString(field) {
+			return nil, fmt.Errorf("%w: %q", models.ErrInvalidSort, part)
+		}
+		opts = append(opts, models.Sort{Field: field, Desc: desc})
+	}
+
+	return opts, nil
+}
+
+func parseListOptions(c *gin.Context) ([]models.QueryOption, error) {
+	filters, err := parseQueryOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	sorts, err := parseSortOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	return append(filters, sorts...), nil
+}
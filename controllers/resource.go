@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/middleware"
+	"github.com/alvarotor/entitier-go/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// Operation identifies one of the routes mounted by RegisterResource.
+type Operation string
+
+const (
+	OpList         Operation = "list"
+	OpListPaged    Operation = "list_paged"
+	OpListCursor   Operation = "list_cursor"
+	OpListTrashed  Operation = "list_trashed"
+	OpGet          Operation = "get"
+	OpCreate       Operation = "create"
+	OpCreateBulk   Operation = "create_bulk"
+	OpUpdate       Operation = "update"
+	OpPatch        Operation = "patch"
+	OpDelete       Operation = "delete"
+	OpRestore      Operation = "restore"
+	OpPurge        Operation = "purge"
+	OpHistory      Operation = "history"
+	OpGetAsOf      Operation = "get_as_of"
+	OpListVersions Operation = "list_versions"
+	OpRevert       Operation = "revert"
+)
+
+// DefaultOperations are mounted unless disabled with WithoutOperations.
+var DefaultOperations = []Operation{OpList, OpGet, OpCreate, OpUpdate, OpPatch, OpDelete}
+
+type resourceRoute[T any, X string | uint] struct {
+	op      Operation
+	method  string
+	path    string
+	withID  bool
+	handler func(IControllerGeneric[T, X]) gin.HandlerFunc
+}
+
+// resourceRoutes lists every operation in mount order.
+func resourceRoutes[T any, X string | uint]() []resourceRoute[T, X] {
+	return []resourceRoute[T, X]{
+		{OpList, http.MethodGet, "", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.GetAll }},
+		{OpListPaged, http.MethodGet, "/paged", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.GetAllPaged }},
+		{OpListCursor, http.MethodGet, "/cursor", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.GetAllCursor }},
+		{OpListTrashed, http.MethodGet, "/trash", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.GetAllTrashed }},
+		{OpPurge, http.MethodDelete, "/trash", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.Purge }},
+		{OpCreate, http.MethodPost, "", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.CreateHandler }},
+		{OpCreateBulk, http.MethodPost, "/bulk", false, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.CreateBulk }},
+		{OpGet, http.MethodGet, "/:id", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.Get }},
+		{OpUpdate, http.MethodPut, "/:id", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.UpdateHandler }},
+		{OpPatch, http.MethodPatch, "/:id", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.Patch }},
+		{OpDelete, http.MethodDelete, "/:id", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.Delete }},
+		{OpRestore, http.MethodPost, "/:id/restore", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.Restore }},
+		{OpHistory, http.MethodGet, "/:id/history", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.History }},
+		{OpGetAsOf, http.MethodGet, "/:id/as-of", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.GetAsOf }},
+		{OpListVersions, http.MethodGet, "/:id/versions", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.ListVersions }},
+		{OpRevert, http.MethodPost, "/:id/versions/:version/revert", true, func(c IControllerGeneric[T, X]) gin.HandlerFunc { return c.Revert }},
+	}
+}
+
+type resourceConfig struct {
+	enabled    map[Operation]bool
+	overrides  map[Operation]gin.HandlerFunc
+	decorators map[Operation][]gin.HandlerFunc
+	middleware []gin.HandlerFunc
+	spec       *openapi.Spec
+}
+
+type ResourceOption func(*resourceConfig)
+
+// WithOperations mounts operations that are not part of DefaultOperations,
+// such as OpListPaged, OpRestore, OpHistory or the versioning operations.
+func WithOperations(ops ...Operation) ResourceOption {
+	return func(cfg *resourceConfig) {
+		for _, op := range ops {
+			cfg.enabled[op] = true
+		}
+	}
+}
+
+func WithoutOperations(ops ...Operation) ResourceOption {
+	return func(cfg *resourceConfig) {
+		for _, op := range ops {
+			cfg.enabled[op] = false
+		}
+	}
+}
+
+// OverrideOperation replaces the controller handler of op, mounting it if
+// it was not enabled. Use it, for example, to serve PUT with ctrl.Upsert.
+func OverrideOperation(op Operation, handler gin.HandlerFunc) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.enabled[op] = true
+		cfg.overrides[op] = handler
+	}
+}
+
+// DecorateOperation runs middleware before the handler of op, after the ID
+// has been validated.
+func DecorateOperation(op Operation, middleware ...gin.HandlerFunc) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.decorators[op] = append(cfg.decorators[op], middleware...)
+	}
+}
+
+// WithResourceMiddleware runs middleware before every operation.
+func WithResourceMiddleware(middleware ...gin.HandlerFunc) ResourceOption {
+	return func(cfg *resourceConfig) {
+		cfg.middleware = append(cfg.middleware, middleware...)
+	}
+}
+
+// RegisterResource mounts the CRUD routes of ctrl under path and returns the
+// resource group so that custom routes can be added next to them.
+func RegisterResource[T any, X string | uint](group *gin.RouterGroup, path string, ctrl IControllerGeneric[T, X], opts ...ResourceOption) *gin.RouterGroup {
+	cfg := resourceConfig{
+		enabled:    map[Operation]bool{},
+		overrides:  map[Operation]gin.HandlerFunc{},
+		decorators: map[Operation][]gin.HandlerFunc{},
+	}
+	for _, op := range DefaultOperations {
+		cfg.enabled[op] = true
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	resource := group.Group(path, cfg.middleware...)
+	for _, route := range resourceRoutes[T, X]() {
+		if !cfg.enabled[route.op] {
+			continue
+		}
+
+		var handlers []gin.HandlerFunc
+		if route.withID {
+			handlers = append(handlers, middleware.IDValidator[X]())
+		}
+		handlers = append(handlers, cfg.decorators[route.op]...)
+		if handler, ok := cfg.overrides[route.op]; ok {
+			handlers = append(handlers, handler)
+		} else {
+			handlers = append(handlers, route.handler(ctrl))
+		}
+
+		resource.Handle(route.method, route.path, handlers...)
+		if cfg.spec != nil {
+			tag := strings.Trim(path, "/")
+			cfg.spec.AddOperation(resource.BasePath()+route.path, route.method, describeOperation[T, X](cfg.spec, tag, route.op))
+		}
+	}
+
+	return resource
+}
@@ -0,0 +1,92 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var ctx = context.Background()
+
+func setupDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("failed to migrate snapshot table: %v", err)
+	}
+	return db
+}
+
+func TestWriteAndList(t *testing.T) {
+	db := setupDB(t)
+
+	first, err := Write(ctx, db, "User", "1", OpUpdate, []byte(`{"id":1,"email":"a"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), first.Version)
+	second, err := Write(ctx, db, "User", "1", OpDelete, []byte(`{"id":1,"email":"b"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), second.Version)
+	other, err := Write(ctx, db, "User", "2", OpUpdate, []byte(`{"id":2}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), other.Version)
+
+	snapshots, err := List(ctx, db, "User", "1")
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 2) {
+		assert.Equal(t, OpUpdate, snapshots[0].Operation)
+		assert.Equal(t, OpDelete, snapshots[1].Operation)
+	}
+
+	found, err := Find(ctx, db, "User", "1", 2)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"email":"b"}`, string(found.Data))
+	_, err = Find(ctx, db, "User", "1", 3)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	err = db.Create(&Snapshot{EntityType: "User", EntityID: "1", Version: 2, Operation: OpUpdate, Data: []byte(`{}`), SupersededAt: time.Now()}).Error
+	assert.Error(t, err)
+}
+
+func TestAsOf(t *testing.T) {
+	db := setupDB(t)
+
+	before := time.Now()
+	_, err := Write(ctx, db, "User", "1", OpUpdate, []byte(`{"email":"a"}`))
+	assert.NoError(t, err)
+	between := time.Now()
+	_, err = Write(ctx, db, "User", "1", OpUpdate, []byte(`{"email":"b"}`))
+	assert.NoError(t, err)
+
+	snapshot, err := AsOf(ctx, db, "User", "1", before)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), snapshot.Version)
+
+	snapshot, err = AsOf(ctx, db, "User", "1", between)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), snapshot.Version)
+
+	snapshot, err = AsOf(ctx, db, "User", "1", time.Now())
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+func TestDecode(t *testing.T) {
+	type user struct {
+		Email    string `json:"email"`
+		Password string `json:"-"`
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	v, err := Decode[user](Snapshot{Version: 3, Operation: OpUpdateField, SupersededAt: at, Data: []byte(`{"email":"a","Password":"x"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, Version[user]{Version: 3, Operation: OpUpdateField, SupersededAt: at, Entity: user{Email: "a"}}, v)
+
+	_, err = Decode[user](Snapshot{Data: []byte(`not json`)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Operations that supersede a version. They have the same values as the
+// operations of outbox events and audit entries.
+const (
+	OpUpdate      = "update"
+	OpUpdateField = "update_field"
+	OpDelete      = "delete"
+	OpRestore     = "restore"
+)
+
+// Snapshot is one version of an entity: its JSON document as it was until
+// the change recorded in Operation replaced it at SupersededAt. Versions
+// are numbered per entity from 1, in the order they were superseded.
+type Snapshot struct {
+	ID           uint      `gorm:"primaryKey"`
+	EntityType   string    `gorm:"size:255;uniqueIndex:idx_snapshot_version;not null"`
+	EntityID     string    `gorm:"size:255;uniqueIndex:idx_snapshot_version;not null"`
+	Version      uint64    `gorm:"uniqueIndex:idx_snapshot_version;not null"`
+	Operation    string    `gorm:"size:32;not null"`
+	Data         []byte    `gorm:"not null"`
+	SupersededAt time.Time `gorm:"index;not null"`
+}
+
+func (Snapshot) TableName() string {
+	return "entity_snapshots"
+}
+
+// Migrate creates or updates the snapshot table.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Snapshot{})
+}
+
+// Write stores data as the next version of the entity through db, which
+// should be the transaction of the change that supersedes it. Concurrent
+// writers of the same entity fail on the unique version index.
+func Write(ctx context.Context, db *gorm.DB, entityType string, entityID string, operation string, data []byte) (*Snapshot, error) {
+	db = db.WithContext(ctx)
+
+	var latest uint64
+	err := db.Model(&Snapshot{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Version:      latest + 1,
+		Operation:    operation,
+		Data:         data,
+		SupersededAt: time.Now().UTC(),
+	}
+	if err := db.Create(snapshot).Error; err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List returns the versions of an entity, oldest first.
+func List(ctx context.Context, db *gorm.DB, entityType string, entityID string) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+	err := db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version").
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+// Find returns one version of an entity, or gorm.ErrRecordNotFound.
+func Find(ctx context.Context, db *gorm.DB, entityType string, entityID string, version uint64) (*Snapshot, error) {
+	var snapshot Snapshot
+	err := db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		Take(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// AsOf returns the version of an entity that was current at the given
+// time: the first one superseded after it. It returns nil, and no error,
+// when no version was superseded since, so the current state applies.
+func AsOf(ctx context.Context, db *gorm.DB, entityType string, entityID string, at time.Time) (*Snapshot, error) {
+	var snapshot Snapshot
+	err := db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ? AND superseded_at > ?", entityType, entityID, at.UTC()).
+		Order("version").
+		Take(&snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Version is a snapshot decoded into its model. Members the model leaves
+// out of its JSON document, such as `json:"-"` fields, are zero.
+type Version[T any] struct {
+	Version      uint64    `json:"version"`
+	Operation    string    `json:"operation"`
+	SupersededAt time.Time `json:"superseded_at"`
+	Entity       T         `json:"entity"`
+}
+
+// Decode returns the entity version stored in s.
+func Decode[T any](s Snapshot) (Version[T], error) {
+	v := Version[T]{Version: s.Version, Operation: s.Operation, SupersededAt: s.SupersededAt}
+	err := json.Unmarshal(s.Data, &v.Entity)
+	return v, err
+}
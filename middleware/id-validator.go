@@ -1,10 +1,10 @@
 package middleware
 
 import (
-	"net/http"
 	"strconv"
 
 	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/problem"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,16 +12,14 @@ func IDValidator[X string | uint]() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		if idStr == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"err": models.ErrMustProvideValidID.Error()})
-			c.Abort()
+			problem.Abort(c, models.ErrMustProvideValidID)
 			return
 		}
 
 		idInterface := getIDParam(c)
 		id, err := convertToGenericID[X](idInterface)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"err": err.Error()})
-			c.Abort()
+			problem.Abort(c, err)
 			return
 		}
 
@@ -43,7 +43,7 @@ func TestIDValidator(t *testing.T) {
 			paramType:      "string",
 			path:           "/",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   gin.H{"err": models.ErrMustProvideValidID.Error()},
+			expectedBody:   gin.H{"type": "about:blank", "title": "Bad Request", "status": float64(http.StatusBadRequest), "detail": models.ErrMustProvideValidID.Error(), "instance": "/", "code": models.CodeInvalidID},
 			expectedID:     nil,
 		},
 		{
@@ -51,7 +51,7 @@ func TestIDValidator(t *testing.T) {
 			paramType:      "uint",
 			path:           "/abc",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   gin.H{"err": models.ErrIDTypeMismatch.Error()},
+			expectedBody:   gin.H{"type": "about:blank", "title": "Bad Request", "status": float64(http.StatusBadRequest), "detail": models.ErrIDTypeMismatch.Error(), "instance": "/abc", "code": models.CodeInvalidID},
 			expectedID:     nil,
 		},
 	}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/problem"
+	"github.com/alvarotor/entitier-go/tenancy"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTenantHeader is read by Tenant when it is given no source.
+const DefaultTenantHeader = "X-Tenant-ID"
+
+// TenantSource extracts the tenant of a request, reporting false when the
+// request does not carry one.
+type TenantSource func(c *gin.Context) (string, bool)
+
+// TenantHeader reads the tenant from a request header.
+func TenantHeader(name string) TenantSource {
+	return func(c *gin.Context) (string, bool) {
+		tenant := strings.TrimSpace(c.GetHeader(name))
+		return tenant, tenant != ""
+	}
+}
+
+// TenantClaim reads the tenant from claim in the claims that an
+// authentication middleware stored under key with c.Set, as any map with
+// string keys such as jwt.MapClaims. String and numeric claims are
+// accepted.
+func TenantClaim(key string, claim string) TenantSource {
+	return func(c *gin.Context) (string, bool) {
+		claims, ok := c.Get(key)
+		if !ok {
+			return "", false
+		}
+		m := reflect.ValueOf(claims)
+		if m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String {
+			return "", false
+		}
+		v := m.MapIndex(reflect.ValueOf(claim).Convert(m.Type().Key()))
+		if !v.IsValid() {
+			return "", false
+		}
+		var tenant string
+		switch value := v.Interface().(type) {
+		case string:
+			tenant = value
+		case fmt.Stringer:
+			tenant = value.String()
+		case float64, float32, int, int32, int64, uint, uint32, uint64:
+			tenant = fmt.Sprint(value)
+		}
+		return tenant, tenant != ""
+	}
+}
+
+// Tenant puts the tenant of the request on its context, for repositories
+// built with repository.WithTenancy, taking it from the first of sources
+// that has one, or from the X-Tenant-ID header when none are given.
+// Requests without a tenant are rejected with models.ErrTenantRequired.
+func Tenant(sources ...TenantSource) gin.HandlerFunc {
+	if len(sources) == 0 {
+		sources = []TenantSource{TenantHeader(DefaultTenantHeader)}
+	}
+	return func(c *gin.Context) {
+		for _, source := range sources {
+			if tenant, ok := source(c); ok {
+				c.Request = c.Request.WithContext(tenancy.WithTenant(c.Request.Context(), tenant))
+				c.Next()
+				return
+			}
+		}
+		problem.Abort(c, models.ErrTenantRequired)
+	}
+}
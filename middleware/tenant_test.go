@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/tenancy"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapClaims map[string]interface{}
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		sources        []TenantSource
+		header         string
+		claims         interface{}
+		expectedStatus int
+		expectedTenant string
+	}{
+		{"Default header", nil, "acme", nil, http.StatusOK, "acme"},
+		{"Missing", nil, "", nil, http.StatusUnauthorized, ""},
+		{"Claim", []TenantSource{TenantClaim("claims", "tenant")}, "", mapClaims{"tenant": "globex"}, http.StatusOK, "globex"},
+		{"Numeric claim", []TenantSource{TenantClaim("claims", "tenant")}, "", mapClaims{"tenant": float64(42)}, http.StatusOK, "42"},
+		{"Claim wins over header", []TenantSource{TenantClaim("claims", "tenant"), TenantHeader("X-Tenant-ID")}, "acme", mapClaims{"tenant": "globex"}, http.StatusOK, "globex"},
+		{"Falls back to header", []TenantSource{TenantClaim("claims", "tenant"), TenantHeader("X-Tenant-ID")}, "acme", mapClaims{"sub": "alice"}, http.StatusOK, "acme"},
+		{"Claims of another type", []TenantSource{TenantClaim("claims", "tenant")}, "", "acme", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set("claims", tt.claims)
+				}
+			})
+			router.GET("/", Tenant(tt.sources...), func(c *gin.Context) {
+				tenant, ok := tenancy.TenantFrom(c.Request.Context())
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectedTenant, tenant)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(DefaultTenantHeader, tt.header)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				var response gin.H
+				if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response)) {
+					assert.Equal(t, models.CodeTenantRequired, response["code"])
+				}
+			}
+		})
+	}
+}
@@ -82,6 +82,72 @@ func (_c *IControllerGeneric_Create_Call[T, X]) RunAndReturn(run func(context.Co
 	return _c
 }
 
+// CreateBulk provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) CreateBulk(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_CreateBulk_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateBulk'
+type IControllerGeneric_CreateBulk_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// CreateBulk is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) CreateBulk(_a0 interface{}) *IControllerGeneric_CreateBulk_Call[T, X] {
+	return &IControllerGeneric_CreateBulk_Call[T, X]{Call: _e.mock.On("CreateBulk", _a0)}
+}
+
+func (_c *IControllerGeneric_CreateBulk_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_CreateBulk_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_CreateBulk_Call[T, X]) Return() *IControllerGeneric_CreateBulk_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_CreateBulk_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_CreateBulk_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// CreateHandler provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) CreateHandler(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_CreateHandler_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateHandler'
+type IControllerGeneric_CreateHandler_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// CreateHandler is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) CreateHandler(_a0 interface{}) *IControllerGeneric_CreateHandler_Call[T, X] {
+	return &IControllerGeneric_CreateHandler_Call[T, X]{Call: _e.mock.On("CreateHandler", _a0)}
+}
+
+func (_c *IControllerGeneric_CreateHandler_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_CreateHandler_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_CreateHandler_Call[T, X]) Return() *IControllerGeneric_CreateHandler_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_CreateHandler_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_CreateHandler_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
 // Delete provides a mock function with given fields: _a0
 func (_m *IControllerGeneric[T, X]) Delete(_a0 *gin.Context) {
 	_m.Called(_a0)
@@ -181,6 +247,336 @@ func (_c *IControllerGeneric_GetAll_Call[T, X]) RunAndReturn(run func(*gin.Conte
 	return _c
 }
 
+// GetAllCursor provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) GetAllCursor(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_GetAllCursor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllCursor'
+type IControllerGeneric_GetAllCursor_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAllCursor is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) GetAllCursor(_a0 interface{}) *IControllerGeneric_GetAllCursor_Call[T, X] {
+	return &IControllerGeneric_GetAllCursor_Call[T, X]{Call: _e.mock.On("GetAllCursor", _a0)}
+}
+
+func (_c *IControllerGeneric_GetAllCursor_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_GetAllCursor_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAllCursor_Call[T, X]) Return() *IControllerGeneric_GetAllCursor_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAllCursor_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_GetAllCursor_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// GetAllPaged provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) GetAllPaged(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_GetAllPaged_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllPaged'
+type IControllerGeneric_GetAllPaged_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAllPaged is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) GetAllPaged(_a0 interface{}) *IControllerGeneric_GetAllPaged_Call[T, X] {
+	return &IControllerGeneric_GetAllPaged_Call[T, X]{Call: _e.mock.On("GetAllPaged", _a0)}
+}
+
+func (_c *IControllerGeneric_GetAllPaged_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_GetAllPaged_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAllPaged_Call[T, X]) Return() *IControllerGeneric_GetAllPaged_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAllPaged_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_GetAllPaged_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// GetAllTrashed provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) GetAllTrashed(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_GetAllTrashed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTrashed'
+type IControllerGeneric_GetAllTrashed_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAllTrashed is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) GetAllTrashed(_a0 interface{}) *IControllerGeneric_GetAllTrashed_Call[T, X] {
+	return &IControllerGeneric_GetAllTrashed_Call[T, X]{Call: _e.mock.On("GetAllTrashed", _a0)}
+}
+
+func (_c *IControllerGeneric_GetAllTrashed_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_GetAllTrashed_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAllTrashed_Call[T, X]) Return() *IControllerGeneric_GetAllTrashed_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAllTrashed_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_GetAllTrashed_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// GetAsOf provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) GetAsOf(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_GetAsOf_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAsOf'
+type IControllerGeneric_GetAsOf_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAsOf is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) GetAsOf(_a0 interface{}) *IControllerGeneric_GetAsOf_Call[T, X] {
+	return &IControllerGeneric_GetAsOf_Call[T, X]{Call: _e.mock.On("GetAsOf", _a0)}
+}
+
+func (_c *IControllerGeneric_GetAsOf_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_GetAsOf_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAsOf_Call[T, X]) Return() *IControllerGeneric_GetAsOf_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_GetAsOf_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_GetAsOf_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// History provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) History(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type IControllerGeneric_History_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) History(_a0 interface{}) *IControllerGeneric_History_Call[T, X] {
+	return &IControllerGeneric_History_Call[T, X]{Call: _e.mock.On("History", _a0)}
+}
+
+func (_c *IControllerGeneric_History_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_History_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_History_Call[T, X]) Return() *IControllerGeneric_History_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_History_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_History_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// ListVersions provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) ListVersions(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_ListVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVersions'
+type IControllerGeneric_ListVersions_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// ListVersions is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) ListVersions(_a0 interface{}) *IControllerGeneric_ListVersions_Call[T, X] {
+	return &IControllerGeneric_ListVersions_Call[T, X]{Call: _e.mock.On("ListVersions", _a0)}
+}
+
+func (_c *IControllerGeneric_ListVersions_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_ListVersions_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_ListVersions_Call[T, X]) Return() *IControllerGeneric_ListVersions_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_ListVersions_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_ListVersions_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// Patch provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) Patch(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_Patch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Patch'
+type IControllerGeneric_Patch_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Patch is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) Patch(_a0 interface{}) *IControllerGeneric_Patch_Call[T, X] {
+	return &IControllerGeneric_Patch_Call[T, X]{Call: _e.mock.On("Patch", _a0)}
+}
+
+func (_c *IControllerGeneric_Patch_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_Patch_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_Patch_Call[T, X]) Return() *IControllerGeneric_Patch_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_Patch_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_Patch_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// Purge provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) Purge(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type IControllerGeneric_Purge_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) Purge(_a0 interface{}) *IControllerGeneric_Purge_Call[T, X] {
+	return &IControllerGeneric_Purge_Call[T, X]{Call: _e.mock.On("Purge", _a0)}
+}
+
+func (_c *IControllerGeneric_Purge_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_Purge_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_Purge_Call[T, X]) Return() *IControllerGeneric_Purge_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_Purge_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_Purge_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) Restore(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type IControllerGeneric_Restore_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) Restore(_a0 interface{}) *IControllerGeneric_Restore_Call[T, X] {
+	return &IControllerGeneric_Restore_Call[T, X]{Call: _e.mock.On("Restore", _a0)}
+}
+
+func (_c *IControllerGeneric_Restore_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_Restore_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_Restore_Call[T, X]) Return() *IControllerGeneric_Restore_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_Restore_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_Restore_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// Revert provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) Revert(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_Revert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revert'
+type IControllerGeneric_Revert_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Revert is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) Revert(_a0 interface{}) *IControllerGeneric_Revert_Call[T, X] {
+	return &IControllerGeneric_Revert_Call[T, X]{Call: _e.mock.On("Revert", _a0)}
+}
+
+func (_c *IControllerGeneric_Revert_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_Revert_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_Revert_Call[T, X]) Return() *IControllerGeneric_Revert_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_Revert_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_Revert_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
 // Update provides a mock function with given fields: _a0, _a1, _a2
 func (_m *IControllerGeneric[T, X]) Update(_a0 context.Context, _a1 X, _a2 T) (int, error) {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -239,6 +635,72 @@ func (_c *IControllerGeneric_Update_Call[T, X]) RunAndReturn(run func(context.Co
 	return _c
 }
 
+// UpdateHandler provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) UpdateHandler(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_UpdateHandler_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateHandler'
+type IControllerGeneric_UpdateHandler_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// UpdateHandler is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) UpdateHandler(_a0 interface{}) *IControllerGeneric_UpdateHandler_Call[T, X] {
+	return &IControllerGeneric_UpdateHandler_Call[T, X]{Call: _e.mock.On("UpdateHandler", _a0)}
+}
+
+func (_c *IControllerGeneric_UpdateHandler_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_UpdateHandler_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_UpdateHandler_Call[T, X]) Return() *IControllerGeneric_UpdateHandler_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_UpdateHandler_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_UpdateHandler_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
+// Upsert provides a mock function with given fields: _a0
+func (_m *IControllerGeneric[T, X]) Upsert(_a0 *gin.Context) {
+	_m.Called(_a0)
+}
+
+// IControllerGeneric_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type IControllerGeneric_Upsert_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - _a0 *gin.Context
+func (_e *IControllerGeneric_Expecter[T, X]) Upsert(_a0 interface{}) *IControllerGeneric_Upsert_Call[T, X] {
+	return &IControllerGeneric_Upsert_Call[T, X]{Call: _e.mock.On("Upsert", _a0)}
+}
+
+func (_c *IControllerGeneric_Upsert_Call[T, X]) Run(run func(_a0 *gin.Context)) *IControllerGeneric_Upsert_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*gin.Context))
+	})
+	return _c
+}
+
+func (_c *IControllerGeneric_Upsert_Call[T, X]) Return() *IControllerGeneric_Upsert_Call[T, X] {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IControllerGeneric_Upsert_Call[T, X]) RunAndReturn(run func(*gin.Context)) *IControllerGeneric_Upsert_Call[T, X] {
+	_c.Run(run)
+	return _c
+}
+
 // NewIControllerGeneric creates a new instance of IControllerGeneric. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIControllerGeneric[T interface{}, X interface{ string | uint }](t interface {
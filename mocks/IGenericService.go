@@ -5,7 +5,15 @@ package mocks
 import (
 	context "context"
 
+	audit "github.com/alvarotor/entitier-go/audit"
+
+	history "github.com/alvarotor/entitier-go/history"
+
 	mock "github.com/stretchr/testify/mock"
+
+	models "github.com/alvarotor/entitier-go/models"
+
+	time "time"
 )
 
 // IGenericService is an autogenerated mock type for the IGenericService type
@@ -25,14 +33,23 @@ func (_m *IGenericService[T, X]) EXPECT() *IGenericService_Expecter[T, X] {
 func (_m *IGenericService[T, X]) Create(_a0 context.Context, _a1 T) (T, error) {
 	ret := _m.Called(_a0, _a1)
 
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
 	var r0 T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, T) (T, error)); ok {
+		return rf(_a0, _a1)
+	}
 	if rf, ok := ret.Get(0).(func(context.Context, T) T); ok {
 		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Get(0).(T)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(T)
+		}
 	}
 
-	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, T) error); ok {
 		r1 = rf(_a0, _a1)
 	} else {
@@ -66,10 +83,79 @@ func (_c *IGenericService_Create_Call[T, X]) Return(_a0 T, _a1 error) *IGenericS
 	return _c
 }
 
+func (_c *IGenericService_Create_Call[T, X]) RunAndReturn(run func(context.Context, T) (T, error)) *IGenericService_Create_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateMany provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IGenericService[T, X]) CreateMany(_a0 context.Context, _a1 []T, _a2 int) ([]models.BatchResult[T], error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMany")
+	}
+
+	var r0 []models.BatchResult[T]
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []T, int) ([]models.BatchResult[T], error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []T, int) []models.BatchResult[T]); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BatchResult[T])
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []T, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_CreateMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMany'
+type IGenericService_CreateMany_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// CreateMany is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 []T
+//   - _a2 int
+func (_e *IGenericService_Expecter[T, X]) CreateMany(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IGenericService_CreateMany_Call[T, X] {
+	return &IGenericService_CreateMany_Call[T, X]{Call: _e.mock.On("CreateMany", _a0, _a1, _a2)}
+}
+
+func (_c *IGenericService_CreateMany_Call[T, X]) Run(run func(_a0 context.Context, _a1 []T, _a2 int)) *IGenericService_CreateMany_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]T), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *IGenericService_CreateMany_Call[T, X]) Return(_a0 []models.BatchResult[T], _a1 error) *IGenericService_CreateMany_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_CreateMany_Call[T, X]) RunAndReturn(run func(context.Context, []T, int) ([]models.BatchResult[T], error)) *IGenericService_CreateMany_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Delete provides a mock function with given fields: _a0, _a1, _a2
 func (_m *IGenericService[T, X]) Delete(_a0 context.Context, _a1 X, _a2 bool) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
 	var r0 error
 	if rf, ok := ret.Get(0).(func(context.Context, X, bool) error); ok {
 		r0 = rf(_a0, _a1, _a2)
@@ -105,11 +191,97 @@ func (_c *IGenericService_Delete_Call[T, X]) Return(_a0 error) *IGenericService_
 	return _c
 }
 
+func (_c *IGenericService_Delete_Call[T, X]) RunAndReturn(run func(context.Context, X, bool) error) *IGenericService_Delete_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMany provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IGenericService[T, X]) DeleteMany(_a0 context.Context, _a1 []X, _a2 bool, _a3 ...models.QueryOption) (int64, error) {
+	_va := make([]interface{}, len(_a3))
+	for _i := range _a3 {
+		_va[_i] = _a3[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1, _a2)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMany")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []X, bool, ...models.QueryOption) (int64, error)); ok {
+		return rf(_a0, _a1, _a2, _a3...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []X, bool, ...models.QueryOption) int64); ok {
+		r0 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []X, bool, ...models.QueryOption) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_DeleteMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMany'
+type IGenericService_DeleteMany_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// DeleteMany is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 []X
+//   - _a2 bool
+//   - _a3 ...models.QueryOption
+func (_e *IGenericService_Expecter[T, X]) DeleteMany(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 ...interface{}) *IGenericService_DeleteMany_Call[T, X] {
+	return &IGenericService_DeleteMany_Call[T, X]{Call: _e.mock.On("DeleteMany",
+		append([]interface{}{_a0, _a1, _a2}, _a3...)...)}
+}
+
+func (_c *IGenericService_DeleteMany_Call[T, X]) Run(run func(_a0 context.Context, _a1 []X, _a2 bool, _a3 ...models.QueryOption)) *IGenericService_DeleteMany_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]models.QueryOption, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.QueryOption)
+			}
+		}
+		run(args[0].(context.Context), args[1].([]X), args[2].(bool), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *IGenericService_DeleteMany_Call[T, X]) Return(_a0 int64, _a1 error) *IGenericService_DeleteMany_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_DeleteMany_Call[T, X]) RunAndReturn(run func(context.Context, []X, bool, ...models.QueryOption) (int64, error)) *IGenericService_DeleteMany_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function with given fields: _a0, _a1, _a2
 func (_m *IGenericService[T, X]) Get(_a0 context.Context, _a1 X, _a2 string) (*T, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
 	var r0 *T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, X, string) (*T, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
 	if rf, ok := ret.Get(0).(func(context.Context, X, string) *T); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
@@ -118,7 +290,6 @@ func (_m *IGenericService[T, X]) Get(_a0 context.Context, _a1 X, _a2 string) (*T
 		}
 	}
 
-	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, X, string) error); ok {
 		r1 = rf(_a0, _a1, _a2)
 	} else {
@@ -153,22 +324,41 @@ func (_c *IGenericService_Get_Call[T, X]) Return(_a0 *T, _a1 error) *IGenericSer
 	return _c
 }
 
-// GetAll provides a mock function with given fields: _a0
-func (_m *IGenericService[T, X]) GetAll(_a0 context.Context) ([]*T, error) {
-	ret := _m.Called(_a0)
+func (_c *IGenericService_Get_Call[T, X]) RunAndReturn(run func(context.Context, X, string) (*T, error)) *IGenericService_Get_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAll provides a mock function with given fields: _a0, _a1
+func (_m *IGenericService[T, X]) GetAll(_a0 context.Context, _a1 ...models.QueryOption) ([]*T, error) {
+	_va := make([]interface{}, len(_a1))
+	for _i := range _a1 {
+		_va[_i] = _a1[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
 
 	var r0 []*T
-	if rf, ok := ret.Get(0).(func(context.Context) []*T); ok {
-		r0 = rf(_a0)
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...models.QueryOption) ([]*T, error)); ok {
+		return rf(_a0, _a1...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ...models.QueryOption) []*T); ok {
+		r0 = rf(_a0, _a1...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*T)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, ...models.QueryOption) error); ok {
+		r1 = rf(_a0, _a1...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -183,13 +373,21 @@ type IGenericService_GetAll_Call[T interface{}, X interface{ string | uint }] st
 
 // GetAll is a helper method to define mock.On call
 //   - _a0 context.Context
-func (_e *IGenericService_Expecter[T, X]) GetAll(_a0 interface{}) *IGenericService_GetAll_Call[T, X] {
-	return &IGenericService_GetAll_Call[T, X]{Call: _e.mock.On("GetAll", _a0)}
+//   - _a1 ...models.QueryOption
+func (_e *IGenericService_Expecter[T, X]) GetAll(_a0 interface{}, _a1 ...interface{}) *IGenericService_GetAll_Call[T, X] {
+	return &IGenericService_GetAll_Call[T, X]{Call: _e.mock.On("GetAll",
+		append([]interface{}{_a0}, _a1...)...)}
 }
 
-func (_c *IGenericService_GetAll_Call[T, X]) Run(run func(_a0 context.Context)) *IGenericService_GetAll_Call[T, X] {
+func (_c *IGenericService_GetAll_Call[T, X]) Run(run func(_a0 context.Context, _a1 ...models.QueryOption)) *IGenericService_GetAll_Call[T, X] {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		variadicArgs := make([]models.QueryOption, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.QueryOption)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
 	})
 	return _c
 }
@@ -199,52 +397,888 @@ func (_c *IGenericService_GetAll_Call[T, X]) Return(_a0 []*T, _a1 error) *IGener
 	return _c
 }
 
-// Update provides a mock function with given fields: _a0, _a1, _a2
-func (_m *IGenericService[T, X]) Update(_a0 context.Context, _a1 X, _a2 T) error {
-	ret := _m.Called(_a0, _a1, _a2)
+func (_c *IGenericService_GetAll_Call[T, X]) RunAndReturn(run func(context.Context, ...models.QueryOption) ([]*T, error)) *IGenericService_GetAll_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, X, T) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+// GetAllCursor provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *IGenericService[T, X]) GetAllCursor(_a0 context.Context, _a1 string, _a2 int, _a3 string, _a4 ...models.QueryOption) ([]*T, string, error) {
+	_va := make([]interface{}, len(_a4))
+	for _i := range _a4 {
+		_va[_i] = _a4[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1, _a2, _a3)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllCursor")
+	}
+
+	var r0 []*T
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string, ...models.QueryOption) ([]*T, string, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, string, ...models.QueryOption) []*T); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4...)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*T)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, string, ...models.QueryOption) string); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4...)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int, string, ...models.QueryOption) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3, _a4...)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// IGenericService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
-type IGenericService_Update_Call[T interface{}, X interface{ string | uint }] struct {
+// IGenericService_GetAllCursor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllCursor'
+type IGenericService_GetAllCursor_Call[T interface{}, X interface{ string | uint }] struct {
 	*mock.Call
 }
 
-// Update is a helper method to define mock.On call
+// GetAllCursor is a helper method to define mock.On call
 //   - _a0 context.Context
-//   - _a1 X
-//   - _a2 T
-func (_e *IGenericService_Expecter[T, X]) Update(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IGenericService_Update_Call[T, X] {
-	return &IGenericService_Update_Call[T, X]{Call: _e.mock.On("Update", _a0, _a1, _a2)}
+//   - _a1 string
+//   - _a2 int
+//   - _a3 string
+//   - _a4 ...models.QueryOption
+func (_e *IGenericService_Expecter[T, X]) GetAllCursor(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}, _a4 ...interface{}) *IGenericService_GetAllCursor_Call[T, X] {
+	return &IGenericService_GetAllCursor_Call[T, X]{Call: _e.mock.On("GetAllCursor",
+		append([]interface{}{_a0, _a1, _a2, _a3}, _a4...)...)}
 }
 
-func (_c *IGenericService_Update_Call[T, X]) Run(run func(_a0 context.Context, _a1 X, _a2 T)) *IGenericService_Update_Call[T, X] {
+func (_c *IGenericService_GetAllCursor_Call[T, X]) Run(run func(_a0 context.Context, _a1 string, _a2 int, _a3 string, _a4 ...models.QueryOption)) *IGenericService_GetAllCursor_Call[T, X] {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(X), args[2].(T))
+		variadicArgs := make([]models.QueryOption, len(args)-4)
+		for i, a := range args[4:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.QueryOption)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(string), variadicArgs...)
 	})
 	return _c
 }
 
-func (_c *IGenericService_Update_Call[T, X]) Return(_a0 error) *IGenericService_Update_Call[T, X] {
-	_c.Call.Return(_a0)
+func (_c *IGenericService_GetAllCursor_Call[T, X]) Return(_a0 []*T, _a1 string, _a2 error) *IGenericService_GetAllCursor_Call[T, X] {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-type mockConstructorTestingTNewIGenericService interface {
-	mock.TestingT
-	Cleanup(func())
+func (_c *IGenericService_GetAllCursor_Call[T, X]) RunAndReturn(run func(context.Context, string, int, string, ...models.QueryOption) ([]*T, string, error)) *IGenericService_GetAllCursor_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
 }
 
-// NewIGenericService creates a new instance of IGenericService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-func NewIGenericService[T interface{}, X interface{ string | uint }](t mockConstructorTestingTNewIGenericService) *IGenericService[T, X] {
+// GetAllPaged provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IGenericService[T, X]) GetAllPaged(_a0 context.Context, _a1 int, _a2 int, _a3 ...models.QueryOption) ([]*T, int64, error) {
+	_va := make([]interface{}, len(_a3))
+	for _i := range _a3 {
+		_va[_i] = _a3[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1, _a2)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllPaged")
+	}
+
+	var r0 []*T
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, ...models.QueryOption) ([]*T, int64, error)); ok {
+		return rf(_a0, _a1, _a2, _a3...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, ...models.QueryOption) []*T); ok {
+		r0 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, ...models.QueryOption) int64); ok {
+		r1 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, ...models.QueryOption) error); ok {
+		r2 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IGenericService_GetAllPaged_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllPaged'
+type IGenericService_GetAllPaged_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAllPaged is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 int
+//   - _a2 int
+//   - _a3 ...models.QueryOption
+func (_e *IGenericService_Expecter[T, X]) GetAllPaged(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 ...interface{}) *IGenericService_GetAllPaged_Call[T, X] {
+	return &IGenericService_GetAllPaged_Call[T, X]{Call: _e.mock.On("GetAllPaged",
+		append([]interface{}{_a0, _a1, _a2}, _a3...)...)}
+}
+
+func (_c *IGenericService_GetAllPaged_Call[T, X]) Run(run func(_a0 context.Context, _a1 int, _a2 int, _a3 ...models.QueryOption)) *IGenericService_GetAllPaged_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]models.QueryOption, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.QueryOption)
+			}
+		}
+		run(args[0].(context.Context), args[1].(int), args[2].(int), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *IGenericService_GetAllPaged_Call[T, X]) Return(_a0 []*T, _a1 int64, _a2 error) *IGenericService_GetAllPaged_Call[T, X] {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IGenericService_GetAllPaged_Call[T, X]) RunAndReturn(run func(context.Context, int, int, ...models.QueryOption) ([]*T, int64, error)) *IGenericService_GetAllPaged_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllTrashed provides a mock function with given fields: _a0, _a1
+func (_m *IGenericService[T, X]) GetAllTrashed(_a0 context.Context, _a1 ...models.QueryOption) ([]*T, error) {
+	_va := make([]interface{}, len(_a1))
+	for _i := range _a1 {
+		_va[_i] = _a1[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllTrashed")
+	}
+
+	var r0 []*T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...models.QueryOption) ([]*T, error)); ok {
+		return rf(_a0, _a1...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ...models.QueryOption) []*T); ok {
+		r0 = rf(_a0, _a1...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ...models.QueryOption) error); ok {
+		r1 = rf(_a0, _a1...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_GetAllTrashed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTrashed'
+type IGenericService_GetAllTrashed_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAllTrashed is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 ...models.QueryOption
+func (_e *IGenericService_Expecter[T, X]) GetAllTrashed(_a0 interface{}, _a1 ...interface{}) *IGenericService_GetAllTrashed_Call[T, X] {
+	return &IGenericService_GetAllTrashed_Call[T, X]{Call: _e.mock.On("GetAllTrashed",
+		append([]interface{}{_a0}, _a1...)...)}
+}
+
+func (_c *IGenericService_GetAllTrashed_Call[T, X]) Run(run func(_a0 context.Context, _a1 ...models.QueryOption)) *IGenericService_GetAllTrashed_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]models.QueryOption, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.QueryOption)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *IGenericService_GetAllTrashed_Call[T, X]) Return(_a0 []*T, _a1 error) *IGenericService_GetAllTrashed_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_GetAllTrashed_Call[T, X]) RunAndReturn(run func(context.Context, ...models.QueryOption) ([]*T, error)) *IGenericService_GetAllTrashed_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAsOf provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IGenericService[T, X]) GetAsOf(_a0 context.Context, _a1 X, _a2 time.Time) (*T, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAsOf")
+	}
+
+	var r0 *T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, X, time.Time) (*T, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, X, time.Time) *T); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, X, time.Time) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_GetAsOf_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAsOf'
+type IGenericService_GetAsOf_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// GetAsOf is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+//   - _a2 time.Time
+func (_e *IGenericService_Expecter[T, X]) GetAsOf(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IGenericService_GetAsOf_Call[T, X] {
+	return &IGenericService_GetAsOf_Call[T, X]{Call: _e.mock.On("GetAsOf", _a0, _a1, _a2)}
+}
+
+func (_c *IGenericService_GetAsOf_Call[T, X]) Run(run func(_a0 context.Context, _a1 X, _a2 time.Time)) *IGenericService_GetAsOf_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *IGenericService_GetAsOf_Call[T, X]) Return(_a0 *T, _a1 error) *IGenericService_GetAsOf_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_GetAsOf_Call[T, X]) RunAndReturn(run func(context.Context, X, time.Time) (*T, error)) *IGenericService_GetAsOf_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// History provides a mock function with given fields: _a0, _a1
+func (_m *IGenericService[T, X]) History(_a0 context.Context, _a1 X) ([]audit.Entry, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for History")
+	}
+
+	var r0 []audit.Entry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, X) ([]audit.Entry, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, X) []audit.Entry); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]audit.Entry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, X) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type IGenericService_History_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+func (_e *IGenericService_Expecter[T, X]) History(_a0 interface{}, _a1 interface{}) *IGenericService_History_Call[T, X] {
+	return &IGenericService_History_Call[T, X]{Call: _e.mock.On("History", _a0, _a1)}
+}
+
+func (_c *IGenericService_History_Call[T, X]) Run(run func(_a0 context.Context, _a1 X)) *IGenericService_History_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X))
+	})
+	return _c
+}
+
+func (_c *IGenericService_History_Call[T, X]) Return(_a0 []audit.Entry, _a1 error) *IGenericService_History_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_History_Call[T, X]) RunAndReturn(run func(context.Context, X) ([]audit.Entry, error)) *IGenericService_History_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListVersions provides a mock function with given fields: _a0, _a1
+func (_m *IGenericService[T, X]) ListVersions(_a0 context.Context, _a1 X) ([]history.Version[T], error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVersions")
+	}
+
+	var r0 []history.Version[T]
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, X) ([]history.Version[T], error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, X) []history.Version[T]); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]history.Version[T])
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, X) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_ListVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVersions'
+type IGenericService_ListVersions_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// ListVersions is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+func (_e *IGenericService_Expecter[T, X]) ListVersions(_a0 interface{}, _a1 interface{}) *IGenericService_ListVersions_Call[T, X] {
+	return &IGenericService_ListVersions_Call[T, X]{Call: _e.mock.On("ListVersions", _a0, _a1)}
+}
+
+func (_c *IGenericService_ListVersions_Call[T, X]) Run(run func(_a0 context.Context, _a1 X)) *IGenericService_ListVersions_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X))
+	})
+	return _c
+}
+
+func (_c *IGenericService_ListVersions_Call[T, X]) Return(_a0 []history.Version[T], _a1 error) *IGenericService_ListVersions_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_ListVersions_Call[T, X]) RunAndReturn(run func(context.Context, X) ([]history.Version[T], error)) *IGenericService_ListVersions_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Patch provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IGenericService[T, X]) Patch(_a0 context.Context, _a1 X, _a2 models.Patch) (*T, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Patch")
+	}
+
+	var r0 *T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, X, models.Patch) (*T, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, X, models.Patch) *T); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, X, models.Patch) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_Patch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Patch'
+type IGenericService_Patch_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Patch is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+//   - _a2 models.Patch
+func (_e *IGenericService_Expecter[T, X]) Patch(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IGenericService_Patch_Call[T, X] {
+	return &IGenericService_Patch_Call[T, X]{Call: _e.mock.On("Patch", _a0, _a1, _a2)}
+}
+
+func (_c *IGenericService_Patch_Call[T, X]) Run(run func(_a0 context.Context, _a1 X, _a2 models.Patch)) *IGenericService_Patch_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X), args[2].(models.Patch))
+	})
+	return _c
+}
+
+func (_c *IGenericService_Patch_Call[T, X]) Return(_a0 *T, _a1 error) *IGenericService_Patch_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_Patch_Call[T, X]) RunAndReturn(run func(context.Context, X, models.Patch) (*T, error)) *IGenericService_Patch_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Purge provides a mock function with given fields: _a0, _a1
+func (_m *IGenericService[T, X]) Purge(_a0 context.Context, _a1 time.Time) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type IGenericService_Purge_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 time.Time
+func (_e *IGenericService_Expecter[T, X]) Purge(_a0 interface{}, _a1 interface{}) *IGenericService_Purge_Call[T, X] {
+	return &IGenericService_Purge_Call[T, X]{Call: _e.mock.On("Purge", _a0, _a1)}
+}
+
+func (_c *IGenericService_Purge_Call[T, X]) Run(run func(_a0 context.Context, _a1 time.Time)) *IGenericService_Purge_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *IGenericService_Purge_Call[T, X]) Return(_a0 int64, _a1 error) *IGenericService_Purge_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_Purge_Call[T, X]) RunAndReturn(run func(context.Context, time.Time) (int64, error)) *IGenericService_Purge_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function with given fields: _a0, _a1
+func (_m *IGenericService[T, X]) Restore(_a0 context.Context, _a1 X) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, X) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IGenericService_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type IGenericService_Restore_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+func (_e *IGenericService_Expecter[T, X]) Restore(_a0 interface{}, _a1 interface{}) *IGenericService_Restore_Call[T, X] {
+	return &IGenericService_Restore_Call[T, X]{Call: _e.mock.On("Restore", _a0, _a1)}
+}
+
+func (_c *IGenericService_Restore_Call[T, X]) Run(run func(_a0 context.Context, _a1 X)) *IGenericService_Restore_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X))
+	})
+	return _c
+}
+
+func (_c *IGenericService_Restore_Call[T, X]) Return(_a0 error) *IGenericService_Restore_Call[T, X] {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IGenericService_Restore_Call[T, X]) RunAndReturn(run func(context.Context, X) error) *IGenericService_Restore_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revert provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IGenericService[T, X]) Revert(_a0 context.Context, _a1 X, _a2 uint64) (*T, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revert")
+	}
+
+	var r0 *T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, X, uint64) (*T, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, X, uint64) *T); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, X, uint64) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_Revert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revert'
+type IGenericService_Revert_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Revert is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+//   - _a2 uint64
+func (_e *IGenericService_Expecter[T, X]) Revert(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IGenericService_Revert_Call[T, X] {
+	return &IGenericService_Revert_Call[T, X]{Call: _e.mock.On("Revert", _a0, _a1, _a2)}
+}
+
+func (_c *IGenericService_Revert_Call[T, X]) Run(run func(_a0 context.Context, _a1 X, _a2 uint64)) *IGenericService_Revert_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X), args[2].(uint64))
+	})
+	return _c
+}
+
+func (_c *IGenericService_Revert_Call[T, X]) Return(_a0 *T, _a1 error) *IGenericService_Revert_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_Revert_Call[T, X]) RunAndReturn(run func(context.Context, X, uint64) (*T, error)) *IGenericService_Revert_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: _a0, _a1, _a2
+func (_m *IGenericService[T, X]) Update(_a0 context.Context, _a1 X, _a2 T) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, X, T) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IGenericService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type IGenericService_Update_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+//   - _a2 T
+func (_e *IGenericService_Expecter[T, X]) Update(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IGenericService_Update_Call[T, X] {
+	return &IGenericService_Update_Call[T, X]{Call: _e.mock.On("Update", _a0, _a1, _a2)}
+}
+
+func (_c *IGenericService_Update_Call[T, X]) Run(run func(_a0 context.Context, _a1 X, _a2 T)) *IGenericService_Update_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X), args[2].(T))
+	})
+	return _c
+}
+
+func (_c *IGenericService_Update_Call[T, X]) Return(_a0 error) *IGenericService_Update_Call[T, X] {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IGenericService_Update_Call[T, X]) RunAndReturn(run func(context.Context, X, T) error) *IGenericService_Update_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateField provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IGenericService[T, X]) UpdateField(_a0 context.Context, _a1 X, _a2 string, _a3 interface{}) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateField")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, X, string, interface{}) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IGenericService_UpdateField_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateField'
+type IGenericService_UpdateField_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// UpdateField is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 X
+//   - _a2 string
+//   - _a3 interface{}
+func (_e *IGenericService_Expecter[T, X]) UpdateField(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *IGenericService_UpdateField_Call[T, X] {
+	return &IGenericService_UpdateField_Call[T, X]{Call: _e.mock.On("UpdateField", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *IGenericService_UpdateField_Call[T, X]) Run(run func(_a0 context.Context, _a1 X, _a2 string, _a3 interface{})) *IGenericService_UpdateField_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(X), args[2].(string), args[3].(interface{}))
+	})
+	return _c
+}
+
+func (_c *IGenericService_UpdateField_Call[T, X]) Return(_a0 error) *IGenericService_UpdateField_Call[T, X] {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IGenericService_UpdateField_Call[T, X]) RunAndReturn(run func(context.Context, X, string, interface{}) error) *IGenericService_UpdateField_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateMany provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IGenericService[T, X]) UpdateMany(_a0 context.Context, _a1 T, _a2 []X, _a3 ...models.QueryOption) (int64, error) {
+	_va := make([]interface{}, len(_a3))
+	for _i := range _a3 {
+		_va[_i] = _a3[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1, _a2)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMany")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, T, []X, ...models.QueryOption) (int64, error)); ok {
+		return rf(_a0, _a1, _a2, _a3...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, T, []X, ...models.QueryOption) int64); ok {
+		r0 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, T, []X, ...models.QueryOption) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_UpdateMany_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMany'
+type IGenericService_UpdateMany_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// UpdateMany is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 T
+//   - _a2 []X
+//   - _a3 ...models.QueryOption
+func (_e *IGenericService_Expecter[T, X]) UpdateMany(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 ...interface{}) *IGenericService_UpdateMany_Call[T, X] {
+	return &IGenericService_UpdateMany_Call[T, X]{Call: _e.mock.On("UpdateMany",
+		append([]interface{}{_a0, _a1, _a2}, _a3...)...)}
+}
+
+func (_c *IGenericService_UpdateMany_Call[T, X]) Run(run func(_a0 context.Context, _a1 T, _a2 []X, _a3 ...models.QueryOption)) *IGenericService_UpdateMany_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]models.QueryOption, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.QueryOption)
+			}
+		}
+		run(args[0].(context.Context), args[1].(T), args[2].([]X), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *IGenericService_UpdateMany_Call[T, X]) Return(_a0 int64, _a1 error) *IGenericService_UpdateMany_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_UpdateMany_Call[T, X]) RunAndReturn(run func(context.Context, T, []X, ...models.QueryOption) (int64, error)) *IGenericService_UpdateMany_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IGenericService[T, X]) Upsert(_a0 context.Context, _a1 T, _a2 []string, _a3 []string) (T, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 T
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, T, []string, []string) (T, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, T, []string, []string) T); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(T)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, T, []string, []string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IGenericService_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type IGenericService_Upsert_Call[T interface{}, X interface{ string | uint }] struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - _a0 context.Context
+//   - _a1 T
+//   - _a2 []string
+//   - _a3 []string
+func (_e *IGenericService_Expecter[T, X]) Upsert(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *IGenericService_Upsert_Call[T, X] {
+	return &IGenericService_Upsert_Call[T, X]{Call: _e.mock.On("Upsert", _a0, _a1, _a2, _a3)}
+}
+
+func (_c *IGenericService_Upsert_Call[T, X]) Run(run func(_a0 context.Context, _a1 T, _a2 []string, _a3 []string)) *IGenericService_Upsert_Call[T, X] {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(T), args[2].([]string), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *IGenericService_Upsert_Call[T, X]) Return(_a0 T, _a1 error) *IGenericService_Upsert_Call[T, X] {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IGenericService_Upsert_Call[T, X]) RunAndReturn(run func(context.Context, T, []string, []string) (T, error)) *IGenericService_Upsert_Call[T, X] {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIGenericService creates a new instance of IGenericService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIGenericService[T interface{}, X interface{ string | uint }](t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IGenericService[T, X] {
 	mock := &IGenericService[T, X]{}
 	mock.Mock.Test(t)
 
@@ -0,0 +1,9 @@
+package models
+
+// BatchResult reports the outcome of one item of a batch operation. Index
+// is the position of the item in the input slice.
+type BatchResult[T any] struct {
+	Index int
+	Item  T
+	Err   error
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// DuplicateKeyError reports a unique constraint violation. Constraint and
+// Columns are filled from the database message when the dialect reports
+// them, and Fields holds the matching JSON member names of the model.
+//
+// It matches both ErrDuplicateKey and, for compatibility, the older
+// ErrDuplicatedKeyEmail with errors.Is.
+type DuplicateKeyError struct {
+	Constraint string
+	Columns    []string
+	Fields     []string
+	Err        error
+}
+
+func (e *DuplicateKeyError) Error() string {
+	switch {
+	case len(e.Fields) > 0:
+		return ErrDuplicateKey.Error() + ": " + strings.Join(e.Fields, ", ") + " already exists"
+	case len(e.Columns) > 0:
+		return ErrDuplicateKey.Error() + ": " + strings.Join(e.Columns, ", ") + " already exists"
+	case e.Constraint != "":
+		return ErrDuplicateKey.Error() + ": constraint " + e.Constraint + " violated"
+	}
+	return ErrDuplicateKey.Error()
+}
+
+func (e *DuplicateKeyError) Is(target error) bool {
+	return target == ErrDuplicateKey || target == ErrDuplicatedKeyEmail
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return e.Err
+}
+
+func duplicateKeyDetails(err error) map[string]interface{} {
+	var dup *DuplicateKeyError
+	if !errors.As(err, &dup) {
+		return nil
+	}
+	details := map[string]interface{}{}
+	if dup.Constraint != "" {
+		details["constraint"] = dup.Constraint
+	}
+	if len(dup.Fields) > 0 {
+		details["fields"] = dup.Fields
+	} else if len(dup.Columns) > 0 {
+		details["fields"] = dup.Columns
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
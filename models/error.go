@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// StatusClientClosedRequest is reported when the client went away before
+// the request completed.
+const StatusClientClosedRequest = 499
+
+const (
+	CodeInternal             = "internal"
+	CodeInvalidRequest       = "invalid_request"
+	CodeNotFound             = "not_found"
+	CodeDuplicateKey         = "duplicate_key"
+	CodeForeignKeyViolation  = "foreign_key_violation"
+	CodeCheckViolation       = "check_violation"
+	CodeVersionConflict      = "version_conflict"
+	CodeEmptyModel           = "empty_model"
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidPagination    = "invalid_pagination"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeUnknownField         = "unknown_field"
+	CodeInvalidFilter        = "invalid_filter"
+	CodeInvalidSort          = "invalid_sort"
+	CodeInvalidVersion       = "invalid_version"
+	CodeInvalidParameter     = "invalid_parameter"
+	CodeMissingCondition     = "missing_condition"
+	CodeSoftDeleteDisabled   = "soft_delete_not_supported"
+	CodeInvalidPatch         = "invalid_patch"
+	CodePatchTestFailed      = "patch_test_failed"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeRequestCanceled      = "request_canceled"
+	CodeDeadlineExceeded     = "deadline_exceeded"
+	CodeValidationFailed     = "validation_failed"
+	CodeAuditDisabled        = "audit_not_enabled"
+	CodeVersioningDisabled   = "versioning_not_enabled"
+	CodeTenantRequired       = "tenant_required"
+)
+
+// Error is an error with a machine-readable code and the HTTP status it is
+// reported with. Err is the cause, so sentinels stay matchable with
+// errors.Is, and Details carries extra members for the client.
+type Error struct {
+	Code    string
+	Status  int
+	Err     error
+	Details map[string]interface{}
+}
+
+func NewError(code string, status int, err error) *Error {
+	return &Error{Code: code, Status: status, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetail returns a copy of e with key set in its details.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
+	}
+	details[key] = value
+	return &Error{Code: e.Code, Status: e.Status, Err: e.Err, Details: details}
+}
+
+// errorMappings is checked in order, so wrappers such as ErrRequestCanceled
+// win over the errors they wrap. details, when set, extracts the members
+// reported alongside the code.
+var errorMappings = []struct {
+	target  error
+	code    string
+	status  int
+	details func(error) map[string]interface{}
+}{
+	{ErrRequestCanceled, CodeRequestCanceled, StatusClientClosedRequest, nil},
+	{ErrDeadlineExceeded, CodeDeadlineExceeded, http.StatusGatewayTimeout, nil},
+	{context.Canceled, CodeRequestCanceled, StatusClientClosedRequest, nil},
+	{context.DeadlineExceeded, CodeDeadlineExceeded, http.StatusGatewayTimeout, nil},
+	{ErrConflict, CodeVersionConflict, http.StatusConflict, nil},
+	{ErrNotFound, CodeNotFound, http.StatusNotFound, nil},
+	{ErrVersionNotFound, CodeNotFound, http.StatusNotFound, nil},
+	{gorm.ErrRecordNotFound, CodeNotFound, http.StatusNotFound, nil},
+	{ErrDuplicateKey, CodeDuplicateKey, http.StatusConflict, duplicateKeyDetails},
+	{ErrDuplicatedKeyEmail, CodeDuplicateKey, http.StatusConflict, duplicateKeyDetails},
+	{gorm.ErrDuplicatedKey, CodeDuplicateKey, http.StatusConflict, duplicateKeyDetails},
+	{gorm.ErrForeignKeyViolated, CodeForeignKeyViolation, http.StatusConflict, nil},
+	{gorm.ErrCheckConstraintViolated, CodeCheckViolation, http.StatusUnprocessableEntity, nil},
+	{ErrModelCannotBeEmpty, CodeEmptyModel, http.StatusBadRequest, nil},
+	{ErrMustProvideValidID, CodeInvalidID, http.StatusBadRequest, nil},
+	{ErrIDTypeMismatch, CodeInvalidID, http.StatusBadRequest, nil},
+	{ErrInvalidPagination, CodeInvalidPagination, http.StatusBadRequest, nil},
+	{ErrInvalidCursor, CodeInvalidCursor, http.StatusBadRequest, nil},
+	{ErrUnknownField, CodeUnknownField, http.StatusBadRequest, nil},
+	{ErrInvalidFilter, CodeInvalidFilter, http.StatusBadRequest, nil},
+	{ErrInvalidSort, CodeInvalidSort, http.StatusBadRequest, nil},
+	{ErrInvalidVersion, CodeInvalidVersion, http.StatusBadRequest, nil},
+	{ErrInvalidPermanentFlag, CodeInvalidParameter, http.StatusBadRequest, nil},
+	{ErrInvalidDuration, CodeInvalidParameter, http.StatusBadRequest, nil},
+	{ErrInvalidTimestamp, CodeInvalidParameter, http.StatusBadRequest, nil},
+	{ErrMissingCondition, CodeMissingCondition, http.StatusBadRequest, nil},
+	{ErrSoftDeleteNotSupported, CodeSoftDeleteDisabled, http.StatusBadRequest, nil},
+	{ErrInvalidPatch, CodeInvalidPatch, http.StatusBadRequest, nil},
+	{ErrPatchTestFailed, CodePatchTestFailed, http.StatusConflict, nil},
+	{ErrUnsupportedPatchType, CodeUnsupportedMediaType, http.StatusUnsupportedMediaType, nil},
+	{ErrValidation, CodeValidationFailed, http.StatusUnprocessableEntity, validationDetails},
+	{ErrTenantRequired, CodeTenantRequired, http.StatusUnauthorized, nil},
+	{ErrAuditNotEnabled, CodeAuditDisabled, http.StatusNotImplemented, nil},
+	{ErrVersioningNotEnabled, CodeVersioningDisabled, http.StatusNotImplemented, nil},
+}
+
+// AsError maps err, which may come from the repository or straight from
+// GORM, to an *Error. Errors that are already an *Error are returned as is
+// and unknown errors are reported as CodeInternal with status 500.
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.target) {
+			mapped := NewError(m.code, m.status, err)
+			if m.details != nil {
+				mapped.Details = m.details(err)
+			}
+			return mapped
+		}
+	}
+	return NewError(CodeInternal, http.StatusInternalServerError, err)
+}
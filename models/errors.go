@@ -3,9 +3,32 @@ package models
 import "errors"
 
 var (
-	ErrNotFound           = errors.New("no rows found")
-	ErrDuplicatedKeyEmail = errors.New("duplicated key. Email already exists")
-	ErrModelCannotBeEmpty = errors.New("model cannot be empty")
-	ErrMustProvideValidID = errors.New("must provide valid id")
-	ErrIDTypeMismatch     = errors.New("id type mismatch")
+	ErrNotFound               = errors.New("no rows found")
+	ErrDuplicatedKeyEmail     = errors.New("duplicated key. Email already exists")
+	ErrDuplicateKey           = errors.New("duplicated key")
+	ErrModelCannotBeEmpty     = errors.New("model cannot be empty")
+	ErrMustProvideValidID     = errors.New("must provide valid id")
+	ErrIDTypeMismatch         = errors.New("id type mismatch")
+	ErrInvalidPagination      = errors.New("invalid pagination parameters")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+	ErrUnknownField           = errors.New("unknown field")
+	ErrInvalidFilter          = errors.New("invalid filter")
+	ErrInvalidSort            = errors.New("invalid sort")
+	ErrRequestCanceled        = errors.New("request canceled")
+	ErrDeadlineExceeded       = errors.New("request deadline exceeded")
+	ErrConflict               = errors.New("version conflict. The record was modified by someone else")
+	ErrInvalidVersion         = errors.New("invalid version")
+	ErrSoftDeleteNotSupported = errors.New("model does not support soft delete")
+	ErrInvalidPermanentFlag   = errors.New("permanent must be true or false")
+	ErrInvalidDuration        = errors.New("invalid duration")
+	ErrMissingCondition       = errors.New("batch operation requires ids or filters")
+	ErrInvalidPatch           = errors.New("invalid patch document")
+	ErrPatchTestFailed        = errors.New("patch test operation failed")
+	ErrUnsupportedPatchType   = errors.New("unsupported patch media type")
+	ErrValidation             = errors.New("validation failed")
+	ErrAuditNotEnabled        = errors.New("audit trail is not enabled")
+	ErrVersioningNotEnabled   = errors.New("entity versioning is not enabled")
+	ErrVersionNotFound        = errors.New("version not found")
+	ErrInvalidTimestamp       = errors.New("invalid timestamp")
+	ErrTenantRequired         = errors.New("tenant is required")
 )
@@ -0,0 +1,13 @@
+package models
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Patch is a PATCH document, either an RFC 7386 merge patch or an RFC 6902
+// JSON patch, as selected by Type.
+type Patch struct {
+	Type     string
+	Document []byte
+}
@@ -0,0 +1,34 @@
+package models
+
+const (
+	FilterEq   = "eq"
+	FilterNe   = "ne"
+	FilterGt   = "gt"
+	FilterGte  = "gte"
+	FilterLt   = "lt"
+	FilterLte  = "lte"
+	FilterLike = "like"
+	FilterIn   = "in"
+	FilterNull = "null"
+)
+
+// QueryOption narrows or orders the rows returned by the list methods of
+// the generic repository.
+type QueryOption interface {
+	queryOption()
+}
+
+type Filter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+func (Filter) queryOption() {}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+func (Sort) queryOption() {}
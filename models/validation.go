@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// FieldError describes why one field failed validation. Field is the JSON
+// path of the field, such as "email" or "address.city", and is empty for
+// errors about the model as a whole.
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError aggregates every field that failed validation. It matches
+// ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return ErrValidation.Error()
+	}
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		if f.Field == "" {
+			msgs[i] = f.Message
+			continue
+		}
+		msgs[i] = f.Field + " " + f.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func validationDetails(err error) map[string]interface{} {
+	var v *ValidationError
+	if !errors.As(err, &v) || len(v.Fields) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"fields": v.Fields}
+}
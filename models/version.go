@@ -0,0 +1,8 @@
+package models
+
+// Versioned can be implemented by models whose optimistic-locking field is
+// not tagged with `entitier:"version"`. VersionField returns the struct
+// field name holding the version number.
+type Versioned interface {
+	VersionField() string
+}
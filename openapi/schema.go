@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	deletedAtType  = reflect.TypeOf(gorm.DeletedAt{})
+	nullTimeType   = reflect.TypeOf(sql.NullTime{})
+	nullStringType = reflect.TypeOf(sql.NullString{})
+	nullInt64Type  = reflect.TypeOf(sql.NullInt64{})
+	nullBoolType   = reflect.TypeOf(sql.NullBool{})
+	nullFloatType  = reflect.TypeOf(sql.NullFloat64{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaFor describes t, registering named structs as components so they
+// are referenced rather than repeated. Callers must hold s.mu.
+func (s *Spec) schemaFor(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	result := s.baseSchema(t)
+	if nullable {
+		result = nullableSchema(result)
+	}
+	return result
+}
+
+func (s *Spec) baseSchema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case deletedAtType, nullTimeType:
+		return &Schema{Type: []string{"string", "null"}, Format: "date-time"}
+	case nullStringType:
+		return &Schema{Type: []string{"string", "null"}}
+	case nullInt64Type:
+		return &Schema{Type: []string{"integer", "null"}, Format: "int64"}
+	case nullBoolType:
+		return &Schema{Type: []string{"boolean", "null"}}
+	case nullFloatType:
+		return &Schema{Type: []string{"number", "null"}, Format: "double"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: new(float64)}
+	case reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: new(float64)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return s.structSchema(t)
+	}
+	return &Schema{}
+}
+
+func (s *Spec) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name == "" {
+		result := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		s.addFields(result, t)
+		return result
+	}
+
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := s.schemas[name]; ok {
+		return ref
+	}
+	result := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.schemas[name] = result
+	s.addFields(result, t)
+	return ref
+}
+
+// addFields adds the JSON members of struct t to result, flattening
+// embedded structs the way encoding/json does and reading GORM and binding
+// tags for constraints.
+func (s *Spec) addFields(result *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			s.addFields(result, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := s.schemaFor(field.Type)
+		tags := schema.ParseTagSetting(field.Tag.Get("gorm"), ";")
+		if _, ok := tags["PRIMARYKEY"]; ok {
+			prop.ReadOnly = true
+		} else if _, ok := tags["PRIMARY_KEY"]; ok {
+			prop.ReadOnly = true
+		}
+		if size, err := strconv.Atoi(tags["SIZE"]); err == nil && prop.Type == "string" {
+			prop.MaxLength = &size
+		}
+		if def, ok := tags["DEFAULT"]; ok {
+			prop.Default = strings.Trim(def, "'")
+		}
+		if comment, ok := tags["COMMENT"]; ok {
+			prop.Description = comment
+		}
+
+		_, notNull := tags["NOT NULL"]
+		_, hasDefault := tags["DEFAULT"]
+		if !prop.ReadOnly && !omitEmpty && ((notNull && !hasDefault) || isRequiredByValidator(field)) {
+			result.Required = append(result.Required, name)
+		}
+		result.Properties[name] = prop
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return "", false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(opts, "omitempty")
+}
+
+func isRequiredByValidator(field reflect.StructField) bool {
+	for _, key := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(field.Tag.Get(key), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func nullableSchema(result *Schema) *Schema {
+	switch typ := result.Type.(type) {
+	case string:
+		result.Type = []string{typ, "null"}
+		return result
+	case []string:
+		return result
+	}
+	if result.Ref != "" {
+		return &Schema{AnyOf: []*Schema{result, {Type: "null"}}}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/schema"
+)
+
+// Spec collects the operations of registered resources and renders them as
+// an OpenAPI document. It is safe for concurrent use.
+type Spec struct {
+	mu         sync.RWMutex
+	info       Info
+	paths      map[string]PathItem
+	schemas    map[string]*Schema
+	tags       map[string]bool
+	modelCache sync.Map
+}
+
+func NewSpec(title string, version string) *Spec {
+	return &Spec{
+		info:    Info{Title: title, Version: version},
+		paths:   map[string]PathItem{},
+		schemas: map[string]*Schema{},
+		tags:    map[string]bool{},
+	}
+}
+
+func (s *Spec) SetDescription(description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info.Description = description
+}
+
+// SchemaOf returns the schema of t, adding named structs to the components.
+func (s *Spec) SchemaOf(t reflect.Type) *Schema {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schemaFor(t)
+}
+
+// AddSchema registers schema as a named component and returns a reference.
+func (s *Spec) AddSchema(name string, schema *Schema) *Schema {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[name] = schema
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// AddOperation documents op under path, written with gin syntax such as
+// /users/:id, and method.
+func (s *Spec) AddOperation(path string, method string, op *Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = openAPIPath(path)
+	if s.paths[path] == nil {
+		s.paths[path] = PathItem{}
+	}
+	s.paths[path][strings.ToLower(method)] = op
+	for _, tag := range op.Tags {
+		s.tags[tag] = true
+	}
+}
+
+// FilterParameters describes the query parameters accepted as filters for
+// the columns of model type t that are visible in its JSON representation.
+func (s *Spec) FilterParameters(t reflect.Type) []Parameter {
+	parsed, err := schema.Parse(reflect.New(t).Interface(), &s.modelCache, schema.NamingStrategy{})
+	if err != nil {
+		return nil
+	}
+
+	var params []Parameter
+	for _, field := range parsed.Fields {
+		if name, _ := jsonName(field.StructField); field.DBName == "" || name == "-" {
+			continue
+		}
+		switch field.DataType {
+		case schema.Bool, schema.Int, schema.Uint, schema.Float, schema.String, schema.Time:
+		default:
+			continue
+		}
+		params = append(params, Parameter{
+			Name:        field.DBName,
+			In:          "query",
+			Description: "Filter on " + field.DBName + ". Use " + field.DBName + "[op] with op one of ne, gt, gte, lt, lte, like, in or null for other comparisons.",
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	return params
+}
+
+func (s *Spec) Document() Document {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc := Document{
+		OpenAPI:    Version,
+		Info:       s.info,
+		Paths:      make(map[string]PathItem, len(s.paths)),
+		Components: Components{Schemas: make(map[string]*Schema, len(s.schemas))},
+	}
+	for path, item := range s.paths {
+		methods := make(PathItem, len(item))
+		for method, op := range item {
+			methods[method] = op
+		}
+		doc.Paths[path] = methods
+	}
+	for name, schema := range s.schemas {
+		doc.Components.Schemas[name] = schema
+	}
+	for tag := range s.tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+// Handler serves the document as JSON, to be mounted at any route, e.g.
+// r.GET("/openapi.json", spec.Handler()).
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.Document())
+	}
+}
+
+// openAPIPath turns gin parameters (:id, *path) into OpenAPI templates.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
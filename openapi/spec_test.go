@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Order struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+type Customer struct {
+	gorm.Model
+	Email    string  `json:"email" gorm:"size:120;not null;unique"`
+	Nickname *string `json:"nickname,omitempty"`
+	Age      int     `json:"age" binding:"required"`
+	Status   string  `json:"status" gorm:"default:'active'"`
+	Password string  `json:"-"`
+	Orders   []Order `json:"orders" gorm:"foreignKey:ID"`
+	internal string
+}
+
+func TestSpec_SchemaOf(t *testing.T) {
+	spec := NewSpec("test", "1.0.0")
+
+	ref := spec.SchemaOf(reflect.TypeOf(Customer{}))
+	assert.Equal(t, "#/components/schemas/Customer", ref.Ref)
+
+	doc := spec.Document()
+	customer := doc.Components.Schemas["Customer"]
+	if !assert.NotNil(t, customer) {
+		return
+	}
+
+	assert.ElementsMatch(t, []string{"ID", "CreatedAt", "UpdatedAt", "DeletedAt", "email", "nickname", "age", "status", "orders"}, keys(customer.Properties))
+	assert.ElementsMatch(t, []string{"email", "age"}, customer.Required)
+
+	assert.Equal(t, "integer", customer.Properties["ID"].Type)
+	assert.True(t, customer.Properties["ID"].ReadOnly)
+	assert.Equal(t, "date-time", customer.Properties["CreatedAt"].Format)
+	assert.Equal(t, []string{"string", "null"}, customer.Properties["DeletedAt"].Type)
+	assert.Equal(t, 120, *customer.Properties["email"].MaxLength)
+	assert.Equal(t, []string{"string", "null"}, customer.Properties["nickname"].Type)
+	assert.Equal(t, "active", customer.Properties["status"].Default)
+	assert.Equal(t, "#/components/schemas/Order", customer.Properties["orders"].Items.Ref)
+	assert.NotNil(t, doc.Components.Schemas["Order"])
+}
+
+func TestSpec_FilterParameters(t *testing.T) {
+	spec := NewSpec("test", "1.0.0")
+
+	var names []string
+	for _, param := range spec.FilterParameters(reflect.TypeOf(Customer{})) {
+		assert.Equal(t, "query", param.In)
+		names = append(names, param.Name)
+	}
+	assert.ElementsMatch(t, []string{"id", "created_at", "updated_at", "deleted_at", "email", "nickname", "age", "status"}, names)
+}
+
+func TestSpec_Handler(t *testing.T) {
+	spec := NewSpec("test", "1.0.0")
+	spec.AddOperation("/api/customers/:id", http.MethodGet, &Operation{
+		OperationID: "get_customers",
+		Tags:        []string{"customers"},
+		Responses:   map[string]Response{"200": {Description: "ok"}},
+	})
+
+	r := gin.New()
+	r.GET("/docs/openapi.json", spec.Handler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var doc Document
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, "test", doc.Info.Title)
+	assert.Equal(t, "get_customers", doc.Paths["/api/customers/{id}"]["get"].OperationID)
+	assert.Equal(t, []Tag{{Name: "customers"}}, doc.Tags)
+}
+
+func keys(m map[string]*Schema) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
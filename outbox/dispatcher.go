@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alvarotor/entitier-go/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultInterval  = time.Second
+	DefaultBatchSize = 100
+)
+
+// Dispatcher drains the outbox to a Publisher. Events are published in the
+// order they were written and a failing event blocks the ones after it
+// until it succeeds, so consumers see the changes of an entity in order.
+// Delivery is at least once: an event is published again when marking it
+// published fails, or when several dispatchers drain the same outbox.
+type Dispatcher struct {
+	db        *gorm.DB
+	publisher Publisher
+	log       logger.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+type DispatcherOption func(*Dispatcher)
+
+// WithInterval sets how long the dispatcher waits after draining the
+// outbox before polling it again.
+func WithInterval(interval time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+// WithBatchSize sets how many messages are read from the outbox at once.
+func WithBatchSize(size int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.batchSize = size
+	}
+}
+
+// WithLogger reports publishing failures of Run to log.
+func WithLogger(log logger.Logger) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.log = log
+	}
+}
+
+func NewDispatcher(db *gorm.DB, publisher Publisher, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		db:        db,
+		publisher: publisher,
+		interval:  DefaultInterval,
+		batchSize: DefaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.interval <= 0 {
+		d.interval = DefaultInterval
+	}
+	if d.batchSize < 1 {
+		d.batchSize = DefaultBatchSize
+	}
+	return d
+}
+
+// Run dispatches the outbox until ctx is done. A full batch is followed by
+// the next one straight away; otherwise, or after a failure, it waits for
+// the interval.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		wait := d.interval
+		n, err := d.Dispatch(ctx)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case err != nil:
+			if d.log != nil {
+				d.log.Error("outbox", err.Error())
+			}
+		case n == d.batchSize:
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// Dispatch publishes one batch of pending messages and returns how many
+// were published. It stops at the first failure, recording it on the
+// message.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	db := d.db.WithContext(ctx)
+
+	var messages []Message
+	err := db.Where("published_at IS NULL").Order("id").Limit(d.batchSize).Find(&messages).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range messages {
+		m := &messages[i]
+		if err := d.publisher.Publish(ctx, m.Event()); err != nil {
+			if ctx.Err() == nil {
+				recordErr := db.Model(m).Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": err.Error(),
+				}).Error
+				err = errors.Join(err, recordErr)
+			}
+			return i, err
+		}
+
+		now := time.Now().UTC()
+		if err := db.Model(m).Update("published_at", now).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(messages), nil
+}
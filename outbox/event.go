@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	OpCreate      = "create"
+	OpUpdate      = "update"
+	OpUpdateField = "update_field"
+	OpDelete      = "delete"
+	OpRestore     = "restore"
+)
+
+// Event is the envelope published for every change of an entity. Before
+// and After hold the JSON representation of the entity around the change
+// and are null for creations and deletions respectively. ID is unique per
+// event so consumers can discard redeliveries.
+type Event struct {
+	ID         string          `json:"id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Operation  string          `json:"operation"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// NewEvent builds the event of a change, encoding before and after, either
+// of which may be nil.
+func NewEvent(entityType string, entityID string, operation string, before interface{}, after interface{}) (Event, error) {
+	event := Event{
+		ID:         newEventID(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  operation,
+		OccurredAt: time.Now().UTC(),
+	}
+	var err error
+	if event.Before, err = payload(before); err != nil {
+		return event, err
+	}
+	if event.After, err = payload(after); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+func payload(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Message is the outbox row of an event. Rows are written in the
+// transaction of the change they describe and marked published by the
+// Dispatcher.
+type Message struct {
+	ID          uint   `gorm:"primaryKey"`
+	EventID     string `gorm:"size:32;uniqueIndex;not null"`
+	EntityType  string `gorm:"size:255;index;not null"`
+	EntityID    string `gorm:"size:255;not null"`
+	Operation   string `gorm:"size:32;not null"`
+	Before      []byte
+	After       []byte
+	OccurredAt  time.Time  `gorm:"not null"`
+	PublishedAt *time.Time `gorm:"index"`
+	Attempts    int
+	LastError   string
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Migrate creates or updates the outbox table.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Message{})
+}
+
+// Write stores event in the outbox through db, which should be the
+// transaction of the change it describes.
+func Write(ctx context.Context, db *gorm.DB, event Event) error {
+	return db.WithContext(ctx).Create(&Message{
+		EventID:    event.ID,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Operation:  event.Operation,
+		Before:     event.Before,
+		After:      event.After,
+		OccurredAt: event.OccurredAt,
+	}).Error
+}
+
+func (m Message) Event() Event {
+	return Event{
+		ID:         m.EventID,
+		EntityType: m.EntityType,
+		EntityID:   m.EntityID,
+		Operation:  m.Operation,
+		Before:     m.Before,
+		After:      m.After,
+		OccurredAt: m.OccurredAt,
+	}
+}
@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alvarotor/entitier-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var ctx = context.Background()
+
+type failingPublisher struct {
+	err       error
+	published []Event
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("User", "7", OpUpdate, map[string]string{"email": "a"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, event.ID, 32)
+	assert.Equal(t, "User", event.EntityType)
+	assert.Equal(t, "7", event.EntityID)
+	assert.JSONEq(t, `{"email":"a"}`, string(event.Before))
+	assert.Nil(t, event.After)
+
+	other, err := NewEvent("User", "7", OpUpdate, nil, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, event.ID, other.ID)
+
+	encoded, err := json.Marshal(event)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), `"after"`)
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &Message{})
+	for _, id := range []string{"1", "2", "3"} {
+		event, err := NewEvent("User", id, OpCreate, nil, map[string]string{"id": id})
+		assert.NoError(t, err)
+		assert.NoError(t, Write(ctx, db, event))
+	}
+
+	publisher := NewChannelPublisher(10)
+	dispatcher := NewDispatcher(db, publisher, WithBatchSize(2))
+
+	n, err := dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		event := <-publisher.Events()
+		ids = append(ids, event.EntityID)
+		assert.JSONEq(t, `{"id":"`+event.EntityID+`"}`, string(event.After))
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+
+	var pending int64
+	db.Model(&Message{}).Where("published_at IS NULL").Count(&pending)
+	assert.Zero(t, pending)
+}
+
+func TestDispatcher_DispatchFailure(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &Message{})
+	for _, id := range []string{"1", "2"} {
+		event, err := NewEvent("User", id, OpDelete, map[string]string{"id": id}, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, Write(ctx, db, event))
+	}
+
+	broker := errors.New("broker unavailable")
+	publisher := &failingPublisher{err: broker}
+	dispatcher := NewDispatcher(db, publisher)
+
+	n, err := dispatcher.Dispatch(ctx)
+	assert.ErrorIs(t, err, broker)
+	assert.Zero(t, n)
+
+	var first Message
+	assert.NoError(t, db.Order("id").First(&first).Error)
+	assert.Equal(t, 1, first.Attempts)
+	assert.Equal(t, "broker unavailable", first.LastError)
+	assert.Nil(t, first.PublishedAt)
+
+	publisher.err = nil
+	n, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	if assert.Len(t, publisher.published, 2) {
+		assert.Equal(t, "1", publisher.published[0].EntityID)
+		assert.Equal(t, "2", publisher.published[1].EntityID)
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &Message{})
+	publisher := NewChannelPublisher(0)
+	log := &mocks.Logger{}
+	log.On("Error", "outbox", mock.Anything).Return().Maybe()
+	dispatcher := NewDispatcher(db, publisher, WithInterval(10*time.Millisecond), WithLogger(log))
+
+	event, err := NewEvent("User", "1", OpCreate, nil, map[string]string{"id": "1"})
+	assert.NoError(t, err)
+	assert.NoError(t, Write(ctx, db, event))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- dispatcher.Run(runCtx) }()
+
+	select {
+	case received := <-publisher.Events():
+		assert.Equal(t, event.ID, received.ID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not dispatched")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("dispatcher did not stop")
+	}
+}
+
+func TestChannelPublisher_Canceled(t *testing.T) {
+	publisher := NewChannelPublisher(0)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, publisher.Publish(canceled, Event{}), context.Canceled)
+}
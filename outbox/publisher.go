@@ -0,0 +1,34 @@
+package outbox
+
+import "context"
+
+// Publisher delivers events to other services, for example through a
+// message broker. Publish must return an error unless the event was
+// accepted, so that it is retried.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// ChannelPublisher publishes events to a Go channel, for tests and
+// in-process consumers.
+type ChannelPublisher struct {
+	events chan Event
+}
+
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{events: make(chan Event, buffer)}
+}
+
+// Publish blocks until the event is received or buffered, or ctx is done.
+func (p *ChannelPublisher) Publish(ctx context.Context, event Event) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *ChannelPublisher) Events() <-chan Event {
+	return p.events
+}
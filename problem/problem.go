@@ -0,0 +1,58 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of RFC 7807 problem details.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Code repeats the
+// models error code so clients can branch on it without parsing Detail.
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     string                 `json:"code"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// New describes err, mapped with models.AsError, as a problem document.
+func New(err error, instance string) Problem {
+	e := models.AsError(err)
+	title := http.StatusText(e.Status)
+	if title == "" {
+		title = e.Code
+	}
+	return Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   e.Status,
+		Detail:   e.Error(),
+		Instance: instance,
+		Code:     e.Code,
+		Details:  e.Details,
+	}
+}
+
+// Write answers the request with err as application/problem+json.
+func Write(c *gin.Context, err error) {
+	instance := ""
+	if c.Request != nil && c.Request.URL != nil {
+		instance = c.Request.URL.Path
+	}
+	p := New(err, instance)
+	c.Header("Content-Type", ContentType)
+	c.JSON(p.Status, p)
+}
+
+// Abort writes err like Write and stops the handler chain.
+func Abort(c *gin.Context, err error) {
+	Write(c, err)
+	c.Abort()
+}
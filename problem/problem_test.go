@@ -0,0 +1,69 @@
+package problem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"Not found", models.ErrNotFound, http.StatusNotFound, models.CodeNotFound},
+		{"GORM not found", gorm.ErrRecordNotFound, http.StatusNotFound, models.CodeNotFound},
+		{"Wrapped sentinel", fmt.Errorf("%w: age", models.ErrUnknownField), http.StatusBadRequest, models.CodeUnknownField},
+		{"GORM duplicated key", gorm.ErrDuplicatedKey, http.StatusConflict, models.CodeDuplicateKey},
+		{"Version conflict", models.ErrConflict, http.StatusConflict, models.CodeVersionConflict},
+		{"Canceled", fmt.Errorf("%w: %w", models.ErrRequestCanceled, context.Canceled), models.StatusClientClosedRequest, models.CodeRequestCanceled},
+		{"Deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, models.CodeDeadlineExceeded},
+		{"Typed", models.NewError("quota_exceeded", http.StatusTooManyRequests, errors.New("quota exceeded")), http.StatusTooManyRequests, "quota_exceeded"},
+		{"Unknown", errors.New("boom"), http.StatusInternalServerError, models.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(tt.err, "/users/1")
+
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, tt.err.Error(), p.Detail)
+			assert.Equal(t, "/users/1", p.Instance)
+			assert.Equal(t, "about:blank", p.Type)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/users/1?x=1", nil)
+
+	err := models.NewError(models.CodeDuplicateKey, http.StatusConflict, models.ErrDuplicatedKeyEmail).
+		WithDetail("fields", []string{"email"})
+	Abort(c, err)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type":"about:blank",
+		"title":"Conflict",
+		"status":409,
+		"detail":"duplicated key. Email already exists",
+		"instance":"/users/1",
+		"code":"duplicate_key",
+		"details":{"fields":["email"]}
+	}`, w.Body.String())
+}
@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"github.com/alvarotor/entitier-go/validation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const DefaultBatchSize = 100
+
+// CreateMany inserts items in batches of batchSize. When a batch fails it is
+// retried item by item, each in its own savepoint, so a single bad row only
+// fails itself. When the create hooks are registered every item is created
+// on its own, like Create, and batchSize is ignored. The returned error is
+// reserved for failures that stop the whole operation, such as a canceled
+// context.
+func (r *genericRepository[T, X]) CreateMany(ctx context.Context, items []T, batchSize int) ([]models.BatchResult[T], error) {
+	if r.perRow(BeforeCreate, AfterCreate) {
+		return r.createEach(ctx, items)
+	}
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	items = append([]T(nil), items...)
+	results := make([]models.BatchResult[T], len(items))
+	pending := make([]int, 0, len(items))
+	for i := range items {
+		results[i].Index = i
+		if err := r.prepareCreate(ctx, &items[i]); err != nil {
+			results[i].Item = items[i]
+			results[i].Err = err
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	uow := NewUnitOfWork(r.DB)
+	for start := 0; start < len(pending); start += batchSize {
+		end := min(start+batchSize, len(pending))
+		indexes := pending[start:end]
+
+		batch := make([]T, len(indexes))
+		for j, i := range indexes {
+			batch[j] = items[i]
+		}
+
+		err := uow.WithTx(ctx, func(ctx context.Context) error {
+			return r.scoped(ctx).Create(&batch).Error
+		})
+		if err == nil {
+			for j, i := range indexes {
+				results[i].Item = batch[j]
+			}
+			continue
+		}
+		if err = dbError(err); isContextError(err) {
+			return results, err
+		}
+
+		for _, i := range indexes {
+			item := items[i]
+			err := uow.WithTx(ctx, func(ctx context.Context) error {
+				return r.scoped(ctx).Create(&item).Error
+			})
+			if err != nil {
+				err = r.writeError(err)
+				if isContextError(err) {
+					return results, err
+				}
+			}
+			results[i].Item = item
+			results[i].Err = err
+		}
+	}
+
+	return results, nil
+}
+
+// createEach creates items one by one with Create, each in its own
+// transaction or savepoint.
+func (r *genericRepository[T, X]) createEach(ctx context.Context, items []T) ([]models.BatchResult[T], error) {
+	results := make([]models.BatchResult[T], len(items))
+	for i, item := range items {
+		created, err := r.Create(ctx, item)
+		results[i] = models.BatchResult[T]{Index: i, Item: created, Err: err}
+		if isContextError(err) {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// UpdateMany applies the non-zero fields of amended to the rows whose
+// primary key is in ids and which match opts. At least one of them must be
+// given. Versioned models get their version bumped. When the update hooks
+// are registered the rows are updated one by one in a transaction, and the
+// hooks of each get a copy of amended with its primary key, then the row as
+// stored.
+func (r *genericRepository[T, X]) UpdateMany(ctx context.Context, amended T, ids []X, opts ...models.QueryOption) (int64, error) {
+	if !r.perRow(BeforeUpdate, AfterUpdate) {
+		return r.updateMany(ctx, amended, ids, opts)
+	}
+
+	var updated int64
+	err := r.atomically(ctx, func(ctx context.Context) error {
+		query, err := r.batchScope(r.scoped(ctx), ids, opts)
+		if err != nil {
+			return err
+		}
+		updated, err = r.eachRow(ctx, query, func(row *T, byID clause.Eq) error {
+			before := *row
+			if err := r.updateRow(ctx, row, byID, amended); err != nil {
+				return err
+			}
+			return r.record(ctx, outbox.OpUpdate, &before, row)
+		})
+		return err
+	}, BeforeUpdate, AfterUpdate)
+	return updated, err
+}
+
+// updateRow applies amended to row, found by byID, running the update hooks
+// around the write and reloading row afterwards.
+func (r *genericRepository[T, X]) updateRow(ctx context.Context, row *T, byID clause.Eq, amended T) error {
+	pk, err := r.primaryField()
+	if err != nil {
+		return err
+	}
+	if err := pk.Set(ctx, reflect.ValueOf(&amended).Elem(), byID.Value); err != nil {
+		return err
+	}
+	if err := r.hooks.run(ctx, BeforeUpdate, &amended); err != nil {
+		return err
+	}
+	if err := r.stampTenant(ctx, &amended); err != nil {
+		return err
+	}
+	if err := r.validateUpdate(ctx, *row, &amended); err != nil {
+		return err
+	}
+	updates, err := r.batchUpdates(ctx, amended)
+	if err != nil {
+		return err
+	}
+
+	if err := r.scoped(ctx).Model(new(T)).Where(byID).Updates(updates).Error; err != nil {
+		return r.writeError(err)
+	}
+	if err := r.scoped(ctx).Where(byID).First(row).Error; err != nil {
+		return dbError(err)
+	}
+	return r.hooks.run(ctx, AfterUpdate, row)
+}
+
+func (r *genericRepository[T, X]) updateMany(ctx context.Context, amended T, ids []X, opts []models.QueryOption) (int64, error) {
+	if err := r.stampTenant(ctx, &amended); err != nil {
+		return 0, err
+	}
+	if err := r.validateFields(ctx, &amended); err != nil {
+		return 0, err
+	}
+	updates, err := r.batchUpdates(ctx, amended)
+	if err != nil {
+		return 0, err
+	}
+
+	query, err := r.batchScope(r.scoped(ctx), ids, opts)
+	if err != nil {
+		return 0, err
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return 0, r.writeError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// batchUpdates returns the assignments of UpdateMany: the non-zero columns
+// of amended and, for versioned models, the version bump. They go in one
+// statement, as a second one would miss rows whose filtered columns the
+// first one changed.
+func (r *genericRepository[T, X]) batchUpdates(ctx context.Context, amended T) (map[string]interface{}, error) {
+	fields, err := r.updateFields(ctx, &amended)
+	if err != nil {
+		return nil, err
+	}
+	vf, err := r.versionField()
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(&amended).Elem()
+	updates := make(map[string]interface{}, len(fields)+1)
+	for _, field := range fields {
+		updates[field.DBName], _ = field.ValueOf(ctx, rv)
+	}
+	if vf != nil {
+		column := clause.Column{Table: clause.CurrentTable, Name: vf.DBName}
+		updates[vf.DBName] = gorm.Expr("? + 1", column)
+	}
+	return updates, nil
+}
+
+// updateFields returns the fields of amended an update writes: the
+// updatable, non-zero ones other than the primary key and the version.
+func (r *genericRepository[T, X]) updateFields(ctx context.Context, amended *T) ([]*schema.Field, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	vf, err := r.versionField()
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(amended).Elem()
+	var fields []*schema.Field
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.PrimaryKey || field == vf || !field.Updatable {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, rv); !zero {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// validateUpdate validates existing as it will be stored once the fields
+// of amended an update writes are applied to it.
+func (r *genericRepository[T, X]) validateUpdate(ctx context.Context, existing T, amended *T) error {
+	fields, err := r.updateFields(ctx, amended)
+	if err != nil {
+		return err
+	}
+	merged := reflect.ValueOf(&existing).Elem()
+	source := reflect.ValueOf(amended).Elem()
+	for _, field := range fields {
+		field.ReflectValueOf(ctx, merged).Set(field.ReflectValueOf(ctx, source))
+	}
+	return validation.Struct(ctx, &existing)
+}
+
+// validateFields validates the fields of amended an update writes, for
+// updates that do not load the rows they change.
+func (r *genericRepository[T, X]) validateFields(ctx context.Context, amended *T) error {
+	fields, err := r.updateFields(ctx, amended)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return validation.Fields(ctx, amended, names...)
+}
+
+// DeleteMany deletes the rows whose primary key is in ids and which match
+// opts. At least one of them must be given. When the delete hooks are
+// registered the rows are deleted one by one in a transaction, and their
+// hooks get each row as stored before it was deleted.
+func (r *genericRepository[T, X]) DeleteMany(ctx context.Context, ids []X, permanently bool, opts ...models.QueryOption) (int64, error) {
+	unscoped := func(db *gorm.DB) *gorm.DB {
+		if permanently {
+			return db.Unscoped()
+		}
+		return db
+	}
+	if !r.perRow(BeforeDelete, AfterDelete) {
+		return r.deleteMany(unscoped(r.scoped(ctx)), ids, opts)
+	}
+
+	var deleted int64
+	err := r.atomically(ctx, func(ctx context.Context) error {
+		query, err := r.batchScope(unscoped(r.scoped(ctx)), ids, opts)
+		if err != nil {
+			return err
+		}
+		deleted, err = r.eachRow(ctx, query, func(row *T, byID clause.Eq) error {
+			if err := r.hooks.run(ctx, BeforeDelete, row); err != nil {
+				return err
+			}
+			if err := unscoped(r.scoped(ctx)).Where(byID).Delete(new(T)).Error; err != nil {
+				return dbError(err)
+			}
+			if err := r.hooks.run(ctx, AfterDelete, row); err != nil {
+				return err
+			}
+			return r.record(ctx, outbox.OpDelete, row, nil)
+		})
+		return err
+	}, BeforeDelete, AfterDelete)
+	return deleted, err
+}
+
+func (r *genericRepository[T, X]) deleteMany(db *gorm.DB, ids []X, opts []models.QueryOption) (int64, error) {
+	query, err := r.batchScope(db, ids, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	result := query.Delete(new(T))
+	if result.Error != nil {
+		return 0, dbError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// perRow reports whether the batch writes must go row by row, because
+// changes are tracked or some of events have hooks.
+func (r *genericRepository[T, X]) perRow(events ...HookEvent) bool {
+	return r.tracked() || r.hooks.has(events...)
+}
+
+// eachRow loads the rows of query and calls fn with each of them and the
+// condition that finds it again. It returns how many rows there were.
+func (r *genericRepository[T, X]) eachRow(ctx context.Context, query *gorm.DB, fn func(row *T, byID clause.Eq) error) (int64, error) {
+	pk, err := r.primaryField()
+	if err != nil {
+		return 0, err
+	}
+
+	var rows []*T
+	if err := query.Order(primaryKeyOrder).Find(&rows).Error; err != nil {
+		return 0, dbError(err)
+	}
+	for _, row := range rows {
+		id, _ := pk.ValueOf(ctx, reflect.ValueOf(row).Elem())
+		byID := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}
+		if err := fn(row, byID); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(rows)), nil
+}
+
+// batchScope restricts query to ids and opts, refusing to build an
+// unconditional statement that would touch every row of the table.
+func (r *genericRepository[T, X]) batchScope(query *gorm.DB, ids []X, opts []models.QueryOption) (*gorm.DB, error) {
+	if len(ids) == 0 && len(opts) == 0 {
+		return nil, models.ErrMissingCondition
+	}
+	if hasSort(opts) {
+		return nil, models.ErrInvalidSort
+	}
+
+	query = query.Model(new(T))
+	if len(ids) > 0 {
+		pk, err := r.primaryField()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(ids))
+		for i, id := range ids {
+			values[i] = id
+		}
+		query = query.Where(clause.IN{
+			Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName},
+			Values: values,
+		})
+	}
+
+	return r.applyOptions(query, opts)
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarotor/entitier-go/cache"
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/tenancy"
+	"golang.org/x/sync/singleflight"
+)
+
+type cacheConfig struct {
+	ttl     time.Duration
+	tenancy bool
+}
+
+type CacheOption func(*cacheConfig)
+
+// WithCacheTTL expires cached entities after ttl. Without it they stay
+// until invalidated or evicted by the backend.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(cfg *cacheConfig) {
+		cfg.ttl = ttl
+	}
+}
+
+// WithCacheTenancy keeps cached entities per tenant of the context, for
+// decorated repositories that scope by tenant but are not from this
+// package. Repositories created WithTenancy need no option.
+func WithCacheTenancy() CacheOption {
+	return func(cfg *cacheConfig) {
+		cfg.tenancy = true
+	}
+}
+
+type cachedRepository[T any, X string | uint] struct {
+	IGenericRepo[T, X]
+	backend cache.Backend[T]
+	cfg     cacheConfig
+	loads   singleflight.Group
+
+	// mu is held for writing while invalidating and for reading while
+	// storing, so a load that raced a write is never stored after the
+	// write invalidated it.
+	mu       sync.RWMutex
+	epoch    uint64
+	preloads sync.Map
+}
+
+// NewCachedRepository decorates repo with a read-through cache of Get,
+// keyed by ID and preload and, when repo scopes by tenant, by the tenant
+// of the context. Concurrent misses of the same key share a single load. Update, UpdateField, Patch, Delete, Restore, Revert, Upsert and the
+// batch writes invalidate what they change; UpdateMany and DeleteMany
+// without ids clear the backend, which should therefore not be shared with
+// other repositories.
+//
+// Reads inside a transaction bypass the cache, and writes inside one
+// invalidate it once the transaction opened by UnitOfWork.WithTx commits.
+// Cache hits do not run the
+// AfterGet hooks of repo, and entities with preloaded associations are not
+// invalidated when those associations change, short of a TTL.
+func NewCachedRepository[T any, X string | uint](repo IGenericRepo[T, X], backend cache.Backend[T], opts ...CacheOption) IGenericRepo[T, X] {
+	var cfg cacheConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if scoped, ok := repo.(tenantScoped); ok && scoped.scopedByTenant() {
+		cfg.tenancy = true
+	}
+	return &cachedRepository[T, X]{
+		IGenericRepo: repo,
+		backend:      backend,
+		cfg:          cfg,
+	}
+}
+
+// key keeps entities per tenant when the repository scopes by tenant, so
+// that one tenant never reads what another cached. Otherwise every tenant
+// shares one entry, which a write from any of them invalidates.
+func (c *cachedRepository[T, X]) key(ctx context.Context, id string, preload string) string {
+	var tenant string
+	if c.cfg.tenancy {
+		tenant, _ = tenancy.TenantFrom(ctx)
+	}
+	return strings.Join([]string{tenant, id, preload}, "\x1f")
+}
+
+func (c *cachedRepository[T, X]) scopedByTenant() bool {
+	return c.cfg.tenancy
+}
+
+// Get returns a copy of the cached entity. Nested pointers, slices and maps
+// are shared with the cache and must not be modified.
+func (c *cachedRepository[T, X]) Get(ctx context.Context, id X, preload string) (*T, error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return c.IGenericRepo.Get(ctx, id, preload)
+	}
+	key := c.key(ctx, fmt.Sprint(id), preload)
+	// A failing backend degrades to reading through.
+	if model, ok, err := c.backend.Get(ctx, key); err == nil && ok {
+		return &model, nil
+	}
+	c.preloads.Store(preload, struct{}{})
+
+	c.mu.RLock()
+	epoch := c.epoch
+	c.mu.RUnlock()
+
+	// The load is shared by every caller waiting on it, so one of them
+	// giving up must not cancel it for the others. Callers arriving after
+	// an invalidation start a new one.
+	loaded := c.loads.DoChan(strconv.FormatUint(epoch, 10)+"\x1f"+key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		model, err := c.IGenericRepo.Get(loadCtx, id, preload)
+		if err != nil {
+			return nil, err
+		}
+		c.store(loadCtx, key, *model, epoch)
+		return *model, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, dbError(ctx.Err())
+	case result := <-loaded:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		model := result.Val.(T)
+		return &model, nil
+	}
+}
+
+func (c *cachedRepository[T, X]) store(ctx context.Context, key string, model T, epoch uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.epoch != epoch {
+		return
+	}
+	_ = c.backend.Set(ctx, key, model, c.cfg.ttl)
+}
+
+// invalidate drops every cached variant of ids. The error of the write it
+// follows takes precedence over its own.
+func (c *cachedRepository[T, X]) invalidate(ctx context.Context, err error, ids ...string) error {
+	return c.afterCommit(ctx, err, func(ctx context.Context) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.epoch++
+
+		var keys []string
+		c.preloads.Range(func(preload, _ any) bool {
+			for _, id := range ids {
+				keys = append(keys, c.key(ctx, id, preload.(string)))
+			}
+			return true
+		})
+		if len(keys) == 0 {
+			return nil
+		}
+		return c.backend.Delete(ctx, keys...)
+	})
+}
+
+func (c *cachedRepository[T, X]) invalidateAll(ctx context.Context, err error) error {
+	return c.afterCommit(ctx, err, func(ctx context.Context) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.epoch++
+		return c.backend.Clear(ctx)
+	})
+}
+
+// afterCommit runs drop after a write, or once the transaction the write
+// joined commits, as a Get in between would cache the row as it was
+// before. The error of a deferred drop is lost.
+func (c *cachedRepository[T, X]) afterCommit(ctx context.Context, err error, drop func(ctx context.Context) error) error {
+	ctx = context.WithoutCancel(ctx)
+	if _, ok := TxFromContext(ctx); ok {
+		AfterCommit(ctx, func() {
+			_ = drop(ctx)
+		})
+		return err
+	}
+	if derr := drop(ctx); err == nil {
+		err = derr
+	}
+	return err
+}
+
+func (c *cachedRepository[T, X]) Update(ctx context.Context, id X, amended T) error {
+	err := c.IGenericRepo.Update(ctx, id, amended)
+	return c.invalidate(ctx, err, fmt.Sprint(id))
+}
+
+func (c *cachedRepository[T, X]) UpdateField(ctx context.Context, id X, field string, amended interface{}) error {
+	err := c.IGenericRepo.UpdateField(ctx, id, field, amended)
+	return c.invalidate(ctx, err, fmt.Sprint(id))
+}
+
+func (c *cachedRepository[T, X]) Delete(ctx context.Context, id X, permanently bool) error {
+	err := c.IGenericRepo.Delete(ctx, id, permanently)
+	return c.invalidate(ctx, err, fmt.Sprint(id))
+}
+
+func (c *cachedRepository[T, X]) Restore(ctx context.Context, id X) error {
+	err := c.IGenericRepo.Restore(ctx, id)
+	return c.invalidate(ctx, err, fmt.Sprint(id))
+}
+
+func (c *cachedRepository[T, X]) Patch(ctx context.Context, id X, patch models.Patch) (*T, error) {
+	model, err := c.IGenericRepo.Patch(ctx, id, patch)
+	return model, c.invalidate(ctx, err, fmt.Sprint(id))
+}
+
+func (c *cachedRepository[T, X]) Revert(ctx context.Context, id X, version uint64) (*T, error) {
+	model, err := c.IGenericRepo.Revert(ctx, id, version)
+	return model, c.invalidate(ctx, err, fmt.Sprint(id))
+}
+
+func (c *cachedRepository[T, X]) Upsert(ctx context.Context, model T, conflictColumns []string, updateColumns []string) (T, error) {
+	model, err := c.IGenericRepo.Upsert(ctx, model, conflictColumns, updateColumns)
+	if pk, ok := primaryKeyOf(&model); ok {
+		return model, c.invalidate(ctx, err, pk)
+	}
+	return model, err
+}
+
+func (c *cachedRepository[T, X]) UpdateMany(ctx context.Context, amended T, ids []X, opts ...models.QueryOption) (int64, error) {
+	affected, err := c.IGenericRepo.UpdateMany(ctx, amended, ids, opts...)
+	if len(ids) == 0 {
+		return affected, c.invalidateAll(ctx, err)
+	}
+	return affected, c.invalidate(ctx, err, idStrings(ids)...)
+}
+
+func (c *cachedRepository[T, X]) DeleteMany(ctx context.Context, ids []X, permanently bool, opts ...models.QueryOption) (int64, error) {
+	affected, err := c.IGenericRepo.DeleteMany(ctx, ids, permanently, opts...)
+	if len(ids) == 0 {
+		return affected, c.invalidateAll(ctx, err)
+	}
+	return affected, c.invalidate(ctx, err, idStrings(ids)...)
+}
+
+func idStrings[X string | uint](ids []X) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = fmt.Sprint(id)
+	}
+	return values
+}
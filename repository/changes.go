@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+)
+
+// tracked reports whether writes record their changes, in the outbox, the
+// audit trail or the version history, and so need the entity as stored
+// around them.
+func (r *genericRepository[T, X]) tracked() bool {
+	return r.outbox || r.audit || r.versioning
+}
+
+// snapshot loads the entity before a change when changes are tracked.
+func (r *genericRepository[T, X]) snapshot(ctx context.Context, id X) (*T, error) {
+	if !r.tracked() {
+		return nil, nil
+	}
+	return r.get(ctx, id, "")
+}
+
+// record writes the outbox message, the audit entry and the snapshot of the
+// version before a change, in the transaction of ctx, for whichever is
+// enabled. Updates that left the entity as it was are not recorded.
+func (r *genericRepository[T, X]) record(ctx context.Context, op string, before *T, after *T) error {
+	if !r.tracked() {
+		return nil
+	}
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	pk, err := r.primaryField()
+	if err != nil {
+		return err
+	}
+
+	var beforeValue, afterValue interface{}
+	entity := after
+	if before != nil {
+		beforeValue = before
+		entity = before
+	}
+	if after != nil {
+		afterValue = after
+		entity = after
+	}
+	id, _ := pk.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+
+	event, err := outbox.NewEvent(s.Name, fmt.Sprint(id), op, beforeValue, afterValue)
+	if err != nil {
+		return err
+	}
+	if before != nil && after != nil && bytes.Equal(event.Before, event.After) {
+		return nil
+	}
+
+	db := r.conn(ctx)
+	if r.outbox {
+		if err := outbox.Write(ctx, db, event); err != nil {
+			return dbError(err)
+		}
+	}
+	if r.audit {
+		if err := audit.Record(ctx, db, event.EntityType, event.EntityID, op, event.Before, event.After); err != nil {
+			return dbError(err)
+		}
+	}
+	if r.versioning && before != nil {
+		if _, err := history.Write(ctx, db, event.EntityType, event.EntityID, op, event.Before); err != nil {
+			// Another write superseded the same version concurrently.
+			if _, ok := parseDuplicateKey(err); ok {
+				return models.ErrConflict
+			}
+			return dbError(err)
+		}
+	}
+	return nil
+}
+
+// History returns the audit entries of the entity with the given id, oldest
+// first. It needs WithAudit and keeps working after the entity is soft
+// deleted, and after it is removed permanently unless WithTenancy is used,
+// as entries do not record their tenant.
+func (r *genericRepository[T, X]) History(ctx context.Context, id X) ([]audit.Entry, error) {
+	if !r.audit {
+		return nil, models.ErrAuditNotEnabled
+	}
+	if r.tenancy {
+		_, err := r.find(r.scoped(ctx).Unscoped(), id)
+		if errors.Is(err, models.ErrNotFound) {
+			return []audit.Entry{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := audit.Find(ctx, r.conn(ctx), audit.Query{EntityType: s.Name, EntityID: fmt.Sprint(id)})
+	if err != nil {
+		return nil, dbError(err)
+	}
+	return entries, nil
+}
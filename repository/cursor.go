@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type keysetCursor[X string | uint] struct {
+	ID     X               `json:"id"`
+	Column string          `json:"col,omitempty"`
+	Value  json.RawMessage `json:"val,omitempty"`
+}
+
+func encodeCursor[X string | uint](cur keysetCursor[X]) (string, error) {
+	raw, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor[X string | uint](token string) (keysetCursor[X], error) {
+	var cur keysetCursor[X]
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cur, models.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return cur, models.ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+func (r *genericRepository[T, X]) GetAllCursor(ctx context.Context, cursor string, limit int, sortBy string, opts ...models.QueryOption) ([]*T, string, error) {
+	items := []*T{}
+	if limit < 1 {
+		return items, "", models.ErrInvalidPagination
+	}
+	if hasSort(opts) {
+		return items, "", fmt.Errorf("%w: cursor pagination is ordered by its sort column", models.ErrInvalidSort)
+	}
+
+	pk, err := r.primaryField()
+	if err != nil {
+		return items, "", err
+	}
+
+	desc := strings.HasPrefix(sortBy, "-")
+	sortBy = strings.TrimPrefix(sortBy, "-")
+	var sortField *schema.Field
+	if sortBy != "" {
+		sortField, err = r.lookupColumn(sortBy)
+		if err != nil {
+			return items, "", err
+		}
+		if sortField == pk {
+			sortField = nil
+		}
+	}
+
+	query, err := r.applyOptions(r.scoped(ctx), opts)
+	if err != nil {
+		return items, "", err
+	}
+	if sortField != nil {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: sortField.DBName}, Desc: desc})
+	}
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Desc: desc})
+
+	if cursor != "" {
+		cur, err := decodeCursor[X](cursor)
+		if err != nil {
+			return items, "", err
+		}
+		query, err = seekAfter(query, cur, pk, sortField, desc)
+		if err != nil {
+			return items, "", err
+		}
+	}
+
+	result := query.Limit(limit + 1).Find(&items)
+	if result.Error != nil {
+		return items, "", dbError(result.Error)
+	}
+	if len(items) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+	next, err := cursorFor[T, X](ctx, items[limit-1], pk, sortField)
+	if err != nil {
+		return items, "", err
+	}
+
+	return items, next, nil
+}
+
+func seekAfter[X string | uint](query *gorm.DB, cur keysetCursor[X], pk *schema.Field, sortField *schema.Field, desc bool) (*gorm.DB, error) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	pkCol := clause.Column{Table: clause.CurrentTable, Name: pk.DBName}
+
+	if sortField == nil {
+		if cur.Column != "" {
+			return nil, models.ErrInvalidCursor
+		}
+		return query.Where(clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{pkCol, cur.ID}}), nil
+	}
+
+	if cur.Column != sortField.DBName || len(cur.Value) == 0 {
+		return nil, models.ErrInvalidCursor
+	}
+	value := reflect.New(sortField.FieldType)
+	if err := json.Unmarshal(cur.Value, value.Interface()); err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	sortCol := clause.Column{Table: clause.CurrentTable, Name: sortField.DBName}
+
+	return query.Where(clause.Expr{
+		SQL:  "(? " + op + " ? OR (? = ? AND ? " + op + " ?))",
+		Vars: []interface{}{sortCol, value.Elem().Interface(), sortCol, value.Elem().Interface(), pkCol, cur.ID},
+	}), nil
+}
+
+func cursorFor[T any, X string | uint](ctx context.Context, item *T, pk *schema.Field, sortField *schema.Field) (string, error) {
+	rv := reflect.ValueOf(item).Elem()
+
+	id, _ := pk.ValueOf(ctx, rv)
+	cur := keysetCursor[X]{}
+	if typed, ok := id.(X); ok {
+		cur.ID = typed
+	} else {
+		return "", models.ErrIDTypeMismatch
+	}
+
+	if sortField != nil {
+		v, _ := sortField.ValueOf(ctx, rv)
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		cur.Column = sortField.DBName
+		cur.Value = raw
+	}
+
+	return encodeCursor(cur)
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm"
+)
+
+var (
+	sqliteUniquePattern    = regexp.MustCompile(`UNIQUE constraint failed: (.+)$`)
+	postgresUniquePattern  = regexp.MustCompile(`duplicate key value violates unique constraint "([^"]+)"`)
+	postgresKeyPattern     = regexp.MustCompile(`Key \(([^)]+)\)=`)
+	mysqlUniquePattern     = regexp.MustCompile(`Duplicate entry '.*' for key '([^']+)'`)
+	sqlServerUniquePattern = regexp.MustCompile(`(?:unique index|UNIQUE KEY constraint|PRIMARY KEY constraint) '([^']+)'`)
+)
+
+// writeError translates errors of statements that write rows, turning
+// unique violations into a *models.DuplicateKeyError naming the fields of T
+// involved.
+func (r *genericRepository[T, X]) writeError(err error) error {
+	dup, ok := parseDuplicateKey(err)
+	if !ok {
+		return dbError(err)
+	}
+	r.resolveDuplicateKey(dup)
+	return dup
+}
+
+// parseDuplicateKey recognises unique violations, either translated by
+// GORM or reported raw by the sqlite, postgres, mysql or sqlserver drivers,
+// and extracts the constraint and columns their message names.
+func parseDuplicateKey(err error) (*models.DuplicateKeyError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var dup *models.DuplicateKeyError
+	if errors.As(err, &dup) {
+		return dup, true
+	}
+
+	dup = &models.DuplicateKeyError{Err: err}
+	msg := err.Error()
+	if m := sqliteUniquePattern.FindStringSubmatch(msg); m != nil {
+		for _, column := range strings.Split(m[1], ",") {
+			column = strings.TrimSpace(column)
+			dup.Columns = append(dup.Columns, column[strings.LastIndex(column, ".")+1:])
+		}
+		return dup, true
+	}
+	if m := postgresUniquePattern.FindStringSubmatch(msg); m != nil {
+		dup.Constraint = m[1]
+		if key := postgresKeyPattern.FindStringSubmatch(msg); key != nil {
+			for _, column := range strings.Split(key[1], ",") {
+				dup.Columns = append(dup.Columns, strings.Trim(strings.TrimSpace(column), `"`))
+			}
+		}
+		return dup, true
+	}
+	if m := mysqlUniquePattern.FindStringSubmatch(msg); m != nil {
+		dup.Constraint = m[1][strings.LastIndex(m[1], ".")+1:]
+		return dup, true
+	}
+	if m := sqlServerUniquePattern.FindStringSubmatch(msg); m != nil {
+		dup.Constraint = m[1]
+		return dup, true
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return dup, true
+	}
+	return nil, false
+}
+
+// resolveDuplicateKey fills the columns of dup from the unique constraints
+// and indexes of T when the database only named the constraint, and maps
+// the columns to JSON member names.
+func (r *genericRepository[T, X]) resolveDuplicateKey(dup *models.DuplicateKeyError) {
+	s, err := r.schema()
+	if err != nil {
+		return
+	}
+
+	if len(dup.Columns) == 0 && dup.Constraint != "" {
+		if unique, ok := s.ParseUniqueConstraints()[dup.Constraint]; ok {
+			dup.Columns = []string{unique.Field.DBName}
+		} else if index, ok := s.ParseIndexes()[dup.Constraint]; ok {
+			for _, option := range index.Fields {
+				if option.Field != nil {
+					dup.Columns = append(dup.Columns, option.DBName)
+				}
+			}
+		}
+	}
+
+	fields, err := r.jsonFields()
+	if err != nil || len(dup.Columns) == 0 {
+		return
+	}
+	names := make(map[string]string, len(fields))
+	for name, field := range fields {
+		names[field.DBName] = name
+	}
+	dup.Fields = make([]string, len(dup.Columns))
+	for i, column := range dup.Columns {
+		if name, ok := names[column]; ok {
+			dup.Fields[i] = name
+		} else {
+			dup.Fields[i] = column
+		}
+	}
+}
+
+// dbError translates context errors surfaced by the driver into the
+// models sentinels, keeping the original error matchable.
+func dbError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled) && !errors.Is(err, models.ErrRequestCanceled):
+		return fmt.Errorf("%w: %w", models.ErrRequestCanceled, err)
+	case errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, models.ErrDeadlineExceeded):
+		return fmt.Errorf("%w: %w", models.ErrDeadlineExceeded, err)
+	}
+	return err
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, models.ErrRequestCanceled) || errors.Is(err, models.ErrDeadlineExceeded)
+}
@@ -3,45 +3,133 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"github.com/alvarotor/entitier-go/validation"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var primaryKeyOrder = clause.OrderByColumn{
+	Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
+}
+
 type genericRepository[T any, X string | uint] struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	hooks      *Hooks[T]
+	outbox     bool
+	audit      bool
+	versioning bool
+	tenancy    bool
 }
 
-func NewGenericRepository[T any, X string | uint](db *gorm.DB) IGenericRepo[T, X] {
+func NewGenericRepository[T any, X string | uint](db *gorm.DB, opts ...Option) IGenericRepo[T, X] {
+	cfg := newConfig(opts...)
 	return &genericRepository[T, X]{
-		DB: db,
+		DB:         db,
+		hooks:      hooksFor[T](cfg),
+		outbox:     cfg.outbox,
+		audit:      cfg.audit,
+		versioning: cfg.versioning,
+		tenancy:    cfg.tenancy,
 	}
 }
 
+// atomically runs fn in a transaction when any of events has hooks or
+// changes are tracked, so that the hooks, the outbox message, the audit
+// entry, the snapshot and the write commit or roll back together.
+func (r *genericRepository[T, X]) atomically(ctx context.Context, fn func(ctx context.Context) error, events ...HookEvent) error {
+	if !r.tracked() && !r.hooks.has(events...) {
+		return fn(ctx)
+	}
+	return NewUnitOfWork(r.DB).WithTx(ctx, fn)
+}
+
+// conn returns the database handle for a call: the transaction carried by
+// ctx when there is one, otherwise the repository's own connection, bound
+// to ctx so cancellation and deadlines reach the driver. Queries on the
+// table of T go through scoped instead.
+func (r *genericRepository[T, X]) conn(ctx context.Context) *gorm.DB {
+	db := r.DB
+	if tx, ok := TxFromContext(ctx); ok {
+		db = tx
+	}
+	return db.WithContext(ctx)
+}
+
 func (r *genericRepository[T, X]) Create(ctx context.Context, model T) (T, error) {
-	// Use reflection to check if model is empty
-	if reflect.DeepEqual(model, reflect.Zero(reflect.TypeOf(model)).Interface()) {
-		return model, models.ErrModelCannotBeEmpty
+	created := model
+	err := r.atomically(ctx, func(ctx context.Context) error {
+		if err := r.hooks.run(ctx, BeforeCreate, &created); err != nil {
+			return err
+		}
+		var err error
+		if created, err = r.create(ctx, created); err != nil {
+			return err
+		}
+		if err := r.hooks.run(ctx, AfterCreate, &created); err != nil {
+			return err
+		}
+		return r.record(ctx, outbox.OpCreate, nil, &created)
+	}, BeforeCreate, AfterCreate)
+	return created, err
+}
+
+func (r *genericRepository[T, X]) create(ctx context.Context, model T) (T, error) {
+	if err := r.prepareCreate(ctx, &model); err != nil {
+		return model, err
 	}
 
-	result := r.DB.Create(&model)
+	result := r.scoped(ctx).Create(&model)
 
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
-			return model, models.ErrDuplicatedKeyEmail
-		}
-		return model, result.Error
+		return model, r.writeError(result.Error)
 	}
 
 	return model, nil
 }
 
-func (r genericRepository[T, X]) GetAll(ctx context.Context) ([]*T, error) {
+func (r *genericRepository[T, X]) prepareCreate(ctx context.Context, model *T) error {
+	// Use reflection to check if model is empty
+	if reflect.DeepEqual(*model, reflect.Zero(reflect.TypeOf(*model)).Interface()) {
+		return models.ErrModelCannotBeEmpty
+	}
+	if err := r.stampTenant(ctx, model); err != nil {
+		return err
+	}
+	if err := validation.Struct(ctx, model); err != nil {
+		return err
+	}
+
+	vf, err := r.versionField()
+	if err != nil {
+		return err
+	}
+	if vf != nil {
+		rv := reflect.ValueOf(model).Elem()
+		if _, zero := vf.ValueOf(ctx, rv); zero {
+			if err := vf.Set(ctx, rv, 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *genericRepository[T, X]) GetAll(ctx context.Context, opts ...models.QueryOption) ([]*T, error) {
 	var items []*T
-	result := r.DB.Find(&items)
+	query, err := r.applyOptions(r.scoped(ctx), opts)
+	if err != nil {
+		return items, err
+	}
+
+	result := query.Find(&items)
 	if result.Error != nil {
-		return items, result.Error
+		return items, dbError(result.Error)
 	}
 	if len(items) == 0 {
 		return items, models.ErrNotFound
@@ -50,13 +138,58 @@ func (r genericRepository[T, X]) GetAll(ctx context.Context) ([]*T, error) {
 	return items, nil
 }
 
+func (r *genericRepository[T, X]) GetAllPaged(ctx context.Context, page int, pageSize int, opts ...models.QueryOption) ([]*T, int64, error) {
+	items := []*T{}
+	if page < 1 || pageSize < 1 {
+		return items, 0, models.ErrInvalidPagination
+	}
+
+	query, err := r.applyOptions(r.scoped(ctx), opts)
+	if err != nil {
+		return items, 0, err
+	}
+
+	var total int64
+	result := query.Model(new(T)).Count(&total)
+	if result.Error != nil {
+		return items, 0, dbError(result.Error)
+	}
+	if total == 0 {
+		return items, 0, nil
+	}
+
+	if !hasSort(opts) {
+		query = query.Order(primaryKeyOrder)
+	}
+	result = query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&items)
+	if result.Error != nil {
+		return items, total, dbError(result.Error)
+	}
+
+	return items, total, nil
+}
+
 func (r *genericRepository[T, X]) Get(ctx context.Context, id X, preload string) (*T, error) {
-	var model = new(T)
-	result := r.DB
+	model, err := r.get(ctx, id, preload)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.hooks.run(ctx, AfterGet, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func (r *genericRepository[T, X]) get(ctx context.Context, id X, preload string) (*T, error) {
+	result := r.scoped(ctx)
 	if len(preload) > 0 {
 		result = result.Preload(preload)
 	}
+	return r.find(result, id)
+}
 
+func (r *genericRepository[T, X]) find(result *gorm.DB, id X) (*T, error) {
+	var model = new(T)
 	if _, ok := any(id).(string); ok {
 		result = result.Where("id = ?", id).First(model)
 	} else {
@@ -67,61 +200,184 @@ func (r *genericRepository[T, X]) Get(ctx context.Context, id X, preload string)
 		return nil, models.ErrNotFound
 	}
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, dbError(result.Error)
 	}
 
 	return model, nil
 }
 
 func (r *genericRepository[T, X]) Update(ctx context.Context, id X, amended T) error {
+	return r.atomically(ctx, func(ctx context.Context) error {
+		before, err := r.snapshot(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.hooks.run(ctx, BeforeUpdate, &amended); err != nil {
+			return err
+		}
+		if err := r.update(ctx, id, amended); err != nil {
+			return err
+		}
+		return r.afterWrite(ctx, AfterUpdate, outbox.OpUpdate, id, before)
+	}, BeforeUpdate, AfterUpdate)
+}
+
+func (r *genericRepository[T, X]) update(ctx context.Context, id X, amended T) error {
+	if err := r.stampTenant(ctx, &amended); err != nil {
+		return err
+	}
+
 	var existing T
-	result := r.DB.First(&existing, "ID = ?", id)
+	db := r.scoped(ctx)
+	result := db.First(&existing, "ID = ?", id)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return models.ErrNotFound
 	}
 	if result.Error != nil {
-		return result.Error
+		return dbError(result.Error)
+	}
+	if err := r.validateUpdate(ctx, existing, &amended); err != nil {
+		return err
 	}
 
-	result = r.DB.Model(&existing).Updates(amended)
-	if result.RowsAffected == 0 {
-		return models.ErrNotFound
+	vf, err := r.versionField()
+	if err != nil {
+		return err
 	}
+	query := db.Model(&existing)
+	if vf != nil {
+		rv := reflect.ValueOf(&amended).Elem()
+		version := versionCheck(ctx, vf, rv, reflect.ValueOf(&existing).Elem())
+		if err := vf.Set(ctx, rv, version+1); err != nil {
+			return err
+		}
+		query = whereVersion(query, vf, version)
+	}
+
+	result = query.Updates(amended)
 	if result.Error != nil {
-		return result.Error
+		return r.writeError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if vf != nil {
+			return models.ErrConflict
+		}
+		return models.ErrNotFound
 	}
 
 	return nil
 }
 
+// UpdateField sets one column of the entity. BeforeUpdateField hooks get
+// the entity as stored before the change.
 func (r *genericRepository[T, X]) UpdateField(ctx context.Context, id X, field string, amended interface{}) error {
+	return r.atomically(ctx, func(ctx context.Context) error {
+		before, err := r.snapshot(ctx, id)
+		if err != nil {
+			return err
+		}
+		if r.hooks.has(BeforeUpdateField) {
+			existing, err := r.get(ctx, id, "")
+			if err != nil {
+				return err
+			}
+			if err := r.hooks.run(ctx, BeforeUpdateField, existing); err != nil {
+				return err
+			}
+		}
+		if err := r.updateField(ctx, id, field, amended); err != nil {
+			return err
+		}
+		return r.afterWrite(ctx, AfterUpdateField, outbox.OpUpdateField, id, before)
+	}, BeforeUpdateField, AfterUpdateField)
+}
+
+func (r *genericRepository[T, X]) updateField(ctx context.Context, id X, field string, amended interface{}) error {
+	if r.isTenantField(field) {
+		return fmt.Errorf("%w: %s cannot be updated", models.ErrUnknownField, field)
+	}
+
 	var existing T
-	result := r.DB.First(&existing, "ID = ?", id)
+	db := r.scoped(ctx)
+	result := db.First(&existing, "ID = ?", id)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return models.ErrNotFound
 	}
 	if result.Error != nil {
-		return result.Error
+		return dbError(result.Error)
 	}
 
-	result = r.DB.Model(&existing).Update(field, amended)
-	if result.RowsAffected == 0 {
-		return models.ErrNotFound
+	vf, err := r.versionField()
+	if err != nil {
+		return err
+	}
+	if vf != nil {
+		version := versionCheck(ctx, vf, reflect.Value{}, reflect.ValueOf(&existing).Elem())
+		result = whereVersion(db.Model(&existing), vf, version).Updates(map[string]interface{}{
+			field:     amended,
+			vf.DBName: version + 1,
+		})
+	} else {
+		result = db.Model(&existing).Update(field, amended)
 	}
 	if result.Error != nil {
-		return result.Error
+		return r.writeError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if vf != nil {
+			return models.ErrConflict
+		}
+		return models.ErrNotFound
 	}
 
 	return nil
 }
 
+// Delete removes the entity. Its hooks get the entity as stored before it
+// was deleted.
 func (r *genericRepository[T, X]) Delete(ctx context.Context, id X, permanently bool) error {
+	return r.atomically(ctx, func(ctx context.Context) error {
+		var existing *T
+		if r.tracked() || r.hooks.has(BeforeDelete, AfterDelete) {
+			db := r.scoped(ctx)
+			if permanently {
+				db = db.Unscoped()
+			}
+			var err error
+			if existing, err = r.find(db, id); err != nil {
+				return err
+			}
+		}
+		if err := r.hooks.run(ctx, BeforeDelete, existing); err != nil {
+			return err
+		}
+		if err := r.delete(ctx, id, permanently); err != nil {
+			return err
+		}
+		if err := r.hooks.run(ctx, AfterDelete, existing); err != nil {
+			return err
+		}
+		return r.record(ctx, outbox.OpDelete, existing, nil)
+	}, BeforeDelete, AfterDelete)
+}
+
+func (r *genericRepository[T, X]) delete(ctx context.Context, id X, permanently bool) error {
 	t := new(T)
 	var deleter *gorm.DB
 	if permanently {
-		deleter = r.DB.Unscoped()
+		deleter = r.scoped(ctx).Unscoped()
 	} else {
-		deleter = r.DB
+		deleter = r.scoped(ctx)
+	}
+
+	vf, err := r.versionField()
+	if err != nil {
+		return err
+	}
+	version, checkVersion := ExpectedVersion(ctx)
+	checkVersion = checkVersion && vf != nil
+	if checkVersion {
+		deleter = whereVersion(deleter, vf, version)
 	}
 
 	if _, ok := any(id).(string); ok {
@@ -134,12 +390,33 @@ func (r *genericRepository[T, X]) Delete(ctx context.Context, id X, permanently
 		if errors.Is(deleter.Error, gorm.ErrRecordNotFound) {
 			return models.ErrNotFound
 		}
-		return deleter.Error
+		return dbError(deleter.Error)
 	}
 
 	if deleter.RowsAffected == 0 {
+		if checkVersion {
+			if _, err := r.get(ctx, id, ""); err == nil {
+				return models.ErrConflict
+			}
+		}
 		return models.ErrNotFound
 	}
 
 	return nil
 }
+
+// afterWrite reloads the entity after an update, when the hooks of event
+// or change tracking need it, runs the hooks and records the change.
+func (r *genericRepository[T, X]) afterWrite(ctx context.Context, event HookEvent, op string, id X, before *T) error {
+	if !r.tracked() && !r.hooks.has(event) {
+		return nil
+	}
+	model, err := r.get(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	if err := r.hooks.run(ctx, event, model); err != nil {
+		return err
+	}
+	return r.record(ctx, op, before, model)
+}
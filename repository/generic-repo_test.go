@@ -4,13 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/cache"
+	"github.com/alvarotor/entitier-go/history"
 	"github.com/alvarotor/entitier-go/mocks"
 	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"github.com/alvarotor/entitier-go/tenancy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -40,6 +47,37 @@ type TestModelWithStringID struct {
 	Email string `gorm:"unique"`
 }
 
+type TestModelVersioned struct {
+	ID      uint   `gorm:"primaryKey"`
+	Email   string `gorm:"unique"`
+	Version uint   `entitier:"version"`
+}
+
+type TestModelRevision struct {
+	ID       uint `gorm:"primaryKey"`
+	Email    string
+	Revision int
+}
+
+func (TestModelRevision) VersionField() string {
+	return "Revision"
+}
+
+type TestModelSoftDelete struct {
+	ID        uint `gorm:"primaryKey"`
+	Email     string
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type TestModelPatch struct {
+	ID       uint `gorm:"primaryKey"`
+	Email    string
+	Age      int
+	Active   bool
+	Nickname *string
+	Secret   string `json:"-"`
+}
+
 var ctx = context.Background()
 
 func TestGenericRepository_Create_WithVariousFields(t *testing.T) {
@@ -507,9 +545,2647 @@ func TestUserRepository_Create_ErrDuplicatedKey(t *testing.T) {
 	_, err := repo.Create(context.Background(), *user)
 
 	assert.Error(t, err)
-	assert.Equal(t, models.ErrDuplicatedKeyEmail, err)
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+	assert.ErrorIs(t, err, models.ErrDuplicatedKeyEmail)
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
 
 	var count int64
 	db.Model(&TestModelWithVariousFields{}).Count(&count)
 	assert.Equal(t, int64(0), count, "No user should have been created")
 }
+
+func TestGenericRepository_GetAllPaged(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	for i := 0; i < 25; i++ {
+		db.Create(&mocks.TestModel{Email: fmt.Sprintf("test%d@example.com", i)})
+	}
+
+	result, total, err := repo.GetAllPaged(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+	assert.Equal(t, 10, len(result))
+	assert.Equal(t, uint(1), result[0].ID)
+
+	result, total, err = repo.GetAllPaged(ctx, 3, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+	assert.Equal(t, 5, len(result))
+	assert.Equal(t, uint(21), result[0].ID)
+
+	result, _, err = repo.GetAllPaged(ctx, 4, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(result))
+}
+
+func TestGenericRepository_GetAllPaged_Empty(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	result, total, err := repo.GetAllPaged(ctx, 1, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Equal(t, 0, len(result))
+}
+
+func TestGenericRepository_GetAllPaged_InvalidParams(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	_, _, err := repo.GetAllPaged(ctx, 0, 10)
+	assert.True(t, errors.Is(err, models.ErrInvalidPagination))
+
+	_, _, err = repo.GetAllPaged(ctx, 1, 0)
+	assert.True(t, errors.Is(err, models.ErrInvalidPagination))
+}
+
+func TestGenericRepository_GetAllCursor_UintID(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	for i := 0; i < 25; i++ {
+		db.Create(&mocks.TestModel{Email: fmt.Sprintf("test%d@example.com", i)})
+	}
+
+	var seen []uint
+	cursor := ""
+	for {
+		result, next, err := repo.GetAllCursor(ctx, cursor, 10, "")
+		assert.NoError(t, err)
+		for _, item := range result {
+			seen = append(seen, item.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	assert.Equal(t, 25, len(seen))
+	for i, id := range seen {
+		assert.Equal(t, uint(i+1), id)
+	}
+}
+
+func TestGenericRepository_GetAllCursor_StringID(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithStringID{})
+	repo := NewGenericRepository[TestModelWithStringID, string](db)
+
+	for _, id := range []string{"c", "a", "e", "b", "d"} {
+		db.Create(&TestModelWithStringID{ID: id, Email: id + "@example.com"})
+	}
+
+	result, next, err := repo.GetAllCursor(ctx, "", 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", result[0].ID)
+	assert.Equal(t, "b", result[1].ID)
+	assert.NotEmpty(t, next)
+
+	result, next, err = repo.GetAllCursor(ctx, next, 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", result[0].ID)
+	assert.Equal(t, "d", result[1].ID)
+
+	result, next, err = repo.GetAllCursor(ctx, next, 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "e", result[0].ID)
+	assert.Empty(t, next)
+}
+
+func TestGenericRepository_GetAllCursor_SecondarySort(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	ages := []int{40, 20, 30, 20, 40, 10}
+	for i, age := range ages {
+		db.Create(&TestModelWithVariousFields{Email: fmt.Sprintf("test%d@example.com", i), Age: age})
+	}
+
+	var seen []uint
+	cursor := ""
+	for {
+		result, next, err := repo.GetAllCursor(ctx, cursor, 4, "-age")
+		assert.NoError(t, err)
+		for _, item := range result {
+			seen = append(seen, item.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	assert.Equal(t, []uint{5, 1, 3, 4, 2, 6}, seen)
+
+	_, _, err := repo.GetAllCursor(ctx, cursor, 4, "email")
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+}
+
+func TestGenericRepository_GetAllCursor_Invalid(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	_, _, err := repo.GetAllCursor(ctx, "not a cursor!", 10, "")
+	assert.True(t, errors.Is(err, models.ErrInvalidCursor))
+
+	_, _, err = repo.GetAllCursor(ctx, "", 10, "password")
+	assert.True(t, errors.Is(err, models.ErrUnknownField))
+
+	_, _, err = repo.GetAllCursor(ctx, "", 0, "")
+	assert.True(t, errors.Is(err, models.ErrInvalidPagination))
+}
+
+func TestGenericRepository_GetAll_Filters(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	db.Create(&TestModelWithVariousFields{Email: "alice@example.com", Age: 17})
+	db.Create(&TestModelWithVariousFields{Email: "alan@example.com", Age: 30})
+	db.Create(&TestModelWithVariousFields{Email: "bob@example.com", Age: 45})
+
+	tests := []struct {
+		name     string
+		filters  []models.QueryOption
+		expected []string
+	}{
+		{"eq", []models.QueryOption{models.Filter{Field: "email", Operator: models.FilterEq, Value: "bob@example.com"}}, []string{"bob@example.com"}},
+		{"gte", []models.QueryOption{models.Filter{Field: "age", Operator: models.FilterGte, Value: "18"}}, []string{"alan@example.com", "bob@example.com"}},
+		{"like and lt", []models.QueryOption{
+			models.Filter{Field: "email", Operator: models.FilterLike, Value: "al%"},
+			models.Filter{Field: "Age", Operator: models.FilterLt, Value: "40"},
+		}, []string{"alice@example.com", "alan@example.com"}},
+		{"in", []models.QueryOption{models.Filter{Field: "age", Operator: models.FilterIn, Value: "17,45"}}, []string{"alice@example.com", "bob@example.com"}},
+		{"ne", []models.QueryOption{models.Filter{Field: "age", Operator: models.FilterNe, Value: "30"}}, []string{"alice@example.com", "bob@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.GetAll(ctx, tt.filters...)
+			assert.NoError(t, err)
+			var emails []string
+			for _, item := range result {
+				emails = append(emails, item.Email)
+			}
+			assert.Equal(t, tt.expected, emails)
+		})
+	}
+}
+
+func TestGenericRepository_GetAll_InvalidFilters(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	_, err := repo.GetAll(ctx, models.Filter{Field: "password", Operator: models.FilterEq, Value: "x"})
+	assert.True(t, errors.Is(err, models.ErrUnknownField))
+
+	_, err = repo.GetAll(ctx, models.Filter{Field: "age", Operator: "regex", Value: "1"})
+	assert.True(t, errors.Is(err, models.ErrInvalidFilter))
+
+	_, err = repo.GetAll(ctx, models.Filter{Field: "age", Operator: models.FilterGt, Value: "old"})
+	assert.True(t, errors.Is(err, models.ErrInvalidFilter))
+}
+
+func TestGenericRepository_GetAllPaged_Filters(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	for i := 0; i < 10; i++ {
+		db.Create(&TestModelWithVariousFields{Email: fmt.Sprintf("test%d@example.com", i), Age: i})
+	}
+
+	result, total, err := repo.GetAllPaged(ctx, 1, 3, models.Filter{Field: "age", Operator: models.FilterGte, Value: "5"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	assert.Equal(t, 3, len(result))
+	assert.Equal(t, 5, result[0].Age)
+}
+
+func TestGenericRepository_GetAll_Sort(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	db.Create(&TestModelWithVariousFields{Email: "c@example.com", Age: 30})
+	db.Create(&TestModelWithVariousFields{Email: "a@example.com", Age: 20})
+	db.Create(&TestModelWithVariousFields{Email: "b@example.com", Age: 30})
+	db.Create(&TestModelWithVariousFields{Email: "d@example.com", Age: 20})
+
+	result, err := repo.GetAll(ctx, models.Sort{Field: "age", Desc: true}, models.Sort{Field: "Email"})
+	assert.NoError(t, err)
+	var emails []string
+	for _, item := range result {
+		emails = append(emails, item.Email)
+	}
+	assert.Equal(t, []string{"b@example.com", "c@example.com", "a@example.com", "d@example.com"}, emails)
+
+	// Ties fall back to the primary key.
+	result, err = repo.GetAll(ctx, models.Sort{Field: "age"})
+	assert.NoError(t, err)
+	var ids []uint
+	for _, item := range result {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []uint{2, 4, 1, 3}, ids)
+}
+
+func TestGenericRepository_GetAll_InvalidSort(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	_, err := repo.GetAll(ctx, models.Sort{Field: "password"})
+	assert.True(t, errors.Is(err, models.ErrUnknownField))
+
+	_, err = repo.GetAll(ctx, models.Sort{Field: "age"}, models.Sort{Field: "Age", Desc: true})
+	assert.True(t, errors.Is(err, models.ErrInvalidSort))
+
+	_, _, err = repo.GetAllCursor(ctx, "", 10, "", models.Sort{Field: "age"})
+	assert.True(t, errors.Is(err, models.ErrInvalidSort))
+}
+
+func TestGenericRepository_GetAllPaged_Sort(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	for i := 0; i < 10; i++ {
+		db.Create(&TestModelWithVariousFields{Email: fmt.Sprintf("test%d@example.com", i), Age: i})
+	}
+
+	result, total, err := repo.GetAllPaged(ctx, 2, 3, models.Sort{Field: "age", Desc: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), total)
+	assert.Equal(t, []int{6, 5, 4}, []int{result[0].Age, result[1].Age, result[2].Age})
+}
+
+func TestUnitOfWork_WithTx_Commit(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{}, &TestModelWithVariousFields{})
+	users := NewGenericRepository[mocks.TestModel, uint](db)
+	profiles := NewGenericRepository[TestModelWithVariousFields, uint](db)
+	db.Create(&TestModelWithVariousFields{Email: "old@example.com", Age: 20})
+
+	err := NewUnitOfWork(db).WithTx(ctx, func(ctx context.Context) error {
+		if _, err := users.Create(ctx, mocks.TestModel{Email: "new@example.com"}); err != nil {
+			return err
+		}
+		return profiles.Update(ctx, 1, TestModelWithVariousFields{Age: 21})
+	})
+	assert.NoError(t, err)
+
+	user, err := users.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+
+	profile, err := profiles.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 21, profile.Age)
+}
+
+func TestUnitOfWork_WithTx_Rollback(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{}, &TestModelWithVariousFields{})
+	users := NewGenericRepository[mocks.TestModel, uint](db)
+
+	errAbort := errors.New("abort")
+	err := NewUnitOfWork(db).WithTx(ctx, func(ctx context.Context) error {
+		if _, err := users.Create(ctx, mocks.TestModel{Email: "new@example.com"}); err != nil {
+			return err
+		}
+		profiles := TxRepository[TestModelWithVariousFields, uint](ctx, db)
+		if _, err := profiles.Create(ctx, TestModelWithVariousFields{Email: "profile@example.com"}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	assert.True(t, errors.Is(err, errAbort))
+
+	var count int64
+	db.Model(&mocks.TestModel{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&TestModelWithVariousFields{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestUnitOfWork_AfterCommit(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	uow := NewUnitOfWork(db)
+
+	var ran []string
+	AfterCommit(ctx, func() { ran = append(ran, "no tx") })
+	err := uow.WithTx(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, func() { ran = append(ran, "outer") })
+		_ = uow.WithTx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func() { ran = append(ran, "rolled back") })
+			return errors.New("inner failure")
+		})
+		assert.NoError(t, uow.WithTx(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func() { ran = append(ran, "inner") })
+			return nil
+		}))
+		assert.Equal(t, []string{"no tx"}, ran)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"no tx", "outer", "inner"}, ran)
+
+	err = uow.WithTx(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, func() { ran = append(ran, "rolled back") })
+		return errors.New("failure")
+	})
+	assert.Error(t, err)
+	assert.Len(t, ran, 3)
+}
+
+func TestUnitOfWork_TxRepository_Options(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenant{})
+
+	err := NewUnitOfWork(db).WithTx(ctx, func(ctx context.Context) error {
+		tenants := TxRepository[TestModelTenant, uint](ctx, db, WithTenancy())
+		_, err := tenants.Create(ctx, TestModelTenant{Email: "a@example.com"})
+		assert.ErrorIs(t, err, models.ErrTenantRequired)
+
+		created, err := tenants.Create(tenancy.WithTenant(ctx, "acme"), TestModelTenant{Email: "a@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", created.TenantID)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestUnitOfWork_WithTx_NestedSavepoint(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	users := NewGenericRepository[mocks.TestModel, uint](db)
+	uow := NewUnitOfWork(db)
+
+	errInner := errors.New("inner failure")
+	err := uow.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := users.Create(ctx, mocks.TestModel{Email: "outer@example.com"}); err != nil {
+			return err
+		}
+		innerErr := uow.WithTx(ctx, func(ctx context.Context) error {
+			if _, err := users.Create(ctx, mocks.TestModel{Email: "inner@example.com"}); err != nil {
+				return err
+			}
+			return errInner
+		})
+		assert.True(t, errors.Is(innerErr, errInner))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	result, err := users.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "outer@example.com", result[0].Email)
+}
+
+func TestGenericRepository_ContextCanceled(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	db.Create(&mocks.TestModel{Email: "test@example.com"})
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err := repo.Get(canceled, 1, "")
+	assert.True(t, errors.Is(err, models.ErrRequestCanceled))
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	_, err = repo.GetAll(canceled)
+	assert.True(t, errors.Is(err, models.ErrRequestCanceled))
+
+	err = repo.Update(canceled, 1, mocks.TestModel{Email: "changed@example.com"})
+	assert.True(t, errors.Is(err, models.ErrRequestCanceled))
+
+	err = repo.Delete(canceled, 1, true)
+	assert.True(t, errors.Is(err, models.ErrRequestCanceled))
+
+	_, err = repo.Create(canceled, mocks.TestModel{Email: "other@example.com"})
+	assert.True(t, errors.Is(err, models.ErrRequestCanceled))
+
+	result, err := repo.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", result.Email)
+}
+
+func TestGenericRepository_ContextDeadlineExceeded(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, _, err := repo.GetAllPaged(expired, 1, 10)
+	assert.True(t, errors.Is(err, models.ErrDeadlineExceeded))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	err = NewUnitOfWork(db).WithTx(expired, func(ctx context.Context) error {
+		return nil
+	})
+	assert.True(t, errors.Is(err, models.ErrDeadlineExceeded))
+}
+
+func TestGenericRepository_Update_OptimisticLocking(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	repo := NewGenericRepository[TestModelVersioned, uint](db)
+
+	created, err := repo.Create(ctx, TestModelVersioned{Email: "v1@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.Version)
+
+	err = repo.Update(ctx, created.ID, TestModelVersioned{Email: "v2@example.com", Version: 1})
+	assert.NoError(t, err)
+
+	fetched, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2@example.com", fetched.Email)
+	assert.Equal(t, uint(2), fetched.Version)
+
+	// A writer still holding version 1 loses.
+	err = repo.Update(ctx, created.ID, TestModelVersioned{Email: "stale@example.com", Version: 1})
+	assert.True(t, errors.Is(err, models.ErrConflict))
+
+	// Without a version the update is applied against the current one.
+	err = repo.Update(ctx, created.ID, TestModelVersioned{Email: "v3@example.com"})
+	assert.NoError(t, err)
+	fetched, _ = repo.Get(ctx, created.ID, "")
+	assert.Equal(t, uint(3), fetched.Version)
+
+	err = repo.Update(ctx, 999, TestModelVersioned{Email: "missing@example.com", Version: 1})
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
+
+func TestGenericRepository_UpdateField_OptimisticLocking(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	repo := NewGenericRepository[TestModelVersioned, uint](db)
+
+	created, err := repo.Create(ctx, TestModelVersioned{Email: "v1@example.com"})
+	assert.NoError(t, err)
+
+	err = repo.UpdateField(WithExpectedVersion(ctx, 1), created.ID, "Email", "v2@example.com")
+	assert.NoError(t, err)
+
+	fetched, _ := repo.Get(ctx, created.ID, "")
+	assert.Equal(t, "v2@example.com", fetched.Email)
+	assert.Equal(t, uint(2), fetched.Version)
+
+	err = repo.UpdateField(WithExpectedVersion(ctx, 1), created.ID, "Email", "stale@example.com")
+	assert.True(t, errors.Is(err, models.ErrConflict))
+
+	err = repo.UpdateField(ctx, created.ID, "Email", "v3@example.com")
+	assert.NoError(t, err)
+	fetched, _ = repo.Get(ctx, created.ID, "")
+	assert.Equal(t, uint(3), fetched.Version)
+}
+
+func TestGenericRepository_Delete_OptimisticLocking(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	repo := NewGenericRepository[TestModelVersioned, uint](db)
+
+	created, err := repo.Create(ctx, TestModelVersioned{Email: "v1@example.com", Version: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), created.Version)
+
+	err = repo.Delete(WithExpectedVersion(ctx, 4), created.ID, true)
+	assert.True(t, errors.Is(err, models.ErrConflict))
+
+	err = repo.Delete(WithExpectedVersion(ctx, 5), created.ID, true)
+	assert.NoError(t, err)
+
+	err = repo.Delete(WithExpectedVersion(ctx, 5), created.ID, true)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
+
+func TestGenericRepository_Versioned_Interface(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelRevision{})
+	repo := NewGenericRepository[TestModelRevision, uint](db)
+
+	created, err := repo.Create(ctx, TestModelRevision{Email: "r1@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.Revision)
+
+	err = repo.Update(ctx, created.ID, TestModelRevision{Email: "stale@example.com", Revision: 7})
+	assert.True(t, errors.Is(err, models.ErrConflict))
+
+	version, ok := VersionOf(&created)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), version)
+
+	_, ok = VersionOf(&mocks.TestModel{})
+	assert.False(t, ok)
+}
+
+func TestGenericRepository_Restore(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelSoftDelete{})
+	repo := NewGenericRepository[TestModelSoftDelete, uint](db)
+
+	created, err := repo.Create(ctx, TestModelSoftDelete{Email: "trash@example.com"})
+	assert.NoError(t, err)
+
+	err = repo.Delete(ctx, created.ID, false)
+	assert.NoError(t, err)
+	_, err = repo.Get(ctx, created.ID, "")
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+
+	err = repo.Restore(ctx, created.ID)
+	assert.NoError(t, err)
+	restored, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "trash@example.com", restored.Email)
+
+	// Restoring a row that is not in the trash fails.
+	err = repo.Restore(ctx, created.ID)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+}
+
+func TestGenericRepository_GetAllTrashed(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelSoftDelete{})
+	repo := NewGenericRepository[TestModelSoftDelete, uint](db)
+
+	_, err := repo.GetAllTrashed(ctx)
+	assert.True(t, errors.Is(err, models.ErrNotFound))
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.Create(ctx, TestModelSoftDelete{Email: fmt.Sprintf("test%d@example.com", i)})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, repo.Delete(ctx, 1, false))
+	assert.NoError(t, repo.Delete(ctx, 3, false))
+
+	trashed, err := repo.GetAllTrashed(ctx, models.Sort{Field: "id", Desc: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(trashed))
+	assert.Equal(t, uint(3), trashed[0].ID)
+	assert.Equal(t, uint(1), trashed[1].ID)
+
+	live, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(live))
+}
+
+func TestGenericRepository_Purge(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelSoftDelete{})
+	repo := NewGenericRepository[TestModelSoftDelete, uint](db)
+
+	old := time.Now().Add(-48 * time.Hour)
+	db.Create(&TestModelSoftDelete{Email: "old@example.com", DeletedAt: gorm.DeletedAt{Time: old, Valid: true}})
+	db.Create(&TestModelSoftDelete{Email: "recent@example.com", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}})
+	db.Create(&TestModelSoftDelete{Email: "live@example.com"})
+
+	purged, err := repo.Purge(ctx, time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var count int64
+	db.Unscoped().Model(&TestModelSoftDelete{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	live, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "live@example.com", live[0].Email)
+}
+
+func TestGenericRepository_SoftDelete_NotSupported(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	err := repo.Restore(ctx, 1)
+	assert.True(t, errors.Is(err, models.ErrSoftDeleteNotSupported))
+
+	_, err = repo.GetAllTrashed(ctx)
+	assert.True(t, errors.Is(err, models.ErrSoftDeleteNotSupported))
+
+	_, err = repo.Purge(ctx, time.Now())
+	assert.True(t, errors.Is(err, models.ErrSoftDeleteNotSupported))
+}
+
+func TestGenericRepository_CreateMany(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	var items []mocks.TestModel
+	for i := 0; i < 25; i++ {
+		items = append(items, mocks.TestModel{Email: fmt.Sprintf("test%d@example.com", i)})
+	}
+
+	results, err := repo.CreateMany(ctx, items, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, len(results))
+	for i, result := range results {
+		assert.NoError(t, result.Err)
+		assert.Equal(t, i, result.Index)
+		assert.NotZero(t, result.Item.ID)
+	}
+
+	var count int64
+	db.Model(&mocks.TestModel{}).Count(&count)
+	assert.Equal(t, int64(25), count)
+}
+
+func TestGenericRepository_CreateMany_PartialFailure(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	repo := NewGenericRepository[mocks.TestModel, uint](db)
+
+	db.Create(&mocks.TestModel{Email: "taken@example.com"})
+
+	items := []mocks.TestModel{
+		{Email: "a@example.com"},
+		{Email: "taken@example.com"},
+		{},
+		{Email: "b@example.com"},
+		{Email: "a@example.com"},
+	}
+
+	results, err := repo.CreateMany(ctx, items, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.True(t, errors.Is(results[2].Err, models.ErrModelCannotBeEmpty))
+	assert.NoError(t, results[3].Err)
+	assert.Error(t, results[4].Err)
+
+	var emails []string
+	db.Model(&mocks.TestModel{}).Order("id").Pluck("email", &emails)
+	assert.Equal(t, []string{"taken@example.com", "a@example.com", "b@example.com"}, emails)
+}
+
+func TestGenericRepository_UpdateMany(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	for i := 0; i < 5; i++ {
+		db.Create(&TestModelWithVariousFields{Email: fmt.Sprintf("test%d@example.com", i), Age: i})
+	}
+
+	affected, err := repo.UpdateMany(ctx, TestModelWithVariousFields{Salary: 100}, []uint{1, 2, 99})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	affected, err = repo.UpdateMany(ctx, TestModelWithVariousFields{Salary: 200}, nil,
+		models.Filter{Field: "age", Operator: models.FilterGte, Value: "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	var salaries []float64
+	db.Model(&TestModelWithVariousFields{}).Order("id").Pluck("salary", &salaries)
+	assert.Equal(t, []float64{100, 100, 0, 200, 200}, salaries)
+
+	_, err = repo.UpdateMany(ctx, TestModelWithVariousFields{Salary: 1}, nil)
+	assert.True(t, errors.Is(err, models.ErrMissingCondition))
+}
+
+func TestGenericRepository_UpdateMany_Versioned(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	repo := NewGenericRepository[TestModelVersioned, uint](db)
+
+	_, err := repo.Create(ctx, TestModelVersioned{Email: "a@example.com"})
+	assert.NoError(t, err)
+
+	affected, err := repo.UpdateMany(ctx, TestModelVersioned{Email: "b@example.com", Version: 42}, []uint{1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	fetched, err := repo.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", fetched.Email)
+	assert.Equal(t, uint(2), fetched.Version)
+
+	// Filtering on the column being changed still bumps the version.
+	filter := models.Filter{Field: "email", Operator: models.FilterEq, Value: "b@example.com"}
+	affected, err = repo.UpdateMany(ctx, TestModelVersioned{Email: "c@example.com"}, nil, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	fetched, err = repo.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "c@example.com", fetched.Email)
+	assert.Equal(t, uint(3), fetched.Version)
+}
+
+func TestGenericRepository_DeleteMany(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelSoftDelete{})
+	repo := NewGenericRepository[TestModelSoftDelete, uint](db)
+
+	for i := 0; i < 5; i++ {
+		db.Create(&TestModelSoftDelete{Email: fmt.Sprintf("test%d@example.com", i)})
+	}
+
+	affected, err := repo.DeleteMany(ctx, []uint{1, 2}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	affected, err = repo.DeleteMany(ctx, nil, true, models.Filter{Field: "email", Operator: models.FilterLike, Value: "test4%"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	trashed, err := repo.GetAllTrashed(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(trashed))
+
+	live, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(live))
+
+	_, err = repo.DeleteMany(ctx, nil, true)
+	assert.True(t, errors.Is(err, models.ErrMissingCondition))
+}
+
+func TestGenericRepository_Upsert_PrimaryKey(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	created, err := repo.Upsert(ctx, TestModelWithVariousFields{ID: 7, Email: "a@example.com", Age: 30}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), created.ID)
+
+	// Replacing writes zero values too.
+	_, err = repo.Upsert(ctx, TestModelWithVariousFields{ID: 7, Email: "b@example.com"}, nil, nil)
+	assert.NoError(t, err)
+
+	fetched, err := repo.Get(ctx, 7, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", fetched.Email)
+	assert.Equal(t, 0, fetched.Age)
+
+	var count int64
+	db.Model(&TestModelWithVariousFields{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestGenericRepository_Upsert_ConflictColumns(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+
+	db.Create(&TestModelWithVariousFields{Email: "a@example.com", Age: 30, Salary: 10})
+
+	_, err := repo.Upsert(ctx, TestModelWithVariousFields{Email: "a@example.com", Age: 31, Salary: 99}, []string{"Email"}, []string{"age"})
+	assert.NoError(t, err)
+
+	_, err = repo.Upsert(ctx, TestModelWithVariousFields{Email: "b@example.com", Age: 20}, []string{"email"}, []string{"age"})
+	assert.NoError(t, err)
+
+	result, err := repo.GetAll(ctx, models.Sort{Field: "id"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 31, result[0].Age)
+	assert.Equal(t, float64(10), result[0].Salary)
+	assert.Equal(t, "b@example.com", result[1].Email)
+
+	_, err = repo.Upsert(ctx, TestModelWithVariousFields{Email: "a@example.com"}, []string{"password"}, nil)
+	assert.True(t, errors.Is(err, models.ErrUnknownField))
+
+	_, err = repo.Upsert(ctx, TestModelWithVariousFields{Email: "a@example.com"}, []string{"email"}, []string{"password"})
+	assert.True(t, errors.Is(err, models.ErrUnknownField))
+}
+
+func TestGenericRepository_Upsert_StringID(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithStringID{})
+	repo := NewGenericRepository[TestModelWithStringID, string](db)
+
+	model := TestModelWithStringID{Email: "a@example.com"}
+	assert.NoError(t, SetPrimaryKey(&model, "abc"))
+	assert.Equal(t, "abc", model.ID)
+
+	_, err := repo.Upsert(ctx, model, nil, nil)
+	assert.NoError(t, err)
+	_, err = repo.Upsert(ctx, TestModelWithStringID{ID: "abc", Email: "b@example.com"}, nil, nil)
+	assert.NoError(t, err)
+
+	fetched, err := repo.Get(ctx, "abc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", fetched.Email)
+}
+
+func TestGenericRepository_Upsert_Versioned(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	repo := NewGenericRepository[TestModelVersioned, uint](db)
+
+	var inserted bool
+	created, err := repo.Upsert(WithUpsertCreated(ctx, &inserted), TestModelVersioned{ID: 1, Email: "a@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.Version)
+	assert.True(t, inserted)
+
+	updated, err := repo.Upsert(WithUpsertCreated(ctx, &inserted), TestModelVersioned{ID: 1, Email: "b@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelVersioned{ID: 1, Email: "b@example.com", Version: 2}, updated)
+	assert.False(t, inserted)
+
+	updated, err = repo.Upsert(WithExpectedVersion(ctx, 2), TestModelVersioned{ID: 1, Email: "c@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), updated.Version)
+
+	_, err = repo.Upsert(WithExpectedVersion(ctx, 2), TestModelVersioned{ID: 1, Email: "d@example.com"}, nil, nil)
+	assert.ErrorIs(t, err, models.ErrConflict)
+	_, err = repo.Upsert(ctx, TestModelVersioned{ID: 1, Email: "d@example.com", Version: 1}, nil, []string{"email", "version"})
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	fetched, err := repo.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelVersioned{ID: 1, Email: "c@example.com", Version: 3}, *fetched)
+}
+
+// TestGenericRepository_Upsert_DoNothing upserts with no column to update,
+// which must leave the conditions out of ON CONFLICT DO NOTHING.
+func TestGenericRepository_Upsert_DoNothing(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenant{})
+	repo := NewGenericRepository[TestModelTenant, uint](db, WithTenancy())
+	acme := tenancy.WithTenant(ctx, "acme")
+	globex := tenancy.WithTenant(ctx, "globex")
+
+	created, err := repo.Upsert(acme, TestModelTenant{ID: 1, Email: "a@acme.com"}, nil, []string{"tenant_id"})
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelTenant{ID: 1, TenantID: "acme", Email: "a@acme.com"}, created)
+
+	kept, err := repo.Upsert(acme, TestModelTenant{ID: 1, Email: "b@acme.com"}, nil, []string{"tenant_id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a@acme.com", kept.Email)
+
+	_, err = repo.Upsert(globex, TestModelTenant{ID: 1, Email: "g@globex.com"}, nil, []string{"tenant_id"})
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+	_, err = repo.Upsert(globex, TestModelTenant{ID: 1, Email: "g@globex.com"}, nil, nil)
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+
+	stored, err := repo.Get(acme, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelTenant{ID: 1, TenantID: "acme", Email: "a@acme.com"}, *stored)
+}
+
+func TestGenericRepository_Patch_MergePatch(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPatch{})
+	repo := NewGenericRepository[TestModelPatch, uint](db)
+
+	nickname := "tester"
+	created, err := repo.Create(ctx, TestModelPatch{Email: "a@example.com", Age: 30, Active: true, Nickname: &nickname, Secret: "s"})
+	assert.NoError(t, err)
+
+	patched, err := repo.Patch(ctx, created.ID, models.Patch{
+		Type:     models.MergePatchType,
+		Document: []byte(`{"Age":0,"Active":false,"Nickname":null}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelPatch{ID: created.ID, Email: "a@example.com", Secret: "s"}, *patched)
+
+	stored, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, *patched, *stored)
+}
+
+func TestGenericRepository_Patch_JSONPatch(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPatch{})
+	repo := NewGenericRepository[TestModelPatch, uint](db)
+
+	nickname := "tester"
+	created, err := repo.Create(ctx, TestModelPatch{Email: "a@example.com", Age: 30, Active: true, Nickname: &nickname})
+	assert.NoError(t, err)
+
+	patched, err := repo.Patch(ctx, created.ID, models.Patch{
+		Type: models.JSONPatchType,
+		Document: []byte(`[
+			{"op":"test","path":"/Age","value":30.0},
+			{"op":"replace","path":"/Email","value":"b@example.com"},
+			{"op":"replace","path":"/Active","value":false},
+			{"op":"remove","path":"/Nickname"}
+		]`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelPatch{ID: created.ID, Email: "b@example.com", Age: 30}, *patched)
+
+	_, err = repo.Patch(ctx, created.ID, models.Patch{
+		Type:     models.JSONPatchType,
+		Document: []byte(`[{"op":"test","path":"/Age","value":31},{"op":"replace","path":"/Age","value":0}]`),
+	})
+	assert.ErrorIs(t, err, models.ErrPatchTestFailed)
+
+	stored, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, stored.Age)
+}
+
+func TestGenericRepository_Patch_Invalid(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPatch{})
+	repo := NewGenericRepository[TestModelPatch, uint](db)
+
+	created, err := repo.Create(ctx, TestModelPatch{Email: "a@example.com"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		id    uint
+		patch models.Patch
+		err   error
+	}{
+		{"Unknown field", created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Unknown":1}`)}, models.ErrUnknownField},
+		{"Hidden field", created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Secret":"x"}`)}, models.ErrUnknownField},
+		{"Primary key", created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"ID":9}`)}, models.ErrInvalidPatch},
+		{"Wrong type", created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Age":"old"}`)}, models.ErrInvalidPatch},
+		{"Not an object", created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`[1]`)}, models.ErrInvalidPatch},
+		{"Unknown op", created.ID, models.Patch{Type: models.JSONPatchType, Document: []byte(`[{"op":"swap","path":"/Age"}]`)}, models.ErrInvalidPatch},
+		{"Missing path", created.ID, models.Patch{Type: models.JSONPatchType, Document: []byte(`[{"op":"replace","path":"/Nickname/x","value":1}]`)}, models.ErrInvalidPatch},
+		{"Unknown test path", created.ID, models.Patch{Type: models.JSONPatchType, Document: []byte(`[{"op":"test","path":"/Other","value":1}]`)}, models.ErrInvalidPatch},
+		{"Unsupported type", created.ID, models.Patch{Type: "text/plain", Document: []byte(`{}`)}, models.ErrUnsupportedPatchType},
+		{"Not found", created.ID + 1, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Age":1}`)}, models.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.Patch(ctx, tt.id, tt.patch)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestGenericRepository_Patch_OptimisticLocking(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	repo := NewGenericRepository[TestModelVersioned, uint](db)
+
+	created, err := repo.Create(ctx, TestModelVersioned{Email: "a@example.com"})
+	assert.NoError(t, err)
+
+	patched, err := repo.Patch(WithExpectedVersion(ctx, 1), created.ID, models.Patch{
+		Type:     models.MergePatchType,
+		Document: []byte(`{"Email":"b@example.com"}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), patched.Version)
+
+	_, err = repo.Patch(WithExpectedVersion(ctx, 1), created.ID, models.Patch{
+		Type:     models.MergePatchType,
+		Document: []byte(`{"Email":"c@example.com"}`),
+	})
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	_, err = repo.Patch(ctx, created.ID, models.Patch{
+		Type:     models.MergePatchType,
+		Document: []byte(`{"Version":7}`),
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidPatch)
+}
+
+func TestGenericRepository_Patch_NoChange(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelVersioned{})
+	var updates int
+	hooks := NewHooks[TestModelVersioned]().On(AfterUpdate, func(ctx context.Context, m *TestModelVersioned) error {
+		updates++
+		return nil
+	})
+	for name, repo := range map[string]IGenericRepo[TestModelVersioned, uint]{
+		"generic": NewGenericRepository[TestModelVersioned, uint](db, WithHooks(hooks)),
+		"memory":  NewMemoryRepository[TestModelVersioned, uint](WithHooks(hooks)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			updates = 0
+			created, err := repo.Create(ctx, TestModelVersioned{Email: name + "@example.com"})
+			assert.NoError(t, err)
+
+			patched, err := repo.Patch(ctx, created.ID, models.Patch{
+				Type:     models.MergePatchType,
+				Document: []byte(`{"Email":"` + name + `@example.com"}`),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, created, *patched)
+			assert.Zero(t, updates)
+
+			patched, err = repo.Patch(ctx, created.ID, models.Patch{
+				Type:     models.MergePatchType,
+				Document: []byte(`{"Email":"` + name + `.b@example.com"}`),
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, uint(2), patched.Version)
+			assert.Equal(t, 1, updates)
+		})
+	}
+}
+
+func TestGenericRepository_Patch_DeletedAt(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelSoftDelete{})
+	repo := NewGenericRepository[TestModelSoftDelete, uint](db)
+
+	created, err := repo.Create(ctx, TestModelSoftDelete{Email: "a@example.com"})
+	assert.NoError(t, err)
+
+	_, err = repo.Patch(ctx, created.ID, models.Patch{
+		Type:     models.MergePatchType,
+		Document: []byte(`{"DeletedAt":"2024-01-01T00:00:00Z"}`),
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidPatch)
+	_, err = repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+}
+
+type TestModelCompositeUnique struct {
+	ID     uint   `gorm:"primaryKey"`
+	Tenant string `json:"tenant" gorm:"uniqueIndex:idx_tenant_code"`
+	Code   string `json:"code" gorm:"uniqueIndex:idx_tenant_code"`
+}
+
+func TestGenericRepository_Create_DuplicateKeyColumns(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{}, &TestModelCompositeUnique{})
+
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db)
+	_, err := repo.Create(ctx, TestModelWithVariousFields{Email: "a@example.com"})
+	assert.NoError(t, err)
+	_, err = repo.Create(ctx, TestModelWithVariousFields{Email: "a@example.com"})
+
+	var dup *models.DuplicateKeyError
+	if assert.ErrorAs(t, err, &dup) {
+		assert.Equal(t, []string{"email"}, dup.Columns)
+		assert.Equal(t, []string{"Email"}, dup.Fields)
+		assert.Equal(t, "duplicated key: Email already exists", dup.Error())
+	}
+	assert.ErrorIs(t, err, models.ErrDuplicatedKeyEmail)
+
+	composite := NewGenericRepository[TestModelCompositeUnique, uint](db)
+	_, err = composite.Create(ctx, TestModelCompositeUnique{Tenant: "acme", Code: "x"})
+	assert.NoError(t, err)
+	_, err = composite.Create(ctx, TestModelCompositeUnique{Tenant: "acme", Code: "x"})
+
+	if assert.ErrorAs(t, err, &dup) {
+		assert.Equal(t, []string{"tenant", "code"}, dup.Fields)
+	}
+}
+
+func TestParseDuplicateKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		constraint string
+		columns    []string
+	}{
+		{"sqlite", errors.New("UNIQUE constraint failed: users.tenant, users.email"), "", []string{"tenant", "email"}},
+		{"postgres", errors.New(`ERROR: duplicate key value violates unique constraint "uni_users_email" (SQLSTATE 23505)`), "uni_users_email", nil},
+		{"postgres detail", errors.New(`duplicate key value violates unique constraint "idx_t_c" Key (tenant, code)=(a, b) already exists.`), "idx_t_c", []string{"tenant", "code"}},
+		{"mysql", errors.New("Error 1062 (23000): Duplicate entry 'a@x.com' for key 'users.uni_users_email'"), "uni_users_email", nil},
+		{"sqlserver", errors.New("mssql: Cannot insert duplicate key row in object 'dbo.users' with unique index 'idx_users_email'."), "idx_users_email", nil},
+		{"translated", gorm.ErrDuplicatedKey, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dup, ok := parseDuplicateKey(tt.err)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.constraint, dup.Constraint)
+				assert.Equal(t, tt.columns, dup.Columns)
+				assert.ErrorIs(t, dup, tt.err)
+			}
+		})
+	}
+
+	_, ok := parseDuplicateKey(errors.New("FOREIGN KEY constraint failed"))
+	assert.False(t, ok)
+}
+
+func TestGenericRepository_ResolveDuplicateKey(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelCompositeUnique{})
+	repo := &genericRepository[TestModelCompositeUnique, uint]{DB: db}
+
+	err := repo.writeError(errors.New(`duplicate key value violates unique constraint "idx_tenant_code"`))
+
+	var dup *models.DuplicateKeyError
+	if assert.ErrorAs(t, err, &dup) {
+		assert.Equal(t, []string{"tenant", "code"}, dup.Columns)
+		assert.Equal(t, []string{"tenant", "code"}, dup.Fields)
+	}
+}
+
+type TestModelValidated struct {
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"gte=0,lte=150"`
+	Min   int    `json:"min"`
+	Max   int    `json:"max"`
+}
+
+func (m *TestModelValidated) Validate(ctx context.Context) error {
+	if m.Min > m.Max {
+		return &models.ValidationError{Fields: []models.FieldError{{Field: "min", Message: "must not exceed max"}}}
+	}
+	return nil
+}
+
+func TestGenericRepository_Create_Validation(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelValidated{})
+	repo := NewGenericRepository[TestModelValidated, uint](db)
+
+	_, err := repo.Create(ctx, TestModelValidated{Email: "nope", Age: -1, Min: 2, Max: 1})
+
+	var invalid *models.ValidationError
+	if assert.ErrorAs(t, err, &invalid) {
+		assert.Equal(t, []models.FieldError{
+			{Field: "email", Rule: "email", Message: "must be a valid email address"},
+			{Field: "age", Rule: "gte", Param: "0", Message: "must be at least 0"},
+			{Field: "min", Message: "must not exceed max"},
+		}, invalid.Fields)
+	}
+	assert.ErrorIs(t, err, models.ErrValidation)
+
+	var count int64
+	db.Model(&TestModelValidated{}).Count(&count)
+	assert.Zero(t, count)
+
+	_, err = repo.Create(ctx, TestModelValidated{Email: "a@example.com", Age: 30})
+	assert.NoError(t, err)
+}
+
+func TestGenericRepository_UpdateAndPatch_Validation(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelValidated{})
+	repo := NewGenericRepository[TestModelValidated, uint](db)
+
+	created, err := repo.Create(ctx, TestModelValidated{Email: "a@example.com", Age: 30})
+	assert.NoError(t, err)
+
+	err = repo.Update(ctx, created.ID, TestModelValidated{ID: created.ID, Email: "nope"})
+	assert.ErrorIs(t, err, models.ErrValidation)
+
+	_, err = repo.Patch(ctx, created.ID, models.Patch{
+		Type:     models.MergePatchType,
+		Document: []byte(`{"min":5}`),
+	})
+	assert.ErrorIs(t, err, models.ErrValidation)
+
+	stored, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, created, *stored)
+
+	// A partial update is validated as the row it leaves behind.
+	assert.NoError(t, repo.Update(ctx, created.ID, TestModelValidated{Age: 31}))
+	err = repo.Update(ctx, created.ID, TestModelValidated{Min: 5})
+	assert.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestGenericRepository_UpdateMany_Validation(t *testing.T) {
+	for name, repo := range map[string]IGenericRepo[TestModelValidated, uint]{
+		"generic": NewGenericRepository[TestModelValidated, uint](mocks.SetupGORMSqlite(t, &TestModelValidated{})),
+		"memory":  NewMemoryRepository[TestModelValidated, uint](),
+		"per row": NewGenericRepository[TestModelValidated, uint](mocks.SetupGORMSqlite(t, &TestModelValidated{}),
+			WithHooks(NewHooks[TestModelValidated]().On(AfterUpdate, func(ctx context.Context, m *TestModelValidated) error { return nil }))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			created, err := repo.Create(ctx, TestModelValidated{Email: "a@example.com", Age: 30})
+			assert.NoError(t, err)
+
+			updated, err := repo.UpdateMany(ctx, TestModelValidated{Age: 31}, []uint{created.ID})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), updated)
+			_, err = repo.UpdateMany(ctx, TestModelValidated{Email: "nope"}, []uint{created.ID})
+			assert.ErrorIs(t, err, models.ErrValidation)
+			_, err = repo.UpdateMany(ctx, TestModelValidated{Age: 200}, []uint{created.ID})
+			assert.ErrorIs(t, err, models.ErrValidation)
+
+			stored, err := repo.Get(ctx, created.ID, "")
+			assert.NoError(t, err)
+			assert.Equal(t, TestModelValidated{ID: created.ID, Email: "a@example.com", Age: 31}, *stored)
+		})
+	}
+}
+
+func TestGenericRepository_CreateMany_Validation(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelValidated{})
+	repo := NewGenericRepository[TestModelValidated, uint](db)
+
+	results, err := repo.CreateMany(ctx, []TestModelValidated{
+		{Email: "a@example.com"},
+		{Email: "nope"},
+	}, 10)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, models.ErrValidation)
+}
+
+type TestModelAudited struct {
+	ID        uint `gorm:"primaryKey"`
+	Email     string
+	CreatedBy string
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type actorKey struct{}
+
+func TestGenericRepository_Hooks_Create(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{}, &mocks.TestModel{})
+
+	var created *TestModelAudited
+	hooks := NewHooks[TestModelAudited]().
+		On(BeforeCreate, func(ctx context.Context, m *TestModelAudited) error {
+			m.Email = strings.ToLower(m.Email)
+			m.CreatedBy, _ = ctx.Value(actorKey{}).(string)
+			return nil
+		}).
+		On(AfterCreate, func(ctx context.Context, m *TestModelAudited) error {
+			created = m
+			_, err := NewGenericRepository[mocks.TestModel, uint](db).Create(ctx, mocks.TestModel{Email: m.Email})
+			return err
+		})
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	actorCtx := context.WithValue(ctx, actorKey{}, "alice")
+	model, err := repo.Create(actorCtx, TestModelAudited{Email: "A@Example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelAudited{ID: model.ID, Email: "a@example.com", CreatedBy: "alice"}, model)
+	if assert.NotNil(t, created) {
+		assert.NotZero(t, created.ID)
+	}
+
+	var related int64
+	db.Model(&mocks.TestModel{}).Count(&related)
+	assert.Equal(t, int64(1), related)
+}
+
+func TestGenericRepository_Hooks_ErrorRollsBack(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{}, &mocks.TestModel{})
+	failed := errors.New("publish failed")
+
+	hooks := NewHooks[TestModelAudited]().
+		On(AfterCreate, func(ctx context.Context, m *TestModelAudited) error {
+			if _, err := NewGenericRepository[mocks.TestModel, uint](db).Create(ctx, mocks.TestModel{Email: m.Email}); err != nil {
+				return err
+			}
+			return failed
+		})
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	_, err := repo.Create(ctx, TestModelAudited{Email: "a@example.com"})
+	assert.ErrorIs(t, err, failed)
+
+	var count int64
+	db.Model(&TestModelAudited{}).Count(&count)
+	assert.Zero(t, count)
+	db.Model(&mocks.TestModel{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestGenericRepository_Hooks_Update(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{})
+
+	var after []string
+	hooks := NewHooks[TestModelAudited]().
+		On(BeforeUpdate, func(ctx context.Context, m *TestModelAudited) error {
+			m.Email = strings.ToLower(m.Email)
+			return nil
+		}).
+		On(BeforeUpdateField, func(ctx context.Context, m *TestModelAudited) error {
+			after = append(after, "before field "+m.Email)
+			return nil
+		}).
+		On(AfterUpdate, recordHook(&after, "update")).
+		On(AfterUpdateField, recordHook(&after, "field"))
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	created, err := repo.Create(ctx, TestModelAudited{Email: "a@example.com", CreatedBy: "alice"})
+	assert.NoError(t, err)
+
+	err = repo.Update(ctx, created.ID, TestModelAudited{Email: "B@Example.com"})
+	assert.NoError(t, err)
+	err = repo.UpdateField(ctx, created.ID, "email", "c@example.com")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"update b@example.com alice",
+		"before field b@example.com",
+		"field c@example.com alice",
+	}, after)
+}
+
+func recordHook(events *[]string, name string) Hook[TestModelAudited] {
+	return func(ctx context.Context, m *TestModelAudited) error {
+		*events = append(*events, name+" "+m.Email+" "+m.CreatedBy)
+		return nil
+	}
+}
+
+func TestGenericRepository_Hooks_Delete(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{})
+	protected := errors.New("protected")
+
+	var deleted []string
+	hooks := NewHooks[TestModelAudited]().
+		On(BeforeDelete, func(ctx context.Context, m *TestModelAudited) error {
+			if m.CreatedBy == "system" {
+				return protected
+			}
+			return nil
+		}).
+		On(AfterDelete, func(ctx context.Context, m *TestModelAudited) error {
+			deleted = append(deleted, m.Email)
+			return nil
+		})
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	system, err := repo.Create(ctx, TestModelAudited{Email: "root@example.com", CreatedBy: "system"})
+	assert.NoError(t, err)
+	user, err := repo.Create(ctx, TestModelAudited{Email: "a@example.com"})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, repo.Delete(ctx, system.ID, false), protected)
+	assert.NoError(t, repo.Delete(ctx, user.ID, false))
+	assert.NoError(t, repo.Delete(ctx, user.ID, true))
+	assert.ErrorIs(t, repo.Delete(ctx, user.ID, true), models.ErrNotFound)
+
+	assert.Equal(t, []string{"a@example.com", "a@example.com"}, deleted)
+	_, err = repo.Get(ctx, system.ID, "")
+	assert.NoError(t, err)
+}
+
+func TestGenericRepository_Hooks_Get(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{})
+	hidden := errors.New("hidden")
+
+	hooks := NewHooks[TestModelAudited]().
+		On(AfterGet, func(ctx context.Context, m *TestModelAudited) error {
+			if m.CreatedBy == "system" {
+				return hidden
+			}
+			m.Email = strings.ToUpper(m.Email)
+			return nil
+		})
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	user, err := repo.Create(ctx, TestModelAudited{Email: "a@example.com"})
+	assert.NoError(t, err)
+	system, err := repo.Create(ctx, TestModelAudited{Email: "root@example.com", CreatedBy: "system"})
+	assert.NoError(t, err)
+
+	fetched, err := repo.Get(ctx, user.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "A@EXAMPLE.COM", fetched.Email)
+
+	_, err = repo.Get(ctx, system.ID, "")
+	assert.ErrorIs(t, err, hidden)
+}
+
+func TestGenericRepository_Hooks_Patch(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{})
+
+	var after []string
+	hooks := NewHooks[TestModelAudited]().
+		On(BeforeUpdate, func(ctx context.Context, m *TestModelAudited) error {
+			m.Email = strings.ToLower(m.Email)
+			m.CreatedBy = "patcher"
+			m.ID = 99
+			return nil
+		}).
+		On(AfterUpdate, recordHook(&after, "update"))
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	created, err := repo.Create(ctx, TestModelAudited{Email: "a@example.com", CreatedBy: "alice"})
+	assert.NoError(t, err)
+
+	patched, err := repo.Patch(ctx, created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Email":"B@Example.com"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelAudited{ID: created.ID, Email: "b@example.com", CreatedBy: "patcher"}, *patched)
+	assert.Equal(t, []string{"update b@example.com patcher"}, after)
+
+	stored, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, *patched, *stored)
+}
+
+func TestGenericRepository_Hooks_Batch(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{})
+	protected := errors.New("protected")
+
+	var events []string
+	hooks := NewHooks[TestModelAudited]().
+		On(BeforeCreate, func(ctx context.Context, m *TestModelAudited) error {
+			m.Email = strings.ToLower(m.Email)
+			return nil
+		}).
+		On(AfterCreate, recordHook(&events, "create")).
+		On(BeforeUpdate, func(ctx context.Context, m *TestModelAudited) error {
+			m.CreatedBy = fmt.Sprintf("%s %d", m.CreatedBy, m.ID)
+			return nil
+		}).
+		On(AfterUpdate, recordHook(&events, "update")).
+		On(BeforeDelete, func(ctx context.Context, m *TestModelAudited) error {
+			if m.CreatedBy == "system" {
+				return protected
+			}
+			return nil
+		}).
+		On(AfterDelete, recordHook(&events, "delete"))
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithHooks(hooks))
+
+	results, err := repo.CreateMany(ctx, []TestModelAudited{{Email: "A@Example.com"}, {Email: "B@Example.com"}}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", results[0].Item.Email)
+	assert.Equal(t, "b@example.com", results[1].Item.Email)
+
+	upserted, err := repo.Upsert(ctx, TestModelAudited{ID: 3, Email: "C@Example.com", CreatedBy: "system"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "c@example.com", upserted.Email)
+
+	updated, err := repo.UpdateMany(ctx, TestModelAudited{CreatedBy: "bob"}, []uint{1, 2})
+	assert.Equal(t, int64(2), updated)
+	assert.NoError(t, err)
+
+	// One protected row rolls back the whole batch.
+	_, err = repo.DeleteMany(ctx, nil, false, models.Filter{Field: "email", Operator: models.FilterLike, Value: "%example.com"})
+	assert.ErrorIs(t, err, protected)
+	deleted, err := repo.DeleteMany(ctx, []uint{1}, false)
+	assert.Equal(t, int64(1), deleted)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"create a@example.com ",
+		"create b@example.com ",
+		"create c@example.com system",
+		"update a@example.com bob 1",
+		"update b@example.com bob 2",
+		"delete a@example.com bob 1",
+		"delete b@example.com bob 2",
+		"delete a@example.com bob 1",
+	}, events)
+	remaining, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 2)
+}
+
+func TestGenericRepository_WithHooks_WrongModel(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{})
+	assert.Panics(t, func() {
+		NewGenericRepository[TestModelAudited, uint](db, WithHooks(NewHooks[mocks.TestModel]()))
+	})
+}
+
+func outboxMessages(t *testing.T, db *gorm.DB) []outbox.Message {
+	var messages []outbox.Message
+	assert.NoError(t, db.Order("id").Find(&messages).Error)
+	return messages
+}
+
+func TestGenericRepository_Outbox(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPatch{}, &outbox.Message{})
+	repo := NewGenericRepository[TestModelPatch, uint](db, WithOutbox())
+
+	created, err := repo.Create(ctx, TestModelPatch{Email: "a@example.com", Age: 30})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Update(ctx, created.ID, TestModelPatch{Age: 31}))
+	assert.NoError(t, repo.Update(ctx, created.ID, TestModelPatch{Age: 31}))
+	assert.NoError(t, repo.UpdateField(ctx, created.ID, "email", "b@example.com"))
+	_, err = repo.Patch(ctx, created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Active":true}`)})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete(ctx, created.ID, true))
+
+	messages := outboxMessages(t, db)
+	if !assert.Len(t, messages, 5) {
+		return
+	}
+	id := fmt.Sprint(created.ID)
+	for _, m := range messages {
+		assert.Equal(t, "TestModelPatch", m.EntityType)
+		assert.Equal(t, id, m.EntityID)
+		assert.Nil(t, m.PublishedAt)
+	}
+	assert.Equal(t, []string{outbox.OpCreate, outbox.OpUpdate, outbox.OpUpdateField, outbox.OpUpdate, outbox.OpDelete},
+		[]string{messages[0].Operation, messages[1].Operation, messages[2].Operation, messages[3].Operation, messages[4].Operation})
+
+	assert.Nil(t, messages[0].Before)
+	assert.JSONEq(t, `{"ID":`+id+`,"Email":"a@example.com","Age":30,"Active":false,"Nickname":null}`, string(messages[0].After))
+	assert.JSONEq(t, `{"ID":`+id+`,"Email":"a@example.com","Age":30,"Active":false,"Nickname":null}`, string(messages[1].Before))
+	assert.JSONEq(t, `{"ID":`+id+`,"Email":"a@example.com","Age":31,"Active":false,"Nickname":null}`, string(messages[1].After))
+	assert.JSONEq(t, `{"ID":`+id+`,"Email":"b@example.com","Age":31,"Active":true,"Nickname":null}`, string(messages[4].Before))
+	assert.Nil(t, messages[4].After)
+}
+
+func TestGenericRepository_Outbox_BatchAndTrash(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelAudited{}, &outbox.Message{})
+	repo := NewGenericRepository[TestModelAudited, uint](db, WithOutbox())
+
+	_, err := repo.CreateMany(ctx, []TestModelAudited{{Email: "a@example.com"}, {Email: "b@example.com"}}, 10)
+	assert.NoError(t, err)
+	_, err = repo.Upsert(ctx, TestModelAudited{ID: 3, Email: "c@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	_, err = repo.Upsert(ctx, TestModelAudited{ID: 3, Email: "d@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	_, err = repo.UpdateMany(ctx, TestModelAudited{CreatedBy: "bob"}, []uint{1, 2})
+	assert.NoError(t, err)
+	_, err = repo.DeleteMany(ctx, nil, false, models.Filter{Field: "email", Operator: models.FilterLike, Value: "a%"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Restore(ctx, 1))
+	assert.NoError(t, repo.Delete(ctx, 1, false))
+	purged, err := repo.Purge(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	messages := outboxMessages(t, db)
+	var changes []string
+	for _, m := range messages {
+		changes = append(changes, m.Operation+" "+m.EntityID)
+	}
+	assert.Equal(t, []string{
+		"create 1", "create 2", "create 3", "update 3", "update 1", "update 2",
+		"delete 1", "restore 1", "delete 1", "delete 1",
+	}, changes)
+	if len(messages) == 10 {
+		assert.JSONEq(t, `{"ID":3,"Email":"c@example.com","CreatedBy":"","DeletedAt":null}`, string(messages[3].Before))
+		assert.JSONEq(t, `{"ID":3,"Email":"d@example.com","CreatedBy":"","DeletedAt":null}`, string(messages[3].After))
+		assert.JSONEq(t, `{"ID":1,"Email":"a@example.com","CreatedBy":"bob","DeletedAt":null}`, string(messages[7].After))
+		assert.NotNil(t, messages[9].Before)
+		assert.Nil(t, messages[9].After)
+	}
+}
+
+func TestGenericRepository_Outbox_RolledBackWithWrite(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{}, &outbox.Message{})
+	repo := NewGenericRepository[TestModelWithVariousFields, uint](db, WithOutbox())
+
+	_, err := repo.Create(ctx, TestModelWithVariousFields{Email: "a@example.com"})
+	assert.NoError(t, err)
+	_, err = repo.Create(ctx, TestModelWithVariousFields{Email: "a@example.com"})
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+
+	failed := errors.New("failed")
+	hooked := NewGenericRepository[TestModelWithVariousFields, uint](db, WithOutbox(), WithHooks(
+		NewHooks[TestModelWithVariousFields]().On(AfterCreate, func(ctx context.Context, m *TestModelWithVariousFields) error {
+			return failed
+		}),
+	))
+	_, err = hooked.Create(ctx, TestModelWithVariousFields{Email: "b@example.com"})
+	assert.ErrorIs(t, err, failed)
+
+	assert.Len(t, outboxMessages(t, db), 1)
+
+	err = NewUnitOfWork(db).WithTx(ctx, func(ctx context.Context) error {
+		if _, err := repo.Create(ctx, TestModelWithVariousFields{Email: "c@example.com"}); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Len(t, outboxMessages(t, db), 1)
+}
+
+func TestGenericRepository_Audit(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPatch{}, &audit.Entry{})
+	repo := NewGenericRepository[TestModelPatch, uint](db, WithAudit())
+
+	alice := audit.WithActor(ctx, "alice")
+	created, err := repo.Create(alice, TestModelPatch{Email: "a@example.com", Age: 30})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Update(audit.WithActor(ctx, "bob"), created.ID, TestModelPatch{Age: 31}))
+	assert.NoError(t, repo.UpdateField(alice, created.ID, "email", "a@example.com"))
+	assert.NoError(t, repo.Delete(alice, created.ID, false))
+
+	entries, err := repo.History(ctx, created.ID)
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+	assert.Equal(t, "TestModelPatch", entries[0].EntityType)
+	assert.Equal(t, fmt.Sprint(created.ID), entries[0].EntityID)
+	assert.Equal(t, []string{audit.OpCreate, audit.OpUpdate, audit.OpDelete},
+		[]string{entries[0].Operation, entries[1].Operation, entries[2].Operation})
+	assert.Equal(t, []string{"alice", "bob", "alice"}, []string{entries[0].Actor, entries[1].Actor, entries[2].Actor})
+	assert.Equal(t, []audit.Change{{Field: "Age", Before: []byte(`30`), After: []byte(`31`)}}, entries[1].Changes)
+
+	other, err := repo.History(ctx, created.ID+1)
+	assert.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestGenericRepository_Audit_NotEnabled(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPatch{})
+	repo := NewGenericRepository[TestModelPatch, uint](db)
+
+	_, err := repo.History(ctx, 1)
+	assert.ErrorIs(t, err, models.ErrAuditNotEnabled)
+}
+
+type TestModelHistory struct {
+	ID        uint `gorm:"primaryKey"`
+	Email     string
+	Nickname  *string
+	Version   uint `entitier:"version"`
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func TestGenericRepository_Versioning(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelHistory{}, &history.Snapshot{})
+	repo := NewGenericRepository[TestModelHistory, uint](db, WithVersioning())
+
+	nickname := "al"
+	beforeCreate := time.Now()
+	created, err := repo.Create(ctx, TestModelHistory{Email: "a@example.com", Nickname: &nickname})
+	assert.NoError(t, err)
+	afterCreate := time.Now()
+	assert.NoError(t, repo.Update(ctx, created.ID, TestModelHistory{Email: "b@example.com"}))
+	afterUpdate := time.Now()
+	assert.NoError(t, repo.UpdateField(ctx, created.ID, "nickname", nil))
+
+	versions, err := repo.ListVersions(ctx, created.ID)
+	assert.NoError(t, err)
+	if !assert.Len(t, versions, 2) {
+		return
+	}
+	assert.Equal(t, []uint64{1, 2}, []uint64{versions[0].Version, versions[1].Version})
+	assert.Equal(t, []string{history.OpUpdate, history.OpUpdateField}, []string{versions[0].Operation, versions[1].Operation})
+	assert.Equal(t, "a@example.com", versions[0].Entity.Email)
+	assert.Equal(t, uint(1), versions[0].Entity.Version)
+	assert.Equal(t, "b@example.com", versions[1].Entity.Email)
+	assert.Equal(t, &nickname, versions[1].Entity.Nickname)
+
+	_, err = repo.GetAsOf(ctx, created.ID, beforeCreate)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	asOf, err := repo.GetAsOf(ctx, created.ID, afterCreate)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", asOf.Email)
+	asOf, err = repo.GetAsOf(ctx, created.ID, afterUpdate)
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", asOf.Email)
+	assert.Equal(t, &nickname, asOf.Nickname)
+	asOf, err = repo.GetAsOf(ctx, created.ID, time.Now())
+	assert.NoError(t, err)
+	assert.Nil(t, asOf.Nickname)
+
+	reverted, err := repo.Revert(ctx, created.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", reverted.Email)
+	assert.Equal(t, &nickname, reverted.Nickname)
+	assert.Equal(t, uint(4), reverted.Version)
+
+	versions, err = repo.ListVersions(ctx, created.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 3) {
+		assert.Equal(t, history.OpUpdate, versions[2].Operation)
+		assert.Equal(t, "b@example.com", versions[2].Entity.Email)
+		assert.Nil(t, versions[2].Entity.Nickname)
+	}
+
+	other, err := repo.ListVersions(ctx, created.ID+1)
+	assert.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestGenericRepository_Versioning_Delete(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelHistory{}, &history.Snapshot{})
+	repo := NewGenericRepository[TestModelHistory, uint](db, WithVersioning())
+
+	created, err := repo.Create(ctx, TestModelHistory{Email: "a@example.com"})
+	assert.NoError(t, err)
+	beforeDelete := time.Now()
+	assert.NoError(t, repo.Delete(ctx, created.ID, false))
+
+	_, err = repo.GetAsOf(ctx, created.ID, time.Now())
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	asOf, err := repo.GetAsOf(ctx, created.ID, beforeDelete)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", asOf.Email)
+
+	restored, err := repo.Revert(ctx, created.ID, 1)
+	assert.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+	_, err = repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.Delete(ctx, created.ID, true))
+	recreated, err := repo.Revert(ctx, created.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, recreated.ID)
+	fetched, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", fetched.Email)
+
+	versions, err := repo.ListVersions(ctx, created.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 3) {
+		assert.Equal(t, []string{history.OpDelete, history.OpUpdate, history.OpDelete},
+			[]string{versions[0].Operation, versions[1].Operation, versions[2].Operation})
+		assert.True(t, versions[1].Entity.DeletedAt.Valid)
+	}
+}
+
+func TestGenericRepository_Versioning_BatchAndTrash(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelHistory{}, &history.Snapshot{})
+	repo := NewGenericRepository[TestModelHistory, uint](db, WithVersioning())
+
+	_, err := repo.CreateMany(ctx, []TestModelHistory{{Email: "a@example.com"}, {Email: "b@example.com"}}, 10)
+	assert.NoError(t, err)
+	_, err = repo.UpdateMany(ctx, TestModelHistory{Email: "c@example.com"}, []uint{1})
+	assert.NoError(t, err)
+	_, err = repo.Upsert(ctx, TestModelHistory{ID: 1, Email: "d@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	_, err = repo.DeleteMany(ctx, []uint{1}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Restore(ctx, 1))
+	assert.NoError(t, repo.Delete(ctx, 1, false))
+	_, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	versions, err := repo.ListVersions(ctx, 1)
+	assert.NoError(t, err)
+	var changes []string
+	for _, v := range versions {
+		changes = append(changes, fmt.Sprintf("%s %s %d %t", v.Operation, v.Entity.Email, v.Entity.Version, v.Entity.DeletedAt.Valid))
+	}
+	assert.Equal(t, []string{
+		"update a@example.com 1 false",
+		"update c@example.com 2 false",
+		"delete d@example.com 3 false",
+		"restore d@example.com 3 true",
+		"delete d@example.com 3 false",
+		"delete d@example.com 3 true",
+	}, changes)
+
+	_, err = repo.GetAsOf(ctx, 1, time.Now())
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	others, err := repo.ListVersions(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestGenericRepository_Versioning_RevertErrors(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelHistory{}, &history.Snapshot{})
+	repo := NewGenericRepository[TestModelHistory, uint](db, WithVersioning())
+
+	created, err := repo.Create(ctx, TestModelHistory{Email: "a@example.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.UpdateField(ctx, created.ID, "email", "b@example.com"))
+
+	_, err = repo.Revert(ctx, created.ID, 2)
+	assert.ErrorIs(t, err, models.ErrVersionNotFound)
+
+	_, err = repo.Revert(WithExpectedVersion(ctx, 1), created.ID, 1)
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	versions, err := repo.ListVersions(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+}
+
+func TestGenericRepository_Versioning_NotEnabled(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelHistory{})
+	repo := NewGenericRepository[TestModelHistory, uint](db)
+
+	_, err := repo.GetAsOf(ctx, 1, time.Now())
+	assert.ErrorIs(t, err, models.ErrVersioningNotEnabled)
+	_, err = repo.ListVersions(ctx, 1)
+	assert.ErrorIs(t, err, models.ErrVersioningNotEnabled)
+	_, err = repo.Revert(ctx, 1, 1)
+	assert.ErrorIs(t, err, models.ErrVersioningNotEnabled)
+}
+
+type TestModelTenant struct {
+	ID        uint `gorm:"primaryKey"`
+	TenantID  string
+	Email     string
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type TestModelTenantTagged struct {
+	ID    uint `gorm:"primaryKey"`
+	OrgID uint `entitier:"tenant"`
+	Email string
+}
+
+func TestGenericRepository_Tenancy(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenant{})
+	repo := NewGenericRepository[TestModelTenant, uint](db, WithTenancy())
+	acme := tenancy.WithTenant(ctx, "acme")
+	globex := tenancy.WithTenant(ctx, "globex")
+
+	a, err := repo.Create(acme, TestModelTenant{TenantID: "globex", Email: "a@acme.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", a.TenantID)
+	g, err := repo.Create(globex, TestModelTenant{Email: "g@globex.com"})
+	assert.NoError(t, err)
+
+	items, err := repo.GetAll(acme)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, a.ID, items[0].ID)
+	}
+	_, total, err := repo.GetAllPaged(globex, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	_, err = repo.Get(acme, g.ID, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.ErrorIs(t, repo.Update(acme, g.ID, TestModelTenant{Email: "x@acme.com"}), models.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateField(acme, g.ID, "email", "x@acme.com"), models.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(acme, g.ID, false), models.ErrNotFound)
+	_, err = repo.Patch(acme, g.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Email":"x@acme.com"}`)})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	deleted, err := repo.DeleteMany(acme, []uint{a.ID, g.ID}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	stored, err := repo.Get(globex, g.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "g@globex.com", stored.Email)
+
+	assert.NoError(t, repo.Update(globex, g.ID, TestModelTenant{TenantID: "acme", Email: "h@globex.com"}))
+	assert.ErrorIs(t, repo.UpdateField(globex, g.ID, "tenant_id", "acme"), models.ErrUnknownField)
+	_, err = repo.Patch(globex, g.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"TenantID":"acme"}`)})
+	assert.ErrorIs(t, err, models.ErrInvalidPatch)
+	stored, err = repo.Get(globex, g.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelTenant{ID: g.ID, TenantID: "globex", Email: "h@globex.com"}, *stored)
+
+	_, err = repo.Upsert(acme, TestModelTenant{ID: g.ID, Email: "taken@acme.com"}, nil, nil)
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+	stored, err = repo.Get(globex, g.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "h@globex.com", stored.Email)
+}
+
+func TestGenericRepository_Tenancy_FailsClosed(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenant{})
+	repo := NewGenericRepository[TestModelTenant, uint](db, WithTenancy())
+
+	created, err := repo.Create(tenancy.WithTenant(ctx, "acme"), TestModelTenant{Email: "a@acme.com"})
+	assert.NoError(t, err)
+
+	_, err = repo.Create(ctx, TestModelTenant{Email: "b@acme.com"})
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, err = repo.Get(ctx, created.ID, "")
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, err = repo.GetAll(ctx)
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, _, err = repo.GetAllCursor(ctx, "", 10, "")
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	assert.ErrorIs(t, repo.Update(ctx, created.ID, TestModelTenant{Email: "b@acme.com"}), models.ErrTenantRequired)
+	assert.ErrorIs(t, repo.UpdateField(ctx, created.ID, "email", "b@acme.com"), models.ErrTenantRequired)
+	assert.ErrorIs(t, repo.Delete(ctx, created.ID, true), models.ErrTenantRequired)
+	_, err = repo.UpdateMany(ctx, TestModelTenant{Email: "b@acme.com"}, []uint{created.ID})
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, err = repo.DeleteMany(ctx, []uint{created.ID}, true)
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, err = repo.Purge(ctx, time.Now())
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+
+	var count int64
+	assert.NoError(t, db.Model(&TestModelTenant{}).Where("email = ?", "a@acme.com").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestGenericRepository_Tenancy_TaggedField(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenantTagged{}, &TestModelPatch{})
+	repo := NewGenericRepository[TestModelTenantTagged, uint](db, WithTenancy())
+
+	created, err := repo.Create(tenancy.WithTenant(ctx, "7"), TestModelTenantTagged{Email: "a@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), created.OrgID)
+
+	_, err = repo.Get(tenancy.WithTenant(ctx, "8"), created.ID, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	_, err = repo.Get(tenancy.WithTenant(ctx, "seven"), created.ID, "")
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+
+	untenanted := NewGenericRepository[TestModelPatch, uint](db, WithTenancy())
+	_, err = untenanted.GetAll(tenancy.WithTenant(ctx, "7"))
+	assert.ErrorIs(t, err, models.ErrUnknownField)
+}
+
+func TestGenericRepository_Tenancy_Versioning(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenant{}, &history.Snapshot{}, &audit.Entry{})
+	repo := NewGenericRepository[TestModelTenant, uint](db, WithTenancy(), WithVersioning(), WithAudit())
+	acme := tenancy.WithTenant(ctx, "acme")
+	globex := tenancy.WithTenant(ctx, "globex")
+
+	created, err := repo.Create(acme, TestModelTenant{Email: "a@acme.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete(acme, created.ID, true))
+
+	versions, err := repo.ListVersions(globex, created.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+	_, err = repo.ListVersions(ctx, created.ID)
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, err = repo.Revert(globex, created.ID, 1)
+	assert.ErrorIs(t, err, models.ErrVersionNotFound)
+	_, err = repo.GetAsOf(globex, created.ID, time.Now().Add(-time.Hour))
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	entries, err := repo.History(globex, created.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	reverted, err := repo.Revert(acme, created.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", reverted.TenantID)
+	entries, err = repo.History(acme, created.ID)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+type ttlBackend struct {
+	*cache.Memory[mocks.TestModel]
+	ttl time.Duration
+}
+
+func (b *ttlBackend) Set(ctx context.Context, key string, value mocks.TestModel, ttl time.Duration) error {
+	b.ttl = ttl
+	return b.Memory.Set(ctx, key, value, ttl)
+}
+
+func TestCachedRepository_Get(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	backend := &ttlBackend{Memory: cache.NewMemory[mocks.TestModel](10)}
+	repo := NewCachedRepository(NewGenericRepository[mocks.TestModel, uint](db), backend, WithCacheTTL(time.Minute))
+
+	created, err := repo.Create(ctx, mocks.TestModel{Email: "cached@test.com"})
+	assert.NoError(t, err)
+
+	first, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.Len())
+	assert.Equal(t, time.Minute, backend.ttl)
+
+	// Served from the cache, as a copy of the cached entity.
+	assert.NoError(t, db.Model(&mocks.TestModel{}).Where("id = ?", created.ID).Update("email", "direct@test.com").Error)
+	first.Email = "mutated@test.com"
+	second, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "cached@test.com", second.Email)
+
+	// Transactions read through.
+	err = NewUnitOfWork(db).WithTx(ctx, func(ctx context.Context) error {
+		model, err := repo.Get(ctx, created.ID, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "direct@test.com", model.Email)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	_, err = repo.Get(ctx, created.ID+1, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Equal(t, 1, backend.Len())
+}
+
+func TestCachedRepository_Invalidation(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelPreload{}, &OrdersModel{})
+	backend := cache.NewMemory[TestModelPreload](10)
+	repo := NewCachedRepository(NewGenericRepository[TestModelPreload, uint](db), backend)
+
+	user, err := repo.Create(ctx, TestModelPreload{Email: "a@test.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Create(&OrdersModel{OrderName: "Order", UserID: user.ID}).Error)
+
+	get := func(preload string) *TestModelPreload {
+		model, err := repo.Get(ctx, user.ID, preload)
+		assert.NoError(t, err)
+		return model
+	}
+
+	get("")
+	assert.Len(t, get("Orders").Orders, 1)
+	assert.Equal(t, 2, backend.Len())
+
+	assert.NoError(t, repo.Update(ctx, user.ID, TestModelPreload{Email: "b@test.com"}))
+	assert.Equal(t, 0, backend.Len())
+	assert.Equal(t, "b@test.com", get("").Email)
+	assert.Equal(t, "b@test.com", get("Orders").Email)
+
+	assert.NoError(t, repo.UpdateField(ctx, user.ID, "email", "c@test.com"))
+	assert.Equal(t, "c@test.com", get("Orders").Email)
+
+	_, err = repo.Patch(ctx, user.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Email":"d@test.com"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, "d@test.com", get("").Email)
+
+	_, err = repo.Upsert(ctx, TestModelPreload{ID: user.ID, Email: "e@test.com"}, nil, []string{"email"})
+	assert.NoError(t, err)
+	assert.Equal(t, "e@test.com", get("").Email)
+
+	_, err = repo.UpdateMany(ctx, TestModelPreload{Email: "f@test.com"}, []uint{user.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "f@test.com", get("").Email)
+
+	_, err = repo.UpdateMany(ctx, TestModelPreload{Email: "g@test.com"}, nil, models.Filter{Field: "email", Operator: models.FilterEq, Value: "f@test.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "g@test.com", get("").Email)
+
+	assert.NoError(t, repo.Delete(ctx, user.ID, true))
+	_, err = repo.Get(ctx, user.ID, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Equal(t, 0, backend.Len())
+}
+
+func TestCachedRepository_InvalidatesAfterCommit(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	backend := cache.NewMemory[mocks.TestModel](10)
+	repo := NewCachedRepository(NewGenericRepository[mocks.TestModel, uint](db), backend)
+	uow := NewUnitOfWork(db)
+
+	created, err := repo.Create(ctx, mocks.TestModel{Email: "a@test.com"})
+	assert.NoError(t, err)
+	_, err = repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+
+	failed := errors.New("failed")
+	err = uow.WithTx(ctx, func(ctx context.Context) error {
+		assert.NoError(t, repo.UpdateField(ctx, created.ID, "email", "b@test.com"))
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	cached, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a@test.com", cached.Email)
+
+	err = uow.WithTx(ctx, func(ctx context.Context) error {
+		assert.NoError(t, repo.UpdateField(ctx, created.ID, "email", "c@test.com"))
+		// Other readers still see the committed row until the commit.
+		assert.Equal(t, 1, backend.Len())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, backend.Len())
+	fetched, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "c@test.com", fetched.Email)
+}
+
+func TestCachedRepository_Singleflight(t *testing.T) {
+	inner := mocks.NewIGenericRepo[mocks.TestModel, uint](t)
+	repo := NewCachedRepository(inner, cache.NewMemory[mocks.TestModel](10))
+
+	release := make(chan struct{})
+	inner.EXPECT().Get(mock.Anything, uint(1), "").
+		RunAndReturn(func(context.Context, uint, string) (*mocks.TestModel, error) {
+			<-release
+			return &mocks.TestModel{ID: 1, Email: "once@test.com"}, nil
+		}).
+		Once()
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan *mocks.TestModel, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model, err := repo.Get(ctx, 1, "")
+			assert.NoError(t, err)
+			results <- model
+		}()
+	}
+
+	// A caller giving up does not cancel the shared load.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := repo.Get(canceled, 1, "")
+	assert.ErrorIs(t, err, models.ErrRequestCanceled)
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+	for model := range results {
+		assert.Equal(t, "once@test.com", model.Email)
+	}
+
+	model, err := repo.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "once@test.com", model.Email)
+}
+
+func TestCachedRepository_Tenancy(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &TestModelTenant{})
+	backend := cache.NewMemory[TestModelTenant](10)
+	repo := NewCachedRepository(NewGenericRepository[TestModelTenant, uint](db, WithTenancy()), backend)
+	acme := tenancy.WithTenant(ctx, "acme")
+	globex := tenancy.WithTenant(ctx, "globex")
+
+	created, err := repo.Create(acme, TestModelTenant{Email: "a@acme.com"})
+	assert.NoError(t, err)
+	_, err = repo.Get(acme, created.ID, "")
+	assert.NoError(t, err)
+
+	_, err = repo.Get(globex, created.ID, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	_, err = repo.Get(ctx, created.ID, "")
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	assert.Equal(t, 1, backend.Len())
+
+	assert.NoError(t, repo.UpdateField(acme, created.ID, "email", "b@acme.com"))
+	assert.Equal(t, 0, backend.Len())
+}
+
+func TestCachedRepository_SharedAcrossTenants(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	backend := cache.NewMemory[mocks.TestModel](10)
+	repo := NewCachedRepository(NewGenericRepository[mocks.TestModel, uint](db), backend)
+	acme := tenancy.WithTenant(ctx, "acme")
+	globex := tenancy.WithTenant(ctx, "globex")
+
+	created, err := repo.Create(ctx, mocks.TestModel{Email: "a@test.com"})
+	assert.NoError(t, err)
+	_, err = repo.Get(acme, created.ID, "")
+	assert.NoError(t, err)
+	_, err = repo.Get(globex, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.Len())
+
+	// A write in one tenant, or in none, invalidates what another read.
+	assert.NoError(t, repo.UpdateField(globex, created.ID, "email", "b@test.com"))
+	got, err := repo.Get(acme, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b@test.com", got.Email)
+	assert.NoError(t, repo.UpdateField(ctx, created.ID, "email", "c@test.com"))
+	got, err = repo.Get(acme, created.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "c@test.com", got.Email)
+}
+
+func TestMemoryRepository_CRUD(t *testing.T) {
+	repo := NewMemoryRepository[TestModelWithVariousFields, uint]()
+
+	_, err := repo.GetAll(ctx)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	_, err = repo.Create(ctx, TestModelWithVariousFields{})
+	assert.ErrorIs(t, err, models.ErrModelCannotBeEmpty)
+
+	first, err := repo.Create(ctx, TestModelWithVariousFields{Email: "a@test.com", Age: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), first.ID)
+	second, err := repo.Create(ctx, TestModelWithVariousFields{Email: "b@test.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), second.ID)
+
+	got, err := repo.Get(ctx, first.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, first, *got)
+	got.Email = "mutated@test.com"
+
+	assert.NoError(t, repo.Update(ctx, first.ID, TestModelWithVariousFields{Salary: 10}))
+	got, err = repo.Get(ctx, first.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "a@test.com", got.Email)
+	assert.Equal(t, 20, got.Age)
+	assert.Equal(t, float64(10), got.Salary)
+
+	assert.NoError(t, repo.UpdateField(ctx, first.ID, "age", 21))
+	assert.ErrorIs(t, repo.UpdateField(ctx, first.ID, "missing", 1), models.ErrUnknownField)
+	got, _ = repo.Get(ctx, first.ID, "")
+	assert.Equal(t, 21, got.Age)
+
+	assert.NoError(t, repo.Delete(ctx, first.ID, false))
+	_, err = repo.Get(ctx, first.ID, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, first.ID, TestModelWithVariousFields{Age: 1}), models.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, first.ID, false), models.ErrNotFound)
+
+	third, err := repo.Create(ctx, TestModelWithVariousFields{Email: "c@test.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), third.ID)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = repo.Get(canceled, second.ID, "")
+	assert.ErrorIs(t, err, models.ErrRequestCanceled)
+
+	_, err = repo.History(ctx, second.ID)
+	assert.ErrorIs(t, err, models.ErrAuditNotEnabled)
+	_, err = repo.GetAsOf(ctx, second.ID, time.Now())
+	assert.ErrorIs(t, err, models.ErrVersioningNotEnabled)
+	_, err = repo.ListVersions(ctx, second.ID)
+	assert.ErrorIs(t, err, models.ErrVersioningNotEnabled)
+	_, err = repo.Revert(ctx, second.ID, 1)
+	assert.ErrorIs(t, err, models.ErrVersioningNotEnabled)
+}
+
+func TestMemoryRepository_StringID(t *testing.T) {
+	repo := NewMemoryRepository[TestModelWithStringID, string]()
+
+	generated, err := repo.Create(ctx, TestModelWithStringID{Email: "a@test.com"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, generated.ID)
+
+	explicit, err := repo.Create(ctx, TestModelWithStringID{ID: "custom", Email: "b@test.com"})
+	assert.NoError(t, err)
+	got, err := repo.Get(ctx, "custom", "")
+	assert.NoError(t, err)
+	assert.Equal(t, explicit, *got)
+
+	_, err = repo.Create(ctx, TestModelWithStringID{ID: "custom", Email: "c@test.com"})
+	var dup *models.DuplicateKeyError
+	if assert.ErrorAs(t, err, &dup) {
+		assert.Equal(t, []string{"ID"}, dup.Fields)
+	}
+	assert.NoError(t, repo.Delete(ctx, "custom", false))
+	_, err = repo.Get(ctx, "custom", "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestMemoryRepository_DuplicateKey(t *testing.T) {
+	repo := NewMemoryRepository[TestModelWithVariousFields, uint]()
+	first, err := repo.Create(ctx, TestModelWithVariousFields{Email: "a@test.com"})
+	assert.NoError(t, err)
+	second, err := repo.Create(ctx, TestModelWithVariousFields{Email: "b@test.com"})
+	assert.NoError(t, err)
+
+	_, err = repo.Create(ctx, TestModelWithVariousFields{Email: "a@test.com"})
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+	assert.ErrorIs(t, err, models.ErrDuplicatedKeyEmail)
+	var dup *models.DuplicateKeyError
+	if assert.ErrorAs(t, err, &dup) {
+		assert.Equal(t, []string{"email"}, dup.Columns)
+		assert.Equal(t, []string{"Email"}, dup.Fields)
+	}
+	assert.ErrorIs(t, repo.Update(ctx, second.ID, TestModelWithVariousFields{Email: "a@test.com"}), models.ErrDuplicateKey)
+	assert.ErrorIs(t, repo.UpdateField(ctx, second.ID, "email", "a@test.com"), models.ErrDuplicateKey)
+	assert.NoError(t, repo.Update(ctx, first.ID, TestModelWithVariousFields{Email: "a@test.com", Age: 1}))
+
+	composite := NewMemoryRepository[TestModelCompositeUnique, uint]()
+	_, err = composite.Create(ctx, TestModelCompositeUnique{Tenant: "acme", Code: "x"})
+	assert.NoError(t, err)
+	_, err = composite.Create(ctx, TestModelCompositeUnique{Tenant: "globex", Code: "x"})
+	assert.NoError(t, err)
+	_, err = composite.Create(ctx, TestModelCompositeUnique{Tenant: "acme", Code: "x"})
+	if assert.ErrorAs(t, err, &dup) {
+		assert.Equal(t, "idx_tenant_code", dup.Constraint)
+		assert.Equal(t, []string{"tenant", "code"}, dup.Fields)
+	}
+}
+
+func TestMemoryRepository_SoftDelete(t *testing.T) {
+	repo := NewMemoryRepository[TestModelSoftDelete, uint]()
+	kept, err := repo.Create(ctx, TestModelSoftDelete{Email: "kept@test.com"})
+	assert.NoError(t, err)
+	trashed, err := repo.Create(ctx, TestModelSoftDelete{Email: "trashed@test.com"})
+	assert.NoError(t, err)
+
+	_, err = repo.GetAllTrashed(ctx)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.NoError(t, repo.Delete(ctx, trashed.ID, false))
+	items, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	items, err = repo.GetAllTrashed(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.True(t, items[0].DeletedAt.Valid)
+	}
+
+	assert.ErrorIs(t, repo.Restore(ctx, kept.ID), models.ErrNotFound)
+	assert.NoError(t, repo.Restore(ctx, trashed.ID))
+	_, err = repo.Get(ctx, trashed.ID, "")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.Delete(ctx, trashed.ID, false))
+	purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+	purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.ErrorIs(t, repo.Restore(ctx, trashed.ID), models.ErrNotFound)
+
+	assert.NoError(t, repo.Delete(ctx, kept.ID, true))
+	assert.ErrorIs(t, repo.Restore(ctx, kept.ID), models.ErrNotFound)
+
+	plain := NewMemoryRepository[mocks.TestModel, uint]()
+	assert.ErrorIs(t, plain.Restore(ctx, 1), models.ErrSoftDeleteNotSupported)
+	_, err = plain.GetAllTrashed(ctx)
+	assert.ErrorIs(t, err, models.ErrSoftDeleteNotSupported)
+	_, err = plain.Purge(ctx, time.Now())
+	assert.ErrorIs(t, err, models.ErrSoftDeleteNotSupported)
+}
+
+func TestMemoryRepository_OptimisticLocking(t *testing.T) {
+	repo := NewMemoryRepository[TestModelVersioned, uint]()
+	created, err := repo.Create(ctx, TestModelVersioned{Email: "a@test.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.Version)
+
+	assert.NoError(t, repo.Update(ctx, created.ID, TestModelVersioned{Email: "b@test.com", Version: 1}))
+	assert.ErrorIs(t, repo.Update(ctx, created.ID, TestModelVersioned{Email: "c@test.com", Version: 1}), models.ErrConflict)
+	assert.ErrorIs(t, repo.UpdateField(WithExpectedVersion(ctx, 1), created.ID, "email", "c@test.com"), models.ErrConflict)
+	assert.NoError(t, repo.UpdateField(WithExpectedVersion(ctx, 2), created.ID, "email", "c@test.com"))
+
+	_, err = repo.Patch(WithExpectedVersion(ctx, 2), created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Email":"d@test.com"}`)})
+	assert.ErrorIs(t, err, models.ErrConflict)
+	patched, err := repo.Patch(ctx, created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Email":"d@test.com"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), patched.Version)
+
+	assert.ErrorIs(t, repo.Delete(WithExpectedVersion(ctx, 3), created.ID, false), models.ErrConflict)
+	assert.NoError(t, repo.Delete(WithExpectedVersion(ctx, 4), created.ID, false))
+}
+
+func TestMemoryRepository_Batch(t *testing.T) {
+	repo := NewMemoryRepository[TestModelVersioned, uint]()
+
+	results, err := repo.CreateMany(ctx, []TestModelVersioned{
+		{Email: "a@test.com"}, {Email: "a@test.com"}, {}, {Email: "b@test.com"},
+	}, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, models.ErrDuplicateKey)
+	assert.ErrorIs(t, results[2].Err, models.ErrModelCannotBeEmpty)
+	assert.NoError(t, results[3].Err)
+	assert.Equal(t, uint(2), results[3].Item.ID)
+
+	_, err = repo.UpdateMany(ctx, TestModelVersioned{Email: "x@test.com"}, nil)
+	assert.ErrorIs(t, err, models.ErrMissingCondition)
+	_, err = repo.UpdateMany(ctx, TestModelVersioned{Email: "x@test.com"}, []uint{1, 2})
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+
+	filter := models.Filter{Field: "email", Operator: models.FilterLike, Value: "B%"}
+	updated, err := repo.UpdateMany(ctx, TestModelVersioned{Email: "c@test.com"}, nil, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+	got, _ := repo.Get(ctx, 2, "")
+	assert.Equal(t, "c@test.com", got.Email)
+	assert.Equal(t, uint(2), got.Version)
+
+	deleted, err := repo.DeleteMany(ctx, []uint{1, 2, 3}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	_, err = repo.DeleteMany(ctx, nil, false, models.Sort{Field: "email"})
+	assert.ErrorIs(t, err, models.ErrInvalidSort)
+}
+
+func TestMemoryRepository_Upsert(t *testing.T) {
+	repo := NewMemoryRepository[TestModelWithVariousFields, uint]()
+
+	created, err := repo.Upsert(ctx, TestModelWithVariousFields{Email: "a@test.com", Age: 1}, []string{"email"}, []string{"age"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.ID)
+
+	updated, err := repo.Upsert(ctx, TestModelWithVariousFields{Email: "a@test.com", Age: 2, Salary: 5}, []string{"email"}, []string{"age"})
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	got, _ := repo.Get(ctx, created.ID, "")
+	assert.Equal(t, 2, got.Age)
+	assert.Equal(t, float64(0), got.Salary)
+
+	_, err = repo.Upsert(ctx, TestModelWithVariousFields{ID: created.ID, Email: "b@test.com", Age: 3}, nil, nil)
+	assert.NoError(t, err)
+	got, _ = repo.Get(ctx, created.ID, "")
+	assert.Equal(t, "b@test.com", got.Email)
+	assert.Equal(t, 3, got.Age)
+
+	_, err = repo.Upsert(ctx, TestModelWithVariousFields{Email: "b@test.com"}, []string{"missing"}, nil)
+	assert.ErrorIs(t, err, models.ErrUnknownField)
+}
+
+func TestMemoryRepository_Upsert_Versioned(t *testing.T) {
+	repo := NewMemoryRepository[TestModelVersioned, uint]()
+
+	created, err := repo.Upsert(ctx, TestModelVersioned{ID: 1, Email: "a@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.Version)
+
+	updated, err := repo.Upsert(WithExpectedVersion(ctx, 1), TestModelVersioned{ID: 1, Email: "b@example.com"}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelVersioned{ID: 1, Email: "b@example.com", Version: 2}, updated)
+
+	_, err = repo.Upsert(WithExpectedVersion(ctx, 1), TestModelVersioned{ID: 1, Email: "c@example.com"}, nil, nil)
+	assert.ErrorIs(t, err, models.ErrConflict)
+
+	fetched, err := repo.Get(ctx, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelVersioned{ID: 1, Email: "b@example.com", Version: 2}, *fetched)
+}
+
+func TestMemoryRepository_Patch(t *testing.T) {
+	repo := NewMemoryRepository[TestModelPatch, uint]()
+	nickname := "al"
+	created, err := repo.Create(ctx, TestModelPatch{Email: "a@test.com", Age: 30, Active: true, Nickname: &nickname, Secret: "s"})
+	assert.NoError(t, err)
+
+	patched, err := repo.Patch(ctx, created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"Age":0,"Active":false,"Nickname":null}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, "a@test.com", patched.Email)
+	assert.Equal(t, 0, patched.Age)
+	assert.False(t, patched.Active)
+	assert.Nil(t, patched.Nickname)
+	assert.Equal(t, "s", patched.Secret)
+
+	_, err = repo.Patch(ctx, created.ID, models.Patch{Type: models.JSONPatchType, Document: []byte(`[{"op":"test","path":"/Age","value":1}]`)})
+	assert.ErrorIs(t, err, models.ErrPatchTestFailed)
+	_, err = repo.Patch(ctx, created.ID, models.Patch{Type: models.MergePatchType, Document: []byte(`{"ID":9}`)})
+	assert.ErrorIs(t, err, models.ErrInvalidPatch)
+	_, err = repo.Patch(ctx, created.ID+1, models.Patch{Type: models.MergePatchType, Document: []byte(`{}`)})
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestMemoryRepository_Preload(t *testing.T) {
+	repo := NewMemoryRepository[TestModelPreload, uint]()
+	created, err := repo.Create(ctx, TestModelPreload{Email: "a@test.com", Orders: []OrdersModel{{OrderName: "Order"}}})
+	assert.NoError(t, err)
+
+	got, err := repo.Get(ctx, created.ID, "")
+	assert.NoError(t, err)
+	assert.Empty(t, got.Orders)
+	got, err = repo.Get(ctx, created.ID, "Orders")
+	assert.NoError(t, err)
+	assert.Len(t, got.Orders, 1)
+	_, err = repo.Get(ctx, created.ID, "Missing")
+	assert.ErrorIs(t, err, models.ErrUnknownField)
+}
+
+func TestMemoryRepository_Concurrent(t *testing.T) {
+	repo := NewMemoryRepository[mocks.TestModel, uint]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			created, err := repo.Create(ctx, mocks.TestModel{Email: fmt.Sprintf("user%d@test.com", i)})
+			assert.NoError(t, err)
+			assert.NoError(t, repo.UpdateField(ctx, created.ID, "email", fmt.Sprintf("updated%d@test.com", i)))
+			_, err = repo.GetAll(ctx)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	items, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, items, 50)
+}
+
+func TestMemoryRepository_Hooks(t *testing.T) {
+	protected := errors.New("protected")
+	failed := errors.New("publish failed")
+
+	var events []string
+	hooks := NewHooks[TestModelAudited]().
+		On(BeforeCreate, func(ctx context.Context, m *TestModelAudited) error {
+			m.Email = strings.ToLower(m.Email)
+			return nil
+		}).
+		On(AfterCreate, recordHook(&events, "create")).
+		On(BeforeUpdate, func(ctx context.Context, m *TestModelAudited) error {
+			m.CreatedBy = fmt.Sprintf("%s %d", m.CreatedBy, m.ID)
+			return nil
+		}).
+		On(AfterUpdate, func(ctx context.Context, m *TestModelAudited) error {
+			if m.Email == "fail@example.com" {
+				return failed
+			}
+			return recordHook(&events, "update")(ctx, m)
+		}).
+		On(BeforeDelete, func(ctx context.Context, m *TestModelAudited) error {
+			if m.CreatedBy == "system" {
+				return protected
+			}
+			return nil
+		}).
+		On(AfterDelete, recordHook(&events, "delete"))
+	repo := NewMemoryRepository[TestModelAudited, uint](WithHooks(hooks))
+
+	created, err := repo.Create(ctx, TestModelAudited{Email: "A@Example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", created.Email)
+	results, err := repo.CreateMany(ctx, []TestModelAudited{{Email: "B@Example.com"}}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", results[0].Item.Email)
+	_, err = repo.Upsert(ctx, TestModelAudited{ID: 3, Email: "C@Example.com", CreatedBy: "system"}, nil, nil)
+	assert.NoError(t, err)
+
+	updated, err := repo.UpdateMany(ctx, TestModelAudited{CreatedBy: "bob"}, []uint{1, 2})
+	assert.Equal(t, int64(2), updated)
+	assert.NoError(t, err)
+	_, err = repo.Patch(ctx, 1, models.Patch{Type: models.MergePatchType, Document: []byte(`{"CreatedBy":"carol"}`)})
+	assert.NoError(t, err)
+
+	// A failing after hook puts the row back as it was.
+	assert.ErrorIs(t, repo.Update(ctx, 2, TestModelAudited{Email: "fail@example.com"}), failed)
+	stored, err := repo.Get(ctx, 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", stored.Email)
+
+	_, err = repo.DeleteMany(ctx, nil, false, models.Filter{Field: "email", Operator: models.FilterLike, Value: "%example.com"})
+	assert.ErrorIs(t, err, protected)
+	assert.NoError(t, repo.Delete(ctx, 1, false))
+
+	assert.Equal(t, []string{
+		"create a@example.com ",
+		"create b@example.com ",
+		"create c@example.com system",
+		"update a@example.com bob 1",
+		"update b@example.com bob 2",
+		"update a@example.com carol 1",
+		"delete a@example.com carol 1",
+		"delete b@example.com bob 2",
+		"delete a@example.com carol 1",
+	}, events)
+	remaining, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, remaining, 2)
+}
+
+func TestMemoryRepository_Tenancy(t *testing.T) {
+	repo := NewMemoryRepository[TestModelTenant, uint](WithTenancy())
+	acme := tenancy.WithTenant(ctx, "acme")
+	globex := tenancy.WithTenant(ctx, "globex")
+
+	a, err := repo.Create(acme, TestModelTenant{TenantID: "globex", Email: "a@acme.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", a.TenantID)
+	g, err := repo.Create(globex, TestModelTenant{Email: "g@globex.com"})
+	assert.NoError(t, err)
+
+	items, err := repo.GetAll(acme)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, a.ID, items[0].ID)
+	}
+	_, err = repo.Get(acme, g.ID, "")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.ErrorIs(t, repo.Update(acme, g.ID, TestModelTenant{Email: "x@acme.com"}), models.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(acme, g.ID, false), models.ErrNotFound)
+	deleted, err := repo.DeleteMany(acme, []uint{a.ID, g.ID}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	assert.NoError(t, repo.Update(globex, g.ID, TestModelTenant{TenantID: "acme", Email: "h@globex.com"}))
+	assert.ErrorIs(t, repo.UpdateField(globex, g.ID, "tenant_id", "acme"), models.ErrUnknownField)
+	_, err = repo.Upsert(acme, TestModelTenant{ID: g.ID, Email: "taken@acme.com"}, nil, nil)
+	assert.ErrorIs(t, err, models.ErrDuplicateKey)
+	stored, err := repo.Get(globex, g.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, TestModelTenant{ID: g.ID, TenantID: "globex", Email: "h@globex.com"}, *stored)
+
+	_, err = repo.Create(ctx, TestModelTenant{Email: "b@acme.com"})
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+	_, err = repo.GetAll(ctx)
+	assert.ErrorIs(t, err, models.ErrTenantRequired)
+
+	assert.Panics(t, func() {
+		NewMemoryRepository[TestModelTenant, uint](WithOutbox())
+	})
+}
+
+// TestMemoryRepository_QueryParity runs the same queries against the
+// memory and the sqlite backed repositories.
+func TestMemoryRepository_QueryParity(t *testing.T) {
+	joined := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []TestModelWithVariousFields{
+		{Email: "alice@example.com", Age: 17, Salary: 10, JoinedAt: joined},
+		{Email: "alan@example.com", Age: 30, Salary: 30, JoinedAt: joined.Add(time.Hour)},
+		{Email: "bob@example.com", Age: 45, Salary: 20, JoinedAt: joined.Add(2 * time.Hour)},
+		{Email: "carol@example.com", Age: 30, Salary: 40, JoinedAt: joined.Add(3 * time.Hour)},
+	}
+	repos := map[string]IGenericRepo[TestModelWithVariousFields, uint]{
+		"sqlite": NewGenericRepository[TestModelWithVariousFields, uint](mocks.SetupGORMSqlite(t, &TestModelWithVariousFields{})),
+		"memory": NewMemoryRepository[TestModelWithVariousFields, uint](),
+	}
+
+	emails := func(items []*TestModelWithVariousFields) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.Email)
+		}
+		return out
+	}
+	queries := [][]models.QueryOption{
+		{models.Filter{Field: "email", Operator: models.FilterEq, Value: "bob@example.com"}},
+		{models.Filter{Field: "age", Operator: models.FilterGte, Value: "18"}, models.Sort{Field: "salary", Desc: true}},
+		{models.Filter{Field: "email", Operator: models.FilterLike, Value: "al%"}, models.Filter{Field: "Age", Operator: models.FilterLt, Value: "40"}},
+		{models.Filter{Field: "age", Operator: models.FilterIn, Value: "17,45"}},
+		{models.Filter{Field: "age", Operator: models.FilterNe, Value: "30"}},
+		{models.Filter{Field: "joined_at", Operator: models.FilterGt, Value: joined.Format(time.RFC3339)}},
+		{models.Sort{Field: "age"}, models.Sort{Field: "email", Desc: true}},
+	}
+
+	results := map[string][]interface{}{}
+	for name, repo := range repos {
+		for _, model := range seed {
+			_, err := repo.Create(ctx, model)
+			assert.NoError(t, err)
+		}
+
+		for _, opts := range queries {
+			items, err := repo.GetAll(ctx, opts...)
+			assert.NoError(t, err)
+			results[name] = append(results[name], emails(items))
+		}
+
+		page, total, err := repo.GetAllPaged(ctx, 2, 3, models.Sort{Field: "email"})
+		assert.NoError(t, err)
+		results[name] = append(results[name], emails(page), total)
+
+		var walked []string
+		cursor := ""
+		for {
+			items, next, err := repo.GetAllCursor(ctx, cursor, 3, "-age")
+			assert.NoError(t, err)
+			walked = append(walked, emails(items)...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		results[name] = append(results[name], walked)
+
+		_, err = repo.GetAll(ctx, models.Filter{Field: "missing", Value: "1"})
+		assert.ErrorIs(t, err, models.ErrUnknownField)
+		_, err = repo.GetAll(ctx, models.Filter{Field: "age", Value: "old"})
+		assert.ErrorIs(t, err, models.ErrInvalidFilter)
+		_, _, err = repo.GetAllCursor(ctx, "garbage", 3, "")
+		assert.ErrorIs(t, err, models.ErrInvalidCursor)
+		_, err = repo.GetAll(ctx, models.Filter{Field: "email", Value: "nobody@example.com"})
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	}
+
+	assert.Equal(t, results["sqlite"], results["memory"])
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// HookEvent names the point of an operation a Hook runs at.
+type HookEvent string
+
+const (
+	BeforeCreate      HookEvent = "before_create"
+	AfterCreate       HookEvent = "after_create"
+	BeforeUpdate      HookEvent = "before_update"
+	AfterUpdate       HookEvent = "after_update"
+	BeforeUpdateField HookEvent = "before_update_field"
+	AfterUpdateField  HookEvent = "after_update_field"
+	BeforeDelete      HookEvent = "before_delete"
+	AfterDelete       HookEvent = "after_delete"
+	AfterGet          HookEvent = "after_get"
+)
+
+// Hook is called with the entity an operation works on. Returning an error
+// aborts the operation and rolls back its transaction.
+type Hook[T any] func(ctx context.Context, model *T) error
+
+// Hooks is a registry of lifecycle hooks for T, safe for concurrent use.
+// Hooks of one event run in the order they were registered.
+type Hooks[T any] struct {
+	mu    sync.RWMutex
+	hooks map[HookEvent][]Hook[T]
+}
+
+func NewHooks[T any]() *Hooks[T] {
+	return &Hooks[T]{hooks: map[HookEvent][]Hook[T]{}}
+}
+
+// On registers hooks for event and returns h so calls can be chained.
+func (h *Hooks[T]) On(event HookEvent, hooks ...Hook[T]) *Hooks[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks[event] = append(h.hooks[event], hooks...)
+	return h
+}
+
+func (h *Hooks[T]) has(events ...HookEvent) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, event := range events {
+		if len(h.hooks[event]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Hooks[T]) run(ctx context.Context, event HookEvent, model *T) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	hooks := h.hooks[event]
+	h.mu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(ctx, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,12 +1,33 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/models"
+)
 
 type IGenericRepo[T any, X string | uint] interface {
 	Create(context.Context, T) (T, error)
-	GetAll(context.Context) ([]*T, error)
+	GetAll(context.Context, ...models.QueryOption) ([]*T, error)
+	GetAllPaged(context.Context, int, int, ...models.QueryOption) ([]*T, int64, error)
+	GetAllCursor(context.Context, string, int, string, ...models.QueryOption) ([]*T, string, error)
 	Get(context.Context, X, string) (*T, error)
 	Update(context.Context, X, T) error
 	Delete(context.Context, X, bool) error
 	UpdateField(context.Context, X, string, interface{}) error
+	Restore(context.Context, X) error
+	GetAllTrashed(context.Context, ...models.QueryOption) ([]*T, error)
+	Purge(context.Context, time.Time) (int64, error)
+	CreateMany(context.Context, []T, int) ([]models.BatchResult[T], error)
+	UpdateMany(context.Context, T, []X, ...models.QueryOption) (int64, error)
+	DeleteMany(context.Context, []X, bool, ...models.QueryOption) (int64, error)
+	Upsert(context.Context, T, []string, []string) (T, error)
+	Patch(context.Context, X, models.Patch) (*T, error)
+	History(context.Context, X) ([]audit.Entry, error)
+	GetAsOf(context.Context, X, time.Time) (*T, error)
+	ListVersions(context.Context, X) ([]history.Version[T], error)
+	Revert(context.Context, X, uint64) (*T, error)
 }
@@ -0,0 +1,391 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+)
+
+func decodeDocument(data []byte) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidPatch, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: trailing data", models.ErrInvalidPatch)
+	}
+	return doc, nil
+}
+
+// applyMergePatch applies an RFC 7386 merge patch to doc and returns the
+// top-level members it touched.
+func applyMergePatch(doc interface{}, data []byte) (interface{}, []string, error) {
+	patch, err := decodeDocument(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: merge patch must be a JSON object", models.ErrInvalidPatch)
+	}
+
+	touched := make([]string, 0, len(members))
+	for name := range members {
+		touched = append(touched, name)
+	}
+	return mergePatch(doc, members), touched, nil
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+		} else {
+			object[name] = mergePatch(object[name], value)
+		}
+	}
+	return object
+}
+
+// applyJSONPatch applies an RFC 6902 patch to doc and returns the top-level
+// members written by it. Every path, including those only tested, is
+// returned in checked so the caller can validate it.
+func applyJSONPatch(doc interface{}, data []byte) (interface{}, []string, []string, error) {
+	var ops []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", models.ErrInvalidPatch, err)
+	}
+
+	var touched, checked []string
+	for i, op := range ops {
+		name, err := stringMember(op, "op")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: operation %d: %v", models.ErrInvalidPatch, i, err)
+		}
+		path, err := pointerMember(op, "path")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: operation %d: %v", models.ErrInvalidPatch, i, err)
+		}
+		if len(path) > 0 {
+			checked = append(checked, path[0])
+		}
+
+		var from []string
+		if name == "move" || name == "copy" {
+			if from, err = pointerMember(op, "from"); err != nil {
+				return nil, nil, nil, fmt.Errorf("%w: operation %d: %v", models.ErrInvalidPatch, i, err)
+			}
+			if len(from) > 0 {
+				checked = append(checked, from[0])
+			}
+		}
+
+		var value interface{}
+		if name == "add" || name == "replace" || name == "test" {
+			raw, ok := op["value"]
+			if !ok {
+				return nil, nil, nil, fmt.Errorf("%w: operation %d: missing value", models.ErrInvalidPatch, i)
+			}
+			if value, err = decodeDocument(raw); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+
+		if name != "test" {
+			if len(path) == 0 {
+				return nil, nil, nil, fmt.Errorf("%w: operation %d: cannot %s the whole document", models.ErrInvalidPatch, i, name)
+			}
+			touched = append(touched, path[0])
+		}
+
+		switch name {
+		case "add":
+			doc, err = pointerAdd(doc, path, value, false)
+		case "remove":
+			doc, _, err = pointerRemove(doc, path)
+		case "replace":
+			doc, err = pointerAdd(doc, path, value, true)
+		case "move":
+			if len(from) == 0 {
+				return nil, nil, nil, fmt.Errorf("%w: operation %d: cannot move the whole document", models.ErrInvalidPatch, i)
+			}
+			if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+				return nil, nil, nil, fmt.Errorf("%w: operation %d: cannot move a value into itself", models.ErrInvalidPatch, i)
+			}
+			touched = append(touched, from[0])
+			var moved interface{}
+			if doc, moved, err = pointerRemove(doc, from); err == nil {
+				doc, err = pointerAdd(doc, path, moved, false)
+			}
+		case "copy":
+			var copied interface{}
+			if copied, err = pointerGet(doc, from); err == nil {
+				doc, err = pointerAdd(doc, path, copyValue(copied), false)
+			}
+		case "test":
+			var current interface{}
+			if current, err = pointerGet(doc, path); err == nil && !equalValues(current, value) {
+				return nil, nil, nil, fmt.Errorf("%w: operation %d", models.ErrPatchTestFailed, i)
+			}
+		default:
+			return nil, nil, nil, fmt.Errorf("%w: operation %d: unknown op %q", models.ErrInvalidPatch, i, name)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: operation %d: %v", models.ErrInvalidPatch, i, err)
+		}
+	}
+
+	return doc, touched, checked, nil
+}
+
+func stringMember(op map[string]json.RawMessage, name string) (string, error) {
+	raw, ok := op[name]
+	if !ok {
+		return "", fmt.Errorf("missing %s", name)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+	return s, nil
+}
+
+func pointerMember(op map[string]json.RawMessage, name string) ([]string, error) {
+	s, err := stringMember(op, name)
+	if err != nil {
+		return nil, err
+	}
+	return parsePointer(s)
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if appending && token == "-" {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	limit := length
+	if appending {
+		limit++
+	}
+	if i >= limit {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func pointerGet(doc interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+	}
+	return doc, nil
+}
+
+// pointerAdd adds value at tokens, or replaces the existing value when
+// replace is set, and returns the updated document.
+func pointerAdd(doc interface{}, tokens []string, value interface{}, replace bool) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token := tokens[0]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if len(tokens) > 1 {
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			child, err := pointerAdd(child, tokens[1:], value, replace)
+			if err != nil {
+				return nil, err
+			}
+			node[token] = child
+			return node, nil
+		}
+		if replace && !ok {
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+		node[token] = value
+		return node, nil
+	case []interface{}:
+		if len(tokens) > 1 || replace {
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			if len(tokens) == 1 {
+				node[i] = value
+				return node, nil
+			}
+			child, err := pointerAdd(node[i], tokens[1:], value, replace)
+			if err != nil {
+				return nil, err
+			}
+			node[i] = child
+			return node, nil
+		}
+		i, err := arrayIndex(token, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		node = append(node, nil)
+		copy(node[i+1:], node[i:])
+		node[i] = value
+		return node, nil
+	}
+	return nil, fmt.Errorf("member %q not found", token)
+}
+
+// pointerRemove removes the value at tokens and returns the updated document
+// along with the removed value.
+func pointerRemove(doc interface{}, tokens []string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	token := tokens[0]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", token)
+		}
+		if len(tokens) == 1 {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := pointerRemove(child, tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = child
+		return node, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(tokens) == 1 {
+			removed := node[i]
+			return append(node[:i], node[i+1:]...), removed, nil
+		}
+		child, removed, err := pointerRemove(node[i], tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		node[i] = child
+		return node, removed, nil
+	}
+	return nil, nil, fmt.Errorf("member %q not found", token)
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			object[name] = copyValue(member)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i, item := range v {
+			array[i] = copyValue(item)
+		}
+		return array
+	}
+	return value
+}
+
+// equalValues compares two decoded JSON values, treating numbers by value
+// so that 1 and 1.0 are equal.
+func equalValues(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for name, member := range x {
+			other, ok := y[name]
+			if !ok || !equalValues(member, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
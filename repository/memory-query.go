@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type memoryOrder struct {
+	field *schema.Field
+	desc  bool
+}
+
+// options resolves opts like applyOptions, into the filter expressions rows
+// must match and the order they are returned in, ending with the primary
+// key.
+func (m *memoryRepository[T, X]) options(opts []models.QueryOption) ([]clause.Expression, []memoryOrder, error) {
+	var filters []clause.Expression
+	var orders []memoryOrder
+	sorted := map[string]bool{}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case models.Filter:
+			expr, err := m.meta.filterExpr(o)
+			if err != nil {
+				return nil, nil, err
+			}
+			filters = append(filters, expr)
+		case models.Sort:
+			field, err := m.meta.lookupColumn(o.Field)
+			if err != nil {
+				return nil, nil, err
+			}
+			if sorted[field.DBName] {
+				return nil, nil, fmt.Errorf("%w: %s is sorted more than once", models.ErrInvalidSort, o.Field)
+			}
+			sorted[field.DBName] = true
+			orders = append(orders, memoryOrder{field: field, desc: o.Desc})
+		default:
+			return nil, nil, fmt.Errorf("unsupported query option %T", opt)
+		}
+	}
+
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !sorted[pk.DBName] {
+		orders = append(orders, memoryOrder{field: pk})
+	}
+	return filters, orders, nil
+}
+
+// batchScope checks ids and opts like the batchScope of genericRepository
+// and returns the filters of opts.
+func (m *memoryRepository[T, X]) batchScope(ids []X, opts []models.QueryOption) ([]clause.Expression, error) {
+	if len(ids) == 0 && len(opts) == 0 {
+		return nil, models.ErrMissingCondition
+	}
+	if hasSort(opts) {
+		return nil, models.ErrInvalidSort
+	}
+	filters, _, err := m.options(opts)
+	return filters, err
+}
+
+func (m *memoryRepository[T, X]) inBatch(ctx context.Context, row T, ids []X, filters []clause.Expression, state rowState) bool {
+	if !m.inState(ctx, row, state) {
+		return false
+	}
+	rv := reflect.ValueOf(&row).Elem()
+	if len(ids) > 0 {
+		pk, err := m.meta.primaryField()
+		if err != nil {
+			return false
+		}
+		id, _ := pk.ValueOf(ctx, rv)
+		found := false
+		for _, candidate := range ids {
+			if fmt.Sprint(candidate) == fmt.Sprint(id) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return m.matchesAll(ctx, rv, filters)
+}
+
+// batchRows returns copies of the rows selected by ids and filters in
+// state, ordered by primary key. The caller holds the lock.
+func (m *memoryRepository[T, X]) batchRows(ctx context.Context, ids []X, filters []clause.Expression, state rowState) []T {
+	var rows []T
+	for _, row := range m.rows {
+		if m.inBatch(ctx, row, ids, filters, state) {
+			rows = append(rows, row)
+		}
+	}
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return rows
+	}
+	orders := []memoryOrder{{field: pk}}
+	sort.Slice(rows, func(i, j int) bool {
+		return compareRows(ctx, reflect.ValueOf(&rows[i]).Elem(), reflect.ValueOf(&rows[j]).Elem(), orders) < 0
+	})
+	return rows
+}
+
+func (m *memoryRepository[T, X]) list(ctx context.Context, state rowState, opts []models.QueryOption) ([]*T, error) {
+	items := []*T{}
+	filters, orders, err := m.options(opts)
+	if err != nil {
+		return items, err
+	}
+	if err := m.ready(ctx); err != nil {
+		return items, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sorted(ctx, state, filters, orders), nil
+}
+
+// sorted returns copies of the rows in state that match filters, in order.
+// The caller holds the lock.
+func (m *memoryRepository[T, X]) sorted(ctx context.Context, state rowState, filters []clause.Expression, orders []memoryOrder) []*T {
+	items := []*T{}
+	for _, row := range m.rows {
+		if m.inState(ctx, row, state) && m.matchesAll(ctx, reflect.ValueOf(&row).Elem(), filters) {
+			items = append(items, m.output(ctx, row, ""))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return compareRows(ctx, reflect.ValueOf(items[i]).Elem(), reflect.ValueOf(items[j]).Elem(), orders) < 0
+	})
+	return items
+}
+
+// compareRows orders a and b by orders. Nulls come first, as in sqlite.
+func compareRows(ctx context.Context, a reflect.Value, b reflect.Value, orders []memoryOrder) int {
+	for _, order := range orders {
+		av, _ := order.field.ValueOf(ctx, a)
+		bv, _ := order.field.ValueOf(ctx, b)
+		av, bv = plainValue(av), plainValue(bv)
+
+		var c int
+		switch {
+		case av == nil && bv == nil:
+		case av == nil:
+			c = -1
+		case bv == nil:
+			c = 1
+		default:
+			c, _ = compareValues(av, bv)
+		}
+		if order.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (m *memoryRepository[T, X]) matchesAll(ctx context.Context, rv reflect.Value, filters []clause.Expression) bool {
+	for _, expr := range filters {
+		if !m.matches(ctx, rv, expr) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches evaluates one of the expressions built by filterExpr against rv.
+// Comparisons with null are false, as in SQL.
+func (m *memoryRepository[T, X]) matches(ctx context.Context, rv reflect.Value, expr clause.Expression) bool {
+	value := func(column interface{}) interface{} {
+		s, err := m.meta.schema()
+		if err != nil {
+			return nil
+		}
+		col, _ := column.(clause.Column)
+		field := s.LookUpField(col.Name)
+		if field == nil {
+			return nil
+		}
+		v, _ := field.ValueOf(ctx, rv)
+		return plainValue(v)
+	}
+	compare := func(column interface{}, target interface{}, ok func(int) bool) bool {
+		c, comparable := compareValues(value(column), target)
+		return comparable && ok(c)
+	}
+
+	switch e := expr.(type) {
+	case clause.Eq:
+		if e.Value == nil {
+			return value(e.Column) == nil
+		}
+		return compare(e.Column, e.Value, func(c int) bool { return c == 0 })
+	case clause.Neq:
+		if e.Value == nil {
+			return value(e.Column) != nil
+		}
+		return compare(e.Column, e.Value, func(c int) bool { return c != 0 })
+	case clause.Gt:
+		return compare(e.Column, e.Value, func(c int) bool { return c > 0 })
+	case clause.Gte:
+		return compare(e.Column, e.Value, func(c int) bool { return c >= 0 })
+	case clause.Lt:
+		return compare(e.Column, e.Value, func(c int) bool { return c < 0 })
+	case clause.Lte:
+		return compare(e.Column, e.Value, func(c int) bool { return c <= 0 })
+	case clause.Like:
+		v := value(e.Column)
+		pattern, ok := e.Value.(string)
+		return v != nil && ok && likeMatch(fmt.Sprint(v), pattern)
+	case clause.IN:
+		for _, target := range e.Values {
+			if compare(e.Column, target, func(c int) bool { return c == 0 }) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// plainValue dereferences v and unwraps driver.Valuer types such as
+// gorm.DeletedAt, returning nil for nulls.
+func plainValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	if valuer, ok := rv.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		return value
+	}
+	return rv.Interface()
+}
+
+// compareValues compares two non-null values of the same kind, numbers of
+// any type with each other, and reports false when they are not
+// comparable.
+func compareValues(a interface{}, b interface{}) (int, bool) {
+	a, b = plainValue(a), plainValue(b)
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	switch av := a.(type) {
+	case time.Time:
+		bv, ok := b.(time.Time)
+		return av.Compare(bv), ok
+	case string:
+		bv, ok := b.(string)
+		return strings.Compare(av, bv), ok
+	case bool:
+		bv, ok := b.(bool)
+		switch {
+		case av == bv:
+			return 0, ok
+		case bv:
+			return -1, ok
+		}
+		return 1, ok
+	}
+
+	an, aok := number(a)
+	bn, bok := number(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	return an.Cmp(bn), true
+}
+
+func number(v interface{}) (*big.Float, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Float).SetUint64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(rv.Float()) {
+			return nil, false
+		}
+		return big.NewFloat(rv.Float()), true
+	}
+	return nil, false
+}
+
+// likeMatch reports whether s matches the SQL LIKE pattern, ignoring case.
+func likeMatch(s string, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString(`(?is)^`)
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(`.*`)
+		case '_':
+			expr.WriteString(`.`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString(`$`)
+	matched, err := regexp.MatchString(expr.String(), s)
+	return err == nil && matched
+}
+
+func sameValues(ctx context.Context, fields []*schema.Field, a reflect.Value, b reflect.Value) bool {
+	for _, field := range fields {
+		av, _ := field.ValueOf(ctx, a)
+		bv, _ := field.ValueOf(ctx, b)
+		if c, ok := compareValues(av, bv); !ok || c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func hasNull(ctx context.Context, fields []*schema.Field, rv reflect.Value) bool {
+	for _, field := range fields {
+		if v, _ := field.ValueOf(ctx, rv); plainValue(v) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonInto decodes raw into the addressable value v.
+func jsonInto(raw json.RawMessage, v reflect.Value) error {
+	return json.Unmarshal(raw, v.Addr().Interface())
+}
@@ -0,0 +1,1231 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type rowState int
+
+const (
+	stateLive rowState = iota
+	stateTrashed
+	stateAny
+)
+
+type memoryRepository[T any, X string | uint] struct {
+	// meta resolves the schema of T, and the columns, versions and
+	// duplicate keys derived from it, the way genericRepository does,
+	// without a database behind it.
+	meta *genericRepository[T, X]
+
+	mu     sync.RWMutex
+	rows   map[string]T
+	lastID uint64
+}
+
+// NewMemoryRepository returns an IGenericRepo that keeps its entities in
+// memory, for tests and prototypes. It follows the generic repository on
+// the same models: uint primary keys are numbered from 1 and empty string
+// ones get a random UUID, DeletedAt fields make deletes soft, unique
+// columns and indexes reject duplicates with a *models.DuplicateKeyError,
+// version fields lock optimistically, and missing rows are
+// models.ErrNotFound.
+//
+// WithHooks and WithTenancy are honoured. Hooks run outside the lock of
+// the repository, and when an after hook fails the rows the write changed
+// are put back as they were. There are no transactions or change tracking:
+// writes take effect immediately whatever the context carries, History,
+// GetAsOf, ListVersions and Revert report that they are not enabled, and
+// WithOutbox, WithAudit and WithVersioning panic. Associations
+// are stored with their entity instead of in their own table. Entities are
+// copied in and out shallowly, so nested pointers, slices and maps must not
+// be modified after a write or a read. Like filters match without regard
+// to case, as in sqlite.
+func NewMemoryRepository[T any, X string | uint](opts ...Option) IGenericRepo[T, X] {
+	cfg := newConfig(opts...)
+	if cfg.outbox || cfg.audit || cfg.versioning {
+		panic("repository: the memory repository has no outbox, audit trail or version history")
+	}
+	return &memoryRepository[T, X]{
+		meta: &genericRepository[T, X]{
+			DB:      &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}},
+			hooks:   hooksFor[T](cfg),
+			tenancy: cfg.tenancy,
+		},
+		rows: make(map[string]T),
+	}
+}
+
+func (m *memoryRepository[T, X]) Create(ctx context.Context, model T) (T, error) {
+	if err := m.ready(ctx); err != nil {
+		return model, err
+	}
+	if err := m.meta.hooks.run(ctx, BeforeCreate, &model); err != nil {
+		return model, err
+	}
+	if err := m.meta.prepareCreate(ctx, &model); err != nil {
+		return model, err
+	}
+
+	m.mu.Lock()
+	err := m.insert(ctx, &model)
+	m.mu.Unlock()
+	if err != nil {
+		return model, err
+	}
+	return model, m.after(ctx, AfterCreate, &model, m.takeBack(ctx, model))
+}
+
+func (m *memoryRepository[T, X]) GetAll(ctx context.Context, opts ...models.QueryOption) ([]*T, error) {
+	items, err := m.list(ctx, stateLive, opts)
+	if err != nil {
+		return items, err
+	}
+	if len(items) == 0 {
+		return items, models.ErrNotFound
+	}
+	return items, nil
+}
+
+func (m *memoryRepository[T, X]) GetAllPaged(ctx context.Context, page int, pageSize int, opts ...models.QueryOption) ([]*T, int64, error) {
+	if page < 1 || pageSize < 1 {
+		return []*T{}, 0, models.ErrInvalidPagination
+	}
+	items, err := m.list(ctx, stateLive, opts)
+	if err != nil {
+		return []*T{}, 0, err
+	}
+
+	total := int64(len(items))
+	start := min((page-1)*pageSize, len(items))
+	end := min(start+pageSize, len(items))
+	return items[start:end], total, nil
+}
+
+func (m *memoryRepository[T, X]) GetAllCursor(ctx context.Context, cursor string, limit int, sortBy string, opts ...models.QueryOption) ([]*T, string, error) {
+	items := []*T{}
+	if limit < 1 {
+		return items, "", models.ErrInvalidPagination
+	}
+	if hasSort(opts) {
+		return items, "", fmt.Errorf("%w: cursor pagination is ordered by its sort column", models.ErrInvalidSort)
+	}
+
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return items, "", err
+	}
+	desc := strings.HasPrefix(sortBy, "-")
+	sortBy = strings.TrimPrefix(sortBy, "-")
+	var sortField *schema.Field
+	if sortBy != "" {
+		if sortField, err = m.meta.lookupColumn(sortBy); err != nil {
+			return items, "", err
+		}
+		if sortField == pk {
+			sortField = nil
+		}
+	}
+	orders := []memoryOrder{{field: pk, desc: desc}}
+	if sortField != nil {
+		orders = append([]memoryOrder{{field: sortField, desc: desc}}, orders...)
+	}
+
+	filters, _, err := m.options(opts)
+	if err != nil {
+		return items, "", err
+	}
+
+	var after *T
+	if cursor != "" {
+		if after, err = m.cursorRow(cursor, pk, sortField); err != nil {
+			return items, "", err
+		}
+	}
+
+	if err := m.ready(ctx); err != nil {
+		return items, "", err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, item := range m.sorted(ctx, stateLive, filters, orders) {
+		if after != nil && compareRows(ctx, reflect.ValueOf(after).Elem(), reflect.ValueOf(item).Elem(), orders) >= 0 {
+			continue
+		}
+		items = append(items, item)
+		if len(items) > limit {
+			break
+		}
+	}
+	if len(items) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+	next, err := cursorFor[T, X](ctx, items[limit-1], pk, sortField)
+	if err != nil {
+		return items, "", err
+	}
+	return items, next, nil
+}
+
+// cursorRow decodes cursor into a model holding the position it points at,
+// so rows can be compared against it.
+func (m *memoryRepository[T, X]) cursorRow(cursor string, pk *schema.Field, sortField *schema.Field) (*T, error) {
+	cur, err := decodeCursor[X](cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	after := new(T)
+	rv := reflect.ValueOf(after).Elem()
+	if err := pk.Set(context.Background(), rv, cur.ID); err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	if sortField == nil {
+		if cur.Column != "" {
+			return nil, models.ErrInvalidCursor
+		}
+		return after, nil
+	}
+
+	if cur.Column != sortField.DBName || len(cur.Value) == 0 {
+		return nil, models.ErrInvalidCursor
+	}
+	if err := jsonInto(cur.Value, sortField.ReflectValueOf(context.Background(), rv)); err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	return after, nil
+}
+
+func (m *memoryRepository[T, X]) Get(ctx context.Context, id X, preload string) (*T, error) {
+	if err := m.ready(ctx); err != nil {
+		return nil, err
+	}
+	if err := m.checkPreload(preload); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	model, _, err := m.find(ctx, id, stateLive)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	output := m.output(ctx, model, preload)
+	if err := m.meta.hooks.run(ctx, AfterGet, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func (m *memoryRepository[T, X]) Update(ctx context.Context, id X, amended T) error {
+	if err := m.ready(ctx); err != nil {
+		return err
+	}
+	if err := m.meta.hooks.run(ctx, BeforeUpdate, &amended); err != nil {
+		return err
+	}
+	if err := m.meta.stampTenant(ctx, &amended); err != nil {
+		return err
+	}
+	existing, err := m.current(ctx, id, stateLive)
+	if err != nil {
+		return err
+	}
+	if err := m.meta.validateUpdate(ctx, existing, &amended); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	updated, existing, err := m.update(ctx, id, amended)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.after(ctx, AfterUpdate, m.output(ctx, updated, ""), m.putBack(ctx, existing))
+}
+
+// update applies the non-zero fields of amended to the row with the given
+// id and returns it with the row it replaced. The caller holds the write
+// lock.
+func (m *memoryRepository[T, X]) update(ctx context.Context, id X, amended T) (T, T, error) {
+	var zero T
+	existing, key, err := m.find(ctx, id, stateLive)
+	if err != nil {
+		return zero, zero, err
+	}
+
+	vf, err := m.meta.versionField()
+	if err != nil {
+		return zero, zero, err
+	}
+	source := reflect.ValueOf(&amended).Elem()
+	if vf != nil {
+		version, err := m.checkVersion(ctx, vf, source, existing)
+		if err != nil {
+			return zero, zero, err
+		}
+		if err := vf.Set(ctx, source, version+1); err != nil {
+			return zero, zero, err
+		}
+	}
+
+	fields, err := m.nonZeroFields(ctx, source)
+	if err != nil {
+		return zero, zero, err
+	}
+	updated := existing
+	if err := m.assign(ctx, &updated, source, fields); err != nil {
+		return zero, zero, err
+	}
+	if err := m.replace(ctx, key, &updated); err != nil {
+		return zero, zero, err
+	}
+	return updated, existing, nil
+}
+
+// UpdateField sets one column of the entity. BeforeUpdateField hooks get
+// the entity as stored before the change.
+func (m *memoryRepository[T, X]) UpdateField(ctx context.Context, id X, field string, amended interface{}) error {
+	if err := m.ready(ctx); err != nil {
+		return err
+	}
+	if m.meta.isTenantField(field) {
+		return fmt.Errorf("%w: %s cannot be updated", models.ErrUnknownField, field)
+	}
+	column, err := m.meta.lookupColumn(field)
+	if err != nil {
+		return err
+	}
+	if m.meta.hooks.has(BeforeUpdateField) {
+		existing, err := m.current(ctx, id, stateLive)
+		if err != nil {
+			return err
+		}
+		if err := m.meta.hooks.run(ctx, BeforeUpdateField, m.output(ctx, existing, "")); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	updated, existing, err := m.updateField(ctx, id, column, amended)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.after(ctx, AfterUpdateField, m.output(ctx, updated, ""), m.putBack(ctx, existing))
+}
+
+// updateField sets column of the row with the given id and returns it with
+// the row it replaced. The caller holds the write lock.
+func (m *memoryRepository[T, X]) updateField(ctx context.Context, id X, column *schema.Field, amended interface{}) (T, T, error) {
+	var zero T
+	existing, key, err := m.find(ctx, id, stateLive)
+	if err != nil {
+		return zero, zero, err
+	}
+
+	updated := existing
+	rv := reflect.ValueOf(&updated).Elem()
+	vf, err := m.meta.versionField()
+	if err != nil {
+		return zero, zero, err
+	}
+	if vf != nil {
+		version, err := m.checkVersion(ctx, vf, reflect.Value{}, existing)
+		if err != nil {
+			return zero, zero, err
+		}
+		if err := vf.Set(ctx, rv, version+1); err != nil {
+			return zero, zero, err
+		}
+	}
+	if err := column.Set(ctx, rv, amended); err != nil {
+		return zero, zero, err
+	}
+	if err := m.touch(ctx, rv, false); err != nil {
+		return zero, zero, err
+	}
+	if err := m.replace(ctx, key, &updated); err != nil {
+		return zero, zero, err
+	}
+	return updated, existing, nil
+}
+
+// Delete removes the entity. Its hooks get the entity as stored before it
+// was deleted.
+func (m *memoryRepository[T, X]) Delete(ctx context.Context, id X, permanently bool) error {
+	if err := m.ready(ctx); err != nil {
+		return err
+	}
+	state := stateLive
+	if permanently {
+		state = stateAny
+	}
+	if m.meta.hooks.has(BeforeDelete) {
+		existing, err := m.current(ctx, id, state)
+		if err != nil {
+			return err
+		}
+		if err := m.meta.hooks.run(ctx, BeforeDelete, m.output(ctx, existing, "")); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	existing, err := m.delete(ctx, id, state, permanently)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return m.after(ctx, AfterDelete, m.output(ctx, existing, ""), m.putBack(ctx, existing))
+}
+
+// delete removes the row with the given id and returns it as it was. The
+// caller holds the write lock.
+func (m *memoryRepository[T, X]) delete(ctx context.Context, id X, state rowState, permanently bool) (T, error) {
+	existing, key, err := m.find(ctx, id, state)
+	if err != nil {
+		return existing, err
+	}
+
+	vf, err := m.meta.versionField()
+	if err != nil {
+		return existing, err
+	}
+	if expected, ok := ExpectedVersion(ctx); ok && vf != nil {
+		if current, _ := m.version(ctx, vf, existing); current != expected {
+			return existing, models.ErrConflict
+		}
+	}
+
+	m.remove(ctx, key, existing, permanently)
+	return existing, nil
+}
+
+func (m *memoryRepository[T, X]) Restore(ctx context.Context, id X) error {
+	field, err := m.meta.deletedAtField()
+	if err != nil {
+		return err
+	}
+	if err := m.ready(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, key, err := m.find(ctx, id, stateTrashed)
+	if err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, reflect.ValueOf(&existing).Elem()).Set(reflect.ValueOf(gorm.DeletedAt{}))
+	m.rows[key] = existing
+	return nil
+}
+
+func (m *memoryRepository[T, X]) GetAllTrashed(ctx context.Context, opts ...models.QueryOption) ([]*T, error) {
+	var items []*T
+	if _, err := m.meta.deletedAtField(); err != nil {
+		return items, err
+	}
+	items, err := m.list(ctx, stateTrashed, opts)
+	if err != nil {
+		return items, err
+	}
+	if len(items) == 0 {
+		return items, models.ErrNotFound
+	}
+	return items, nil
+}
+
+func (m *memoryRepository[T, X]) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	field, err := m.meta.deletedAtField()
+	if err != nil {
+		return 0, err
+	}
+	if err := m.ready(ctx); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var purged int64
+	for key, row := range m.rows {
+		if m.inState(ctx, row, stateTrashed) && m.deletedAt(ctx, field, row).Time.Before(olderThan) {
+			delete(m.rows, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// CreateMany creates items one by one with Create, so a single bad item
+// only fails itself. batchSize is accepted for compatibility and ignored.
+func (m *memoryRepository[T, X]) CreateMany(ctx context.Context, items []T, batchSize int) ([]models.BatchResult[T], error) {
+	results := make([]models.BatchResult[T], len(items))
+	for i, item := range items {
+		if err := dbError(ctx.Err()); err != nil {
+			return results, err
+		}
+		created, err := m.Create(ctx, item)
+		results[i] = models.BatchResult[T]{Index: i, Item: created, Err: err}
+	}
+	return results, nil
+}
+
+// UpdateMany updates the rows together, or one by one when the update
+// hooks are registered, putting back the rows already updated when one
+// fails.
+func (m *memoryRepository[T, X]) UpdateMany(ctx context.Context, amended T, ids []X, opts ...models.QueryOption) (int64, error) {
+	vf, err := m.meta.versionField()
+	if err != nil {
+		return 0, err
+	}
+	filters, err := m.batchScope(ids, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.ready(ctx); err != nil {
+		return 0, err
+	}
+	if m.meta.hooks.has(BeforeUpdate, AfterUpdate) {
+		return m.updateEach(ctx, amended, ids, filters, vf)
+	}
+
+	if err := m.meta.stampTenant(ctx, &amended); err != nil {
+		return 0, err
+	}
+	if err := m.meta.validateFields(ctx, &amended); err != nil {
+		return 0, err
+	}
+	source := reflect.ValueOf(&amended).Elem()
+	if vf != nil {
+		if err := vf.Set(ctx, source, 0); err != nil {
+			return 0, err
+		}
+	}
+	fields, err := m.nonZeroFields(ctx, source)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The rows are updated together or not at all, so they are staged and
+	// checked for duplicates before replacing the stored ones.
+	staged := make(map[string]T, len(m.rows))
+	for key, row := range m.rows {
+		staged[key] = row
+	}
+	var changed []string
+	for key, row := range m.rows {
+		if !m.inBatch(ctx, row, ids, filters, stateLive) {
+			continue
+		}
+		if err := m.assign(ctx, &row, source, fields); err != nil {
+			return 0, err
+		}
+		if vf != nil {
+			version, _ := m.version(ctx, vf, row)
+			if err := vf.Set(ctx, reflect.ValueOf(&row).Elem(), version+1); err != nil {
+				return 0, err
+			}
+		}
+		staged[key] = row
+		changed = append(changed, key)
+	}
+	for _, key := range changed {
+		row := staged[key]
+		if err := m.checkUnique(ctx, staged, key, &row); err != nil {
+			return 0, err
+		}
+	}
+
+	m.rows = staged
+	return int64(len(changed)), nil
+}
+
+// updateEach updates the rows of a batch one by one, running the update
+// hooks of each with a copy of amended holding its primary key.
+func (m *memoryRepository[T, X]) updateEach(ctx context.Context, amended T, ids []X, filters []clause.Expression, vf *schema.Field) (int64, error) {
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return 0, err
+	}
+	m.mu.RLock()
+	rows := m.batchRows(ctx, ids, filters, stateLive)
+	m.mu.RUnlock()
+
+	var undo undoLog
+	for _, row := range rows {
+		id, _ := pk.ValueOf(ctx, reflect.ValueOf(&row).Elem())
+		changes := amended
+		source := reflect.ValueOf(&changes).Elem()
+		if err := pk.Set(ctx, source, id); err != nil {
+			return 0, undo.run(err)
+		}
+		if err := m.meta.hooks.run(ctx, BeforeUpdate, &changes); err != nil {
+			return 0, undo.run(err)
+		}
+		if err := m.meta.stampTenant(ctx, &changes); err != nil {
+			return 0, undo.run(err)
+		}
+		if err := m.meta.validateUpdate(ctx, row, &changes); err != nil {
+			return 0, undo.run(err)
+		}
+		if vf != nil {
+			if err := vf.Set(ctx, source, 0); err != nil {
+				return 0, undo.run(err)
+			}
+		}
+		fields, err := m.nonZeroFields(ctx, source)
+		if err != nil {
+			return 0, undo.run(err)
+		}
+
+		m.mu.Lock()
+		updated, err := m.updateRow(ctx, m.key(ctx, &row), source, fields, vf)
+		m.mu.Unlock()
+		if err != nil {
+			return 0, undo.run(err)
+		}
+		undo = append(undo, m.putBack(ctx, row))
+		if err := m.meta.hooks.run(ctx, AfterUpdate, m.output(ctx, updated, "")); err != nil {
+			return 0, undo.run(err)
+		}
+	}
+	return int64(len(rows)), nil
+}
+
+// updateRow copies fields from source into the live row under key and
+// bumps its version. The caller holds the write lock.
+func (m *memoryRepository[T, X]) updateRow(ctx context.Context, key string, source reflect.Value, fields []*schema.Field, vf *schema.Field) (T, error) {
+	row, ok := m.rows[key]
+	if !ok || !m.inState(ctx, row, stateLive) {
+		return row, models.ErrNotFound
+	}
+	if err := m.assign(ctx, &row, source, fields); err != nil {
+		return row, err
+	}
+	if vf != nil {
+		version, _ := m.version(ctx, vf, row)
+		if err := vf.Set(ctx, reflect.ValueOf(&row).Elem(), version+1); err != nil {
+			return row, err
+		}
+	}
+	return row, m.replace(ctx, key, &row)
+}
+
+// DeleteMany deletes the rows one by one when the delete hooks are
+// registered, putting back the rows already deleted when one fails.
+func (m *memoryRepository[T, X]) DeleteMany(ctx context.Context, ids []X, permanently bool, opts ...models.QueryOption) (int64, error) {
+	filters, err := m.batchScope(ids, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.ready(ctx); err != nil {
+		return 0, err
+	}
+	state := stateLive
+	if permanently {
+		state = stateAny
+	}
+	if m.meta.hooks.has(BeforeDelete, AfterDelete) {
+		return m.deleteEach(ctx, ids, filters, state, permanently)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for key, row := range m.rows {
+		if m.inBatch(ctx, row, ids, filters, state) {
+			m.remove(ctx, key, row, permanently)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// deleteEach deletes the rows of a batch one by one, running the delete
+// hooks of each.
+func (m *memoryRepository[T, X]) deleteEach(ctx context.Context, ids []X, filters []clause.Expression, state rowState, permanently bool) (int64, error) {
+	m.mu.RLock()
+	rows := m.batchRows(ctx, ids, filters, state)
+	m.mu.RUnlock()
+
+	var undo undoLog
+	var deleted int64
+	for _, row := range rows {
+		if err := m.meta.hooks.run(ctx, BeforeDelete, m.output(ctx, row, "")); err != nil {
+			return 0, undo.run(err)
+		}
+
+		key := m.key(ctx, &row)
+		m.mu.Lock()
+		current, ok := m.rows[key]
+		ok = ok && m.inState(ctx, current, state)
+		if ok {
+			m.remove(ctx, key, current, permanently)
+		}
+		m.mu.Unlock()
+		if !ok {
+			continue
+		}
+		deleted++
+		undo = append(undo, m.putBack(ctx, current))
+		if err := m.meta.hooks.run(ctx, AfterDelete, m.output(ctx, current, "")); err != nil {
+			return 0, undo.run(err)
+		}
+	}
+	return deleted, nil
+}
+
+// Upsert runs the create hooks around the write, whether it inserts or
+// updates.
+func (m *memoryRepository[T, X]) Upsert(ctx context.Context, model T, conflictColumns []string, updateColumns []string) (T, error) {
+	if err := m.ready(ctx); err != nil {
+		return model, err
+	}
+	if err := m.meta.hooks.run(ctx, BeforeCreate, &model); err != nil {
+		return model, err
+	}
+	vf, err := m.meta.versionField()
+	if err != nil {
+		return model, err
+	}
+	version, checkVersion := upsertVersion(ctx, vf, &model)
+	if err := m.meta.prepareCreate(ctx, &model); err != nil {
+		return model, err
+	}
+
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return model, err
+	}
+	conflicts := []*schema.Field{pk}
+	if len(conflictColumns) > 0 {
+		if conflicts, err = m.columns(conflictColumns); err != nil {
+			return model, err
+		}
+	}
+	columns, err := m.meta.upsertColumns(updateColumns, vf)
+	if err != nil {
+		return model, err
+	}
+	updates, err := m.columns(columns)
+	if err != nil {
+		return model, err
+	}
+
+	created := true
+	stored, undo, err := func() (T, func(), error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		source := reflect.ValueOf(&model).Elem()
+		for key, row := range m.rows {
+			if !sameValues(ctx, conflicts, source, reflect.ValueOf(&row).Elem()) {
+				continue
+			}
+			// A conflicting row of another tenant is left alone.
+			if !m.inState(ctx, row, stateAny) {
+				return model, nil, models.ErrDuplicateKey
+			}
+			previous := row
+			if err := m.assign(ctx, &row, source, updates); err != nil {
+				return model, nil, err
+			}
+			if vf != nil {
+				current, _ := m.version(ctx, vf, previous)
+				if checkVersion && current != version {
+					return model, nil, models.ErrConflict
+				}
+				if err := vf.Set(ctx, reflect.ValueOf(&row).Elem(), current+1); err != nil {
+					return model, nil, err
+				}
+			}
+			if err := m.replace(ctx, key, &row); err != nil {
+				return model, nil, err
+			}
+			created = false
+			return *m.output(ctx, row, ""), m.putBack(ctx, previous), nil
+		}
+
+		if err := m.insert(ctx, &model); err != nil {
+			return model, nil, err
+		}
+		return model, m.takeBack(ctx, model), nil
+	}()
+	if err != nil {
+		return stored, err
+	}
+	if err := m.after(ctx, AfterCreate, &stored, undo); err != nil {
+		return stored, err
+	}
+	reportUpsert(ctx, created)
+	return stored, nil
+}
+
+// Patch runs the update hooks like the generic repository, and writes
+// nothing when the patch changes nothing. The patch is
+// applied outside the lock, and fails with models.ErrConflict when the
+// entity changed in the meantime.
+func (m *memoryRepository[T, X]) Patch(ctx context.Context, id X, patch models.Patch) (*T, error) {
+	if err := m.ready(ctx); err != nil {
+		return nil, err
+	}
+	existing, err := m.current(ctx, id, stateLive)
+	if err != nil {
+		return nil, err
+	}
+
+	amended, columns, err := m.meta.applyPatch(ctx, &existing, patch)
+	if err != nil {
+		return nil, err
+	}
+	if columns, err = m.meta.beforePatch(ctx, &existing, &amended, columns); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return m.output(ctx, existing, ""), nil
+	}
+	fields, err := m.columns(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	updated, err := m.patch(ctx, id, existing, amended, fields)
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	output := m.output(ctx, updated, "")
+	if err := m.after(ctx, AfterUpdate, output, m.putBack(ctx, existing)); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// patch writes fields of amended to the row with the given id, which must
+// still be existing. The caller holds the write lock.
+func (m *memoryRepository[T, X]) patch(ctx context.Context, id X, existing T, amended T, fields []*schema.Field) (T, error) {
+	current, key, err := m.find(ctx, id, stateLive)
+	if err != nil {
+		return current, err
+	}
+	if !reflect.DeepEqual(current, existing) {
+		return current, models.ErrConflict
+	}
+
+	source := reflect.ValueOf(&amended).Elem()
+	vf, err := m.meta.versionField()
+	if err != nil {
+		return current, err
+	}
+	if vf != nil {
+		version, err := m.checkVersion(ctx, vf, reflect.Value{}, existing)
+		if err != nil {
+			return current, err
+		}
+		if err := vf.Set(ctx, source, version+1); err != nil {
+			return current, err
+		}
+		fields = append(fields, vf)
+	}
+
+	updated := existing
+	if err := m.assign(ctx, &updated, source, fields); err != nil {
+		return current, err
+	}
+	return updated, m.replace(ctx, key, &updated)
+}
+
+func (m *memoryRepository[T, X]) History(ctx context.Context, id X) ([]audit.Entry, error) {
+	return nil, models.ErrAuditNotEnabled
+}
+
+func (m *memoryRepository[T, X]) GetAsOf(ctx context.Context, id X, at time.Time) (*T, error) {
+	return nil, models.ErrVersioningNotEnabled
+}
+
+func (m *memoryRepository[T, X]) ListVersions(ctx context.Context, id X) ([]history.Version[T], error) {
+	return nil, models.ErrVersioningNotEnabled
+}
+
+func (m *memoryRepository[T, X]) Revert(ctx context.Context, id X, version uint64) (*T, error) {
+	return nil, models.ErrVersioningNotEnabled
+}
+
+func (m *memoryRepository[T, X]) scopedByTenant() bool {
+	return m.meta.tenancy
+}
+
+// ready fails when ctx is done or, under WithTenancy, carries no tenant.
+func (m *memoryRepository[T, X]) ready(ctx context.Context) error {
+	if err := dbError(ctx.Err()); err != nil {
+		return err
+	}
+	_, _, err := m.meta.tenant(ctx)
+	return err
+}
+
+// current returns a copy of the row with the given id, taking the read
+// lock.
+func (m *memoryRepository[T, X]) current(ctx context.Context, id X, state rowState) (T, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	row, _, err := m.find(ctx, id, state)
+	return row, err
+}
+
+// after runs the hooks of event on model once a write is stored, and undoes
+// the write when one fails.
+func (m *memoryRepository[T, X]) after(ctx context.Context, event HookEvent, model *T, undo func()) error {
+	if err := m.meta.hooks.run(ctx, event, model); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+// putBack returns a function storing row again as it is now.
+func (m *memoryRepository[T, X]) putBack(ctx context.Context, row T) func() {
+	key := m.key(ctx, &row)
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.rows[key] = row
+	}
+}
+
+// takeBack returns a function removing the newly inserted row.
+func (m *memoryRepository[T, X]) takeBack(ctx context.Context, row T) func() {
+	key := m.key(ctx, &row)
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.rows, key)
+	}
+}
+
+// undoLog collects how to undo the rows of a batch written so far.
+type undoLog []func()
+
+// run undoes the writes, newest first, and returns err.
+func (u undoLog) run(err error) error {
+	for i := len(u) - 1; i >= 0; i-- {
+		u[i]()
+	}
+	return err
+}
+
+// key returns the key of model in rows.
+func (m *memoryRepository[T, X]) key(ctx context.Context, model *T) string {
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return ""
+	}
+	id, _ := pk.ValueOf(ctx, reflect.ValueOf(model).Elem())
+	return fmt.Sprint(id)
+}
+
+// find returns the stored row with the given id and its key in rows.
+func (m *memoryRepository[T, X]) find(ctx context.Context, id X, state rowState) (T, string, error) {
+	key := fmt.Sprint(id)
+	row, ok := m.rows[key]
+	if !ok || !m.inState(ctx, row, state) {
+		var zero T
+		return zero, "", models.ErrNotFound
+	}
+	return row, key, nil
+}
+
+// inState reports whether row is in state and, under WithTenancy, belongs
+// to the tenant of ctx.
+func (m *memoryRepository[T, X]) inState(ctx context.Context, row T, state rowState) bool {
+	if ok, err := m.meta.inTenant(ctx, &row); err != nil || !ok {
+		return false
+	}
+	if state == stateAny {
+		return true
+	}
+	field, err := m.meta.deletedAtField()
+	if err != nil {
+		return state == stateLive
+	}
+	return m.deletedAt(ctx, field, row).Valid == (state == stateTrashed)
+}
+
+func (m *memoryRepository[T, X]) deletedAt(ctx context.Context, field *schema.Field, row T) gorm.DeletedAt {
+	deleted, _ := field.ReflectValueOf(ctx, reflect.ValueOf(&row).Elem()).Interface().(gorm.DeletedAt)
+	return deleted
+}
+
+// remove deletes the row stored under key, soft deleting it when T
+// supports it and the delete is not permanent.
+func (m *memoryRepository[T, X]) remove(ctx context.Context, key string, row T, permanently bool) {
+	field, err := m.meta.deletedAtField()
+	if permanently || err != nil {
+		delete(m.rows, key)
+		return
+	}
+	deleted := gorm.DeletedAt{Time: time.Now(), Valid: true}
+	field.ReflectValueOf(ctx, reflect.ValueOf(&row).Elem()).Set(reflect.ValueOf(deleted))
+	m.rows[key] = row
+}
+
+// insert stores a new row, giving it a primary key when it has none. The
+// caller holds the write lock.
+func (m *memoryRepository[T, X]) insert(ctx context.Context, model *T) error {
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(model).Elem()
+	if _, zero := pk.ValueOf(ctx, rv); zero {
+		var id interface{} = m.lastID + 1
+		if pk.IndirectFieldType.Kind() == reflect.String {
+			id = newUUID()
+		}
+		if err := pk.Set(ctx, rv, id); err != nil {
+			return err
+		}
+	}
+	if err := m.touch(ctx, rv, true); err != nil {
+		return err
+	}
+
+	id, _ := pk.ValueOf(ctx, rv)
+	key := fmt.Sprint(id)
+	if _, exists := m.rows[key]; exists {
+		return m.duplicate("", []*schema.Field{pk})
+	}
+	if err := m.checkUnique(ctx, m.rows, key, model); err != nil {
+		return err
+	}
+
+	if n, ok := toVersion(id); ok && n > m.lastID {
+		m.lastID = n
+	}
+	m.rows[key] = *model
+	return nil
+}
+
+// replace stores updated in place of the row under key, after checking its
+// unique columns and moving it when its primary key changed. The caller
+// holds the write lock.
+func (m *memoryRepository[T, X]) replace(ctx context.Context, key string, updated *T) error {
+	pk, err := m.meta.primaryField()
+	if err != nil {
+		return err
+	}
+	id, _ := pk.ValueOf(ctx, reflect.ValueOf(updated).Elem())
+	newKey := fmt.Sprint(id)
+	if _, exists := m.rows[newKey]; exists && newKey != key {
+		return m.duplicate("", []*schema.Field{pk})
+	}
+	if err := m.checkUnique(ctx, m.rows, key, updated); err != nil {
+		return err
+	}
+
+	delete(m.rows, key)
+	m.rows[newKey] = *updated
+	return nil
+}
+
+// touch sets the autoCreateTime and autoUpdateTime fields of a new row
+// that are zero, or the autoUpdateTime fields of an updated one, like GORM.
+func (m *memoryRepository[T, X]) touch(ctx context.Context, rv reflect.Value, created bool) error {
+	s, err := m.meta.schema()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, field := range s.Fields {
+		if created && (field.AutoCreateTime > 0 || field.AutoUpdateTime > 0) {
+			if _, zero := field.ValueOf(ctx, rv); !zero {
+				continue
+			}
+		} else if field.AutoUpdateTime == 0 {
+			continue
+		}
+		if err := field.Set(ctx, rv, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nonZeroFields returns the columns Updates writes for a struct: its
+// non-zero fields other than the primary key.
+func (m *memoryRepository[T, X]) nonZeroFields(ctx context.Context, source reflect.Value) ([]*schema.Field, error) {
+	s, err := m.meta.schema()
+	if err != nil {
+		return nil, err
+	}
+	var fields []*schema.Field
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.PrimaryKey {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, source); !zero {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// assign copies fields from source into row and refreshes its update time.
+func (m *memoryRepository[T, X]) assign(ctx context.Context, row *T, source reflect.Value, fields []*schema.Field) error {
+	rv := reflect.ValueOf(row).Elem()
+	for _, field := range fields {
+		field.ReflectValueOf(ctx, rv).Set(field.ReflectValueOf(ctx, source))
+	}
+	return m.touch(ctx, rv, false)
+}
+
+func (m *memoryRepository[T, X]) columns(names []string) ([]*schema.Field, error) {
+	fields := make([]*schema.Field, 0, len(names))
+	for _, name := range names {
+		field, err := m.meta.lookupColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func (m *memoryRepository[T, X]) version(ctx context.Context, vf *schema.Field, row T) (uint64, bool) {
+	v, _ := vf.ValueOf(ctx, reflect.ValueOf(&row).Elem())
+	return toVersion(v)
+}
+
+// checkVersion resolves the version a write must match, like versionCheck,
+// and fails with models.ErrConflict when the stored row has moved on.
+func (m *memoryRepository[T, X]) checkVersion(ctx context.Context, vf *schema.Field, model reflect.Value, existing T) (uint64, error) {
+	version := versionCheck(ctx, vf, model, reflect.ValueOf(&existing).Elem())
+	if current, _ := m.version(ctx, vf, existing); current != version {
+		return 0, models.ErrConflict
+	}
+	return version, nil
+}
+
+// checkUnique fails with a *models.DuplicateKeyError when model, to be
+// stored under key, has the same values as another row of rows in a unique
+// column or index. Rows with a null in it never conflict, as in SQL.
+func (m *memoryRepository[T, X]) checkUnique(ctx context.Context, rows map[string]T, key string, model *T) error {
+	s, err := m.meta.schema()
+	if err != nil {
+		return err
+	}
+
+	type unique struct {
+		name   string
+		fields []*schema.Field
+	}
+	var uniques []unique
+	for name, constraint := range s.ParseUniqueConstraints() {
+		uniques = append(uniques, unique{name, []*schema.Field{constraint.Field}})
+	}
+	for name, index := range s.ParseIndexes() {
+		if index.Class != "UNIQUE" {
+			continue
+		}
+		u := unique{name: name}
+		for _, option := range index.Fields {
+			if option.Field != nil {
+				u.fields = append(u.fields, option.Field)
+			}
+		}
+		uniques = append(uniques, u)
+	}
+	sort.Slice(uniques, func(i, j int) bool { return uniques[i].name < uniques[j].name })
+
+	rv := reflect.ValueOf(model).Elem()
+	for _, u := range uniques {
+		if hasNull(ctx, u.fields, rv) {
+			continue
+		}
+		for other, row := range rows {
+			if other != key && sameValues(ctx, u.fields, rv, reflect.ValueOf(&row).Elem()) {
+				return m.duplicate(u.name, u.fields)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository[T, X]) duplicate(constraint string, fields []*schema.Field) error {
+	dup := &models.DuplicateKeyError{Constraint: constraint, Err: gorm.ErrDuplicatedKey}
+	for _, field := range fields {
+		dup.Columns = append(dup.Columns, field.DBName)
+	}
+	m.meta.resolveDuplicateKey(dup)
+	return dup
+}
+
+// checkPreload rejects preloads that do not name an association of T, as
+// GORM does.
+func (m *memoryRepository[T, X]) checkPreload(preload string) error {
+	if preload == "" || preload == clause.Associations {
+		return nil
+	}
+	s, err := m.meta.schema()
+	if err != nil {
+		return err
+	}
+	name, _, _ := strings.Cut(preload, ".")
+	if _, ok := s.Relationships.Relations[name]; !ok {
+		return fmt.Errorf("%w: %s", models.ErrUnknownField, preload)
+	}
+	return nil
+}
+
+// output returns a copy of row with the associations that were not
+// preloaded left empty, as a database read would.
+func (m *memoryRepository[T, X]) output(ctx context.Context, row T, preload string) *T {
+	if preload == clause.Associations {
+		return &row
+	}
+	s, err := m.meta.schema()
+	if err != nil {
+		return &row
+	}
+	name, _, _ := strings.Cut(preload, ".")
+	rv := reflect.ValueOf(&row).Elem()
+	for relation, rel := range s.Relationships.Relations {
+		if relation != name {
+			field := rel.Field.ReflectValueOf(ctx, rv)
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+	return &row
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
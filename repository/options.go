@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type config struct {
+	hooks      interface{}
+	outbox     bool
+	audit      bool
+	versioning bool
+	tenancy    bool
+}
+
+type Option func(*config)
+
+// WithHooks runs the lifecycle hooks of hooks around the writes and reads
+// of the repository. Its model type must match the repository's.
+func WithHooks[T any](hooks *Hooks[T]) Option {
+	return func(cfg *config) {
+		cfg.hooks = hooks
+	}
+}
+
+// WithOutbox writes an outbox.Message in the transaction of every write,
+// batch writes, Upsert, Restore and Purge included, to be published by an
+// outbox.Dispatcher. The outbox table is created with outbox.Migrate.
+func WithOutbox() Option {
+	return func(cfg *config) {
+		cfg.outbox = true
+	}
+}
+
+// WithAudit records an audit.Entry, with the actor of the context and the
+// changed members, in the transaction of every write, batch writes, Upsert,
+// Restore and Purge included. The audit table is created with audit.Migrate.
+func WithAudit() Option {
+	return func(cfg *config) {
+		cfg.audit = true
+	}
+}
+
+// WithVersioning keeps every version of the entities: every write to an
+// existing row, batch writes, Upsert, Restore and Purge included, stores
+// the entity as it was before the change as a history.Snapshot, in the
+// same transaction. It enables GetAsOf,
+// ListVersions and Revert. The snapshot table is created with
+// history.Migrate.
+func WithVersioning() Option {
+	return func(cfg *config) {
+		cfg.versioning = true
+	}
+}
+
+// WithTenancy confines the repository to the tenant carried by the
+// context, set with tenancy.WithTenant: every read, update and delete only
+// sees the rows of that tenant, and creates stamp it on the row. The
+// tenant is stored in the tenant_id column, or the field tagged
+// `entitier:"tenant"`. Calls whose context has no tenant fail with
+// models.ErrTenantRequired.
+func WithTenancy() Option {
+	return func(cfg *config) {
+		cfg.tenancy = true
+	}
+}
+
+func newConfig(opts ...Option) config {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// hooksFor returns the hooks of cfg for T. Hooks registered for another
+// model are a programming error and panic, like a mismatched ID type would
+// fail to compile.
+func hooksFor[T any](cfg config) *Hooks[T] {
+	if cfg.hooks == nil {
+		return nil
+	}
+	hooks, ok := cfg.hooks.(*Hooks[T])
+	if !ok {
+		panic(fmt.Sprintf("repository: %T used with a repository of %v", cfg.hooks, reflect.TypeOf((*T)(nil)).Elem()))
+	}
+	return hooks
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"github.com/alvarotor/entitier-go/validation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Patch applies a merge patch or JSON patch to the JSON representation of
+// the entity with the given id and writes back every patched column, zero
+// values and nulls included. It returns the entity as stored afterwards.
+// BeforeUpdate hooks get the patched entity, and the columns they change
+// are written too; AfterUpdate hooks get the entity as stored. A patch that
+// changes nothing writes nothing and runs no AfterUpdate hooks.
+func (r *genericRepository[T, X]) Patch(ctx context.Context, id X, patch models.Patch) (*T, error) {
+	var patched *T
+	err := r.atomically(ctx, func(ctx context.Context) error {
+		before, err := r.snapshot(ctx, id)
+		if err != nil {
+			return err
+		}
+		var changed bool
+		if patched, changed, err = r.patch(ctx, id, patch); err != nil || !changed {
+			return err
+		}
+		if err := r.hooks.run(ctx, AfterUpdate, patched); err != nil {
+			return err
+		}
+		return r.record(ctx, outbox.OpUpdate, before, patched)
+	}, BeforeUpdate, AfterUpdate)
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// patch writes the columns patch changes and returns the entity as stored,
+// and whether it changed.
+func (r *genericRepository[T, X]) patch(ctx context.Context, id X, patch models.Patch) (*T, bool, error) {
+	pk, err := r.primaryField()
+	if err != nil {
+		return nil, false, err
+	}
+	byID := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}
+
+	var existing T
+	db := r.scoped(ctx)
+	result := db.Where(byID).First(&existing)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, false, models.ErrNotFound
+	}
+	if result.Error != nil {
+		return nil, false, dbError(result.Error)
+	}
+
+	amended, columns, err := r.applyPatch(ctx, &existing, patch)
+	if err != nil {
+		return nil, false, err
+	}
+	if columns, err = r.beforePatch(ctx, &existing, &amended, columns); err != nil {
+		return nil, false, err
+	}
+	if len(columns) == 0 {
+		return &existing, false, nil
+	}
+
+	vf, err := r.versionField()
+	if err != nil {
+		return nil, false, err
+	}
+	query := db.Model(&existing)
+	if vf != nil {
+		version := versionCheck(ctx, vf, reflect.Value{}, reflect.ValueOf(&existing).Elem())
+		if err := vf.Set(ctx, reflect.ValueOf(&amended).Elem(), version+1); err != nil {
+			return nil, false, err
+		}
+		columns = append(columns, vf.DBName)
+		query = whereVersion(query, vf, version)
+	}
+
+	result = query.Select(columns).Updates(&amended)
+	if result.Error != nil {
+		return nil, false, r.writeError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if vf != nil {
+			return nil, false, models.ErrConflict
+		}
+		return nil, false, models.ErrNotFound
+	}
+
+	var updated T
+	if err := db.Where(byID).First(&updated).Error; err != nil {
+		return nil, false, dbError(err)
+	}
+	return &updated, true, nil
+}
+
+// applyPatch applies patch to the JSON document of existing and returns the
+// entity it describes, with the columns it changes. The version column is
+// left to the caller.
+func (r *genericRepository[T, X]) applyPatch(ctx context.Context, existing *T, patch models.Patch) (T, []string, error) {
+	var amended T
+	original, err := json.Marshal(*existing)
+	if err != nil {
+		return amended, nil, err
+	}
+	doc, err := decodeDocument(original)
+	if err != nil {
+		return amended, nil, err
+	}
+
+	var touched, checked []string
+	switch patch.Type {
+	case models.MergePatchType:
+		doc, touched, err = applyMergePatch(doc, patch.Document)
+	case models.JSONPatchType:
+		doc, touched, checked, err = applyJSONPatch(doc, patch.Document)
+	default:
+		return amended, nil, fmt.Errorf("%w: %s", models.ErrUnsupportedPatchType, patch.Type)
+	}
+	if err != nil {
+		return amended, nil, err
+	}
+
+	vf, err := r.versionField()
+	if err != nil {
+		return amended, nil, err
+	}
+	fields, err := r.jsonFields()
+	if err != nil {
+		return amended, nil, err
+	}
+	for _, name := range checked {
+		if _, ok := fields[name]; !ok {
+			return amended, nil, fmt.Errorf("%w: %s", models.ErrUnknownField, name)
+		}
+	}
+	columns := make([]string, 0, len(touched)+1)
+	seen := make(map[string]bool, len(touched))
+	for _, name := range touched {
+		field, ok := fields[name]
+		if !ok {
+			return amended, nil, fmt.Errorf("%w: %s", models.ErrUnknownField, name)
+		}
+		if r.unpatchable(field, vf) {
+			return amended, nil, fmt.Errorf("%w: %s cannot be patched", models.ErrInvalidPatch, name)
+		}
+		if !seen[field.DBName] {
+			seen[field.DBName] = true
+			columns = append(columns, field.DBName)
+		}
+	}
+
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return amended, nil, err
+	}
+	if err := json.Unmarshal(patched, &amended); err != nil {
+		return amended, nil, fmt.Errorf("%w: %v", models.ErrInvalidPatch, err)
+	}
+	return amended, columns, nil
+}
+
+// unpatchable reports whether field is managed by the repository rather
+// than written by a patch: the primary key, the version, the tenant and the
+// soft delete time, which only Delete and Restore change.
+func (r *genericRepository[T, X]) unpatchable(field *schema.Field, vf *schema.Field) bool {
+	return field.PrimaryKey || field == vf || field.FieldType == deletedAtType || r.isTenantField(field.Name)
+}
+
+// beforePatch runs the BeforeUpdate hooks on amended, the entity a patch
+// of existing describes, and returns the columns of amended that differ
+// from existing, those the hooks changed included, once it is valid. The
+// fields the repository manages stay as patched.
+func (r *genericRepository[T, X]) beforePatch(ctx context.Context, existing *T, amended *T, columns []string) ([]string, error) {
+	if r.hooks.has(BeforeUpdate) {
+		patched := *amended
+		if err := r.hooks.run(ctx, BeforeUpdate, amended); err != nil {
+			return nil, err
+		}
+
+		s, err := r.schema()
+		if err != nil {
+			return nil, err
+		}
+		vf, err := r.versionField()
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			seen[column] = true
+		}
+		rv, before := reflect.ValueOf(amended).Elem(), reflect.ValueOf(&patched).Elem()
+		for _, field := range s.Fields {
+			if field.DBName == "" || seen[field.DBName] {
+				continue
+			}
+			if r.unpatchable(field, vf) {
+				field.ReflectValueOf(ctx, rv).Set(field.ReflectValueOf(ctx, before))
+				continue
+			}
+			if !reflect.DeepEqual(field.ReflectValueOf(ctx, rv).Interface(), field.ReflectValueOf(ctx, before).Interface()) {
+				seen[field.DBName] = true
+				columns = append(columns, field.DBName)
+			}
+		}
+	}
+
+	if columns = r.changedColumns(ctx, existing, amended, columns); len(columns) == 0 {
+		return nil, nil
+	}
+	if err := validation.Struct(ctx, amended); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// changedColumns returns the columns whose value in amended differs from
+// the one in existing. Values are compared as JSON too, which is how a
+// patch round-trips them.
+func (r *genericRepository[T, X]) changedColumns(ctx context.Context, existing *T, amended *T, columns []string) []string {
+	s, err := r.schema()
+	if err != nil {
+		return columns
+	}
+	ev, av := reflect.ValueOf(existing).Elem(), reflect.ValueOf(amended).Elem()
+	changed := columns[:0:0]
+	for _, column := range columns {
+		field := s.LookUpField(column)
+		if field == nil {
+			changed = append(changed, column)
+			continue
+		}
+		before, after := field.ReflectValueOf(ctx, ev).Interface(), field.ReflectValueOf(ctx, av).Interface()
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		b, berr := json.Marshal(before)
+		a, aerr := json.Marshal(after)
+		if berr != nil || aerr != nil || string(a) != string(b) {
+			changed = append(changed, column)
+		}
+	}
+	return changed
+}
+
+// jsonFields maps the JSON member names of T, as produced by encoding/json,
+// to their database columns.
+func (r *genericRepository[T, X]) jsonFields() (map[string]*schema.Field, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]*schema.Field, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.StructField.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields[name] = field
+	}
+	return fields, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+func (r *genericRepository[T, X]) applyOptions(query *gorm.DB, opts []models.QueryOption) (*gorm.DB, error) {
+	var orders []clause.OrderByColumn
+	sorted := map[string]bool{}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case models.Filter:
+			expr, err := r.filterExpr(o)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(expr)
+		case models.Sort:
+			field, err := r.lookupColumn(o.Field)
+			if err != nil {
+				return nil, err
+			}
+			if sorted[field.DBName] {
+				return nil, fmt.Errorf("%w: %s is sorted more than once", models.ErrInvalidSort, o.Field)
+			}
+			sorted[field.DBName] = true
+			orders = append(orders, clause.OrderByColumn{
+				Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+				Desc:   o.Desc,
+			})
+		default:
+			return nil, fmt.Errorf("unsupported query option %T", opt)
+		}
+	}
+
+	if len(orders) > 0 {
+		// Break ties on the primary key so that equal rows keep a stable order.
+		pk, err := r.primaryField()
+		if err != nil {
+			return nil, err
+		}
+		if !sorted[pk.DBName] {
+			orders = append(orders, clause.OrderByColumn{
+				Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName},
+			})
+		}
+		query = query.Clauses(clause.OrderBy{Columns: orders})
+	}
+
+	return query.Session(&gorm.Session{}), nil
+}
+
+func hasSort(opts []models.QueryOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(models.Sort); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *genericRepository[T, X]) filterExpr(f models.Filter) (clause.Expression, error) {
+	field, err := r.lookupColumn(f.Field)
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+	switch f.Operator {
+	case models.FilterNull:
+		isNull, err := strconv.ParseBool(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s[%s] expects true or false", models.ErrInvalidFilter, f.Field, f.Operator)
+		}
+		if isNull {
+			return clause.Eq{Column: column, Value: nil}, nil
+		}
+		return clause.Neq{Column: column, Value: nil}, nil
+	case models.FilterLike:
+		return clause.Like{Column: column, Value: f.Value}, nil
+	case models.FilterIn:
+		var values []interface{}
+		for _, raw := range strings.Split(f.Value, ",") {
+			v, err := convertFilterValue(field, raw)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	}
+
+	value, err := convertFilterValue(field, f.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Operator {
+	case "", models.FilterEq:
+		return clause.Eq{Column: column, Value: value}, nil
+	case models.FilterNe:
+		return clause.Neq{Column: column, Value: value}, nil
+	case models.FilterGt:
+		return clause.Gt{Column: column, Value: value}, nil
+	case models.FilterGte:
+		return clause.Gte{Column: column, Value: value}, nil
+	case models.FilterLt:
+		return clause.Lt{Column: column, Value: value}, nil
+	case models.FilterLte:
+		return clause.Lte{Column: column, Value: value}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown operator %s", models.ErrInvalidFilter, f.Operator)
+}
+
+func convertFilterValue(field *schema.Field, raw string) (interface{}, error) {
+	typ := field.IndirectFieldType
+	invalid := fmt.Errorf("%w: %q is not a valid value for %s", models.ErrInvalidFilter, raw, field.Name)
+
+	if typ == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, invalid
+		}
+		return t, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		return v, nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, invalid
+		}
+		return v, nil
+	}
+
+	return raw, nil
+}
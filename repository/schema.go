@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	schemaCache     sync.Map
+	primaryKeyCache sync.Map
+)
+
+func (r *genericRepository[T, X]) schema() (*schema.Schema, error) {
+	return schema.Parse(new(T), &schemaCache, r.DB.NamingStrategy)
+}
+
+func (r *genericRepository[T, X]) primaryField() (*schema.Field, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%w: %s has no primary key", models.ErrUnknownField, s.Name)
+	}
+	return s.PrioritizedPrimaryField, nil
+}
+
+// lookupColumn resolves a struct field name or column name of T to its
+// schema field, rejecting anything that is not a plain database column.
+func (r *genericRepository[T, X]) lookupColumn(name string) (*schema.Field, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	field := s.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("%w: %s", models.ErrUnknownField, name)
+	}
+	return field, nil
+}
+
+// SetPrimaryKey writes id into the primary key field of model, so a handler
+// can make the ID from the URL authoritative over the one in the body.
+func SetPrimaryKey[T any, X string | uint](model *T, id X) error {
+	s, err := schema.Parse(model, &primaryKeyCache, schema.NamingStrategy{})
+	if err != nil {
+		return err
+	}
+	if s.PrioritizedPrimaryField == nil {
+		return fmt.Errorf("%w: %s has no primary key", models.ErrUnknownField, s.Name)
+	}
+	if err := s.PrioritizedPrimaryField.Set(context.Background(), reflect.ValueOf(model).Elem(), id); err != nil {
+		return models.ErrIDTypeMismatch
+	}
+	return nil
+}
+
+// primaryKeyOf returns the primary key of model formatted like the IDs the
+// repository is called with, and false when it has none or it is zero.
+func primaryKeyOf[T any](model *T) (string, bool) {
+	s, err := schema.Parse(model, &primaryKeyCache, schema.NamingStrategy{})
+	if err != nil || s.PrioritizedPrimaryField == nil {
+		return "", false
+	}
+	value, zero := s.PrioritizedPrimaryField.ValueOf(context.Background(), reflect.ValueOf(model).Elem())
+	if zero {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+func (r *genericRepository[T, X]) deletedAtField() (*schema.Field, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range s.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field, nil
+		}
+	}
+	return nil, models.ErrSoftDeleteNotSupported
+}
+
+func (r *genericRepository[T, X]) trashed(ctx context.Context) (*gorm.DB, *schema.Field, error) {
+	field, err := r.deletedAtField()
+	if err != nil {
+		return nil, nil, err
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	return r.scoped(ctx).Unscoped().Model(new(T)).Where(clause.Neq{Column: column, Value: nil}), field, nil
+}
+
+func (r *genericRepository[T, X]) Restore(ctx context.Context, id X) error {
+	return r.atomically(ctx, func(ctx context.Context) error {
+		query, field, err := r.trashed(ctx)
+		if err != nil {
+			return err
+		}
+		pk, err := r.primaryField()
+		if err != nil {
+			return err
+		}
+		byID := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}
+
+		var before T
+		if r.tracked() {
+			if err := query.Session(&gorm.Session{}).Where(byID).Take(&before).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return models.ErrNotFound
+				}
+				return dbError(err)
+			}
+		}
+
+		result := query.Where(byID).Update(field.DBName, nil)
+		if result.Error != nil {
+			return dbError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrNotFound
+		}
+
+		if !r.tracked() {
+			return nil
+		}
+		after, err := r.get(ctx, id, "")
+		if err != nil {
+			return err
+		}
+		return r.record(ctx, outbox.OpRestore, &before, after)
+	})
+}
+
+func (r *genericRepository[T, X]) GetAllTrashed(ctx context.Context, opts ...models.QueryOption) ([]*T, error) {
+	var items []*T
+	query, _, err := r.trashed(ctx)
+	if err != nil {
+		return items, err
+	}
+	query, err = r.applyOptions(query, opts)
+	if err != nil {
+		return items, err
+	}
+
+	result := query.Find(&items)
+	if result.Error != nil {
+		return items, dbError(result.Error)
+	}
+	if len(items) == 0 {
+		return items, models.ErrNotFound
+	}
+
+	return items, nil
+}
+
+// Purge permanently removes rows that were soft deleted before olderThan and
+// returns how many were removed. When changes are tracked the rows are
+// removed one by one and each removal is recorded as a deletion.
+func (r *genericRepository[T, X]) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	var purged int64
+	err := r.atomically(ctx, func(ctx context.Context) error {
+		query, field, err := r.trashed(ctx)
+		if err != nil {
+			return err
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+		query = query.Where(clause.Lt{Column: column, Value: olderThan})
+
+		if !r.tracked() {
+			result := query.Delete(new(T))
+			if result.Error != nil {
+				return dbError(result.Error)
+			}
+			purged = result.RowsAffected
+			return nil
+		}
+
+		purged, err = r.eachRow(ctx, query, func(row *T, byID clause.Eq) error {
+			if err := r.scoped(ctx).Unscoped().Where(byID).Delete(new(T)).Error; err != nil {
+				return dbError(err)
+			}
+			return r.record(ctx, outbox.OpDelete, row, nil)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
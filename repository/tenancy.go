@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// TenantTag marks the field holding the tenant of a row, for models whose
+// tenant column is not tenant_id:
+//
+//	OrgID string `entitier:"tenant"`
+const TenantTag = "tenant"
+
+// TenantColumn is the tenant column used when no field is tagged.
+const TenantColumn = "tenant_id"
+
+func (r *genericRepository[T, X]) tenantField() (*schema.Field, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range s.Fields {
+		if field.Tag.Get("entitier") == TenantTag && field.DBName != "" {
+			return field, nil
+		}
+	}
+	if field := s.LookUpField(TenantColumn); field != nil && field.DBName != "" {
+		return field, nil
+	}
+	return nil, fmt.Errorf("%w: %s has no tenant column", models.ErrUnknownField, s.Name)
+}
+
+// tenantScoped is implemented by the repositories of this package, so that
+// decorators can tell whether what they read depends on the tenant of the
+// context.
+type tenantScoped interface {
+	scopedByTenant() bool
+}
+
+func (r *genericRepository[T, X]) scopedByTenant() bool {
+	return r.tenancy
+}
+
+// tenant returns the tenant field of T and the tenant of ctx, converted to
+// the type of that field. Both are nil without WithTenancy. A context
+// without a tenant, or with one the field cannot hold, is
+// models.ErrTenantRequired.
+func (r *genericRepository[T, X]) tenant(ctx context.Context) (*schema.Field, interface{}, error) {
+	if !r.tenancy {
+		return nil, nil, nil
+	}
+	field, err := r.tenantField()
+	if err != nil {
+		return nil, nil, err
+	}
+	tenant, ok := tenancy.TenantFrom(ctx)
+	if !ok {
+		return nil, nil, models.ErrTenantRequired
+	}
+
+	rv := reflect.ValueOf(new(T)).Elem()
+	if err := field.Set(ctx, rv, tenant); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid tenant %q", models.ErrTenantRequired, tenant)
+	}
+	value, _ := field.ValueOf(ctx, rv)
+	return field, value, nil
+}
+
+// scoped returns the database handle for queries on the table of T: conn
+// restricted to the rows of the tenant of ctx under WithTenancy. When ctx
+// has no tenant the handle carries the error, so every statement run with
+// it fails instead of touching the rows of all tenants.
+func (r *genericRepository[T, X]) scoped(ctx context.Context) *gorm.DB {
+	db := r.conn(ctx)
+	field, tenant, err := r.tenant(ctx)
+	if err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	if field == nil {
+		return db
+	}
+	return db.Where(tenantCondition(field, tenant)).Session(&gorm.Session{})
+}
+
+func tenantCondition(field *schema.Field, tenant interface{}) clause.Expression {
+	return clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+		Value:  tenant,
+	}
+}
+
+// stampTenant sets the tenant of ctx on model, so that rows are created and
+// updated within it whatever tenant the caller put in the model.
+func (r *genericRepository[T, X]) stampTenant(ctx context.Context, model *T) error {
+	field, tenant, err := r.tenant(ctx)
+	if err != nil || field == nil {
+		return err
+	}
+	return field.Set(ctx, reflect.ValueOf(model).Elem(), tenant)
+}
+
+// inTenant reports whether model, decoded from a snapshot rather than read
+// through scoped, belongs to the tenant of ctx.
+func (r *genericRepository[T, X]) inTenant(ctx context.Context, model *T) (bool, error) {
+	field, tenant, err := r.tenant(ctx)
+	if err != nil || field == nil {
+		return err == nil, err
+	}
+	value, _ := field.ValueOf(ctx, reflect.ValueOf(model).Elem())
+	return tenantString(value) == tenantString(tenant), nil
+}
+
+func tenantString(value interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// isTenantField reports whether name, a struct field or column of T, is the
+// tenant column, which writes may not change.
+func (r *genericRepository[T, X]) isTenantField(name string) bool {
+	if !r.tenancy {
+		return false
+	}
+	field, err := r.lookupColumn(name)
+	if err != nil {
+		return false
+	}
+	tenantField, err := r.tenantField()
+	return err == nil && field == tenantField
+}
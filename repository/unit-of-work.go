@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type afterCommitKey struct{}
+
+// commitCallbacks collects the functions to run once a transaction opened
+// by WithTx commits.
+type commitCallbacks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (c *commitCallbacks) add(fns ...func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fns = append(c.fns, fns...)
+}
+
+type UnitOfWork struct {
+	DB *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{
+		DB: db,
+	}
+}
+
+// WithTx runs fn inside a transaction carried by the context passed to it.
+// Every generic repository called with that context joins the transaction.
+// It commits when fn returns nil and rolls back otherwise; nested calls
+// use savepoints so an inner failure only undoes the inner work.
+func (u *UnitOfWork) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := u.DB
+	if tx, ok := TxFromContext(ctx); ok {
+		db = tx
+	}
+
+	callbacks := &commitCallbacks{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ContextWithTx(ctx, tx), afterCommitKey{}, callbacks))
+	})
+	if err != nil {
+		return dbError(err)
+	}
+
+	// A savepoint is only committed with the transaction around it.
+	if parent, ok := ctx.Value(afterCommitKey{}).(*commitCallbacks); ok {
+		parent.add(callbacks.fns...)
+		return nil
+	}
+	for _, fn := range callbacks.fns {
+		fn()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction opened by WithTx that ctx
+// carries has committed, and not at all when it rolls back. Without such a
+// transaction fn runs straight away.
+func AfterCommit(ctx context.Context, fn func()) {
+	if callbacks, ok := ctx.Value(afterCommitKey{}).(*commitCallbacks); ok {
+		callbacks.add(fn)
+		return
+	}
+	fn()
+}
+
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// TxRepository returns a generic repository built with opts and bound to the
+// transaction in ctx, or to db when ctx carries no transaction. It needs
+// the same options as the repository it stands in for, WithTenancy above
+// all.
+func TxRepository[T any, X string | uint](ctx context.Context, db *gorm.DB, opts ...Option) IGenericRepo[T, X] {
+	if tx, ok := TxFromContext(ctx); ok {
+		return NewGenericRepository[T, X](tx, opts...)
+	}
+	return NewGenericRepository[T, X](db, opts...)
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type upsertCreatedKey struct{}
+
+// WithUpsertCreated returns a context under which Upsert reports, through
+// created, whether it inserted the row rather than updated an existing one.
+func WithUpsertCreated(ctx context.Context, created *bool) context.Context {
+	return context.WithValue(ctx, upsertCreatedKey{}, created)
+}
+
+// reportUpsert tells the caller of Upsert whether the row was inserted.
+func reportUpsert(ctx context.Context, created bool) {
+	if report, ok := ctx.Value(upsertCreatedKey{}).(*bool); ok && report != nil {
+		*report = created
+	}
+}
+
+// Upsert inserts model or, when a row already exists with the same values in
+// conflictColumns (the primary key when empty), updates updateColumns of
+// that row (every column when empty). The version of an updated row is
+// bumped rather than replaced, and must match the one carried by model or
+// ctx when there is one. It returns the row as stored. The create hooks run
+// around the write, whether it inserts or updates.
+func (r *genericRepository[T, X]) Upsert(ctx context.Context, model T, conflictColumns []string, updateColumns []string) (T, error) {
+	stored := model
+	var created bool
+	write := func(ctx context.Context) error {
+		if err := r.hooks.run(ctx, BeforeCreate, &stored); err != nil {
+			return err
+		}
+		var before *T
+		var err error
+		if stored, before, err = r.upsert(ctx, stored, conflictColumns, updateColumns); err != nil {
+			return err
+		}
+		created = before == nil
+		if err := r.hooks.run(ctx, AfterCreate, &stored); err != nil {
+			return err
+		}
+		if before == nil {
+			return r.record(ctx, outbox.OpCreate, nil, &stored)
+		}
+		return r.record(ctx, outbox.OpUpdate, before, &stored)
+	}
+
+	// The tenant and the version of a conflicting row are checked before
+	// the write, in the same transaction.
+	var err error
+	if r.tenancy || VersionFieldName[T]() != "" {
+		err = NewUnitOfWork(r.DB).WithTx(ctx, write)
+	} else {
+		err = r.atomically(ctx, write, BeforeCreate, AfterCreate)
+	}
+	if err == nil {
+		reportUpsert(ctx, created)
+	}
+	return stored, err
+}
+
+// upsert writes model and returns the row as stored and, when it was
+// updated, as it was before.
+func (r *genericRepository[T, X]) upsert(ctx context.Context, model T, conflictColumns []string, updateColumns []string) (T, *T, error) {
+	vf, err := r.versionField()
+	if err != nil {
+		return model, nil, err
+	}
+	version, checkVersion := upsertVersion(ctx, vf, &model)
+	if err := r.prepareCreate(ctx, &model); err != nil {
+		return model, nil, err
+	}
+
+	onConflict := clause.OnConflict{}
+	if len(conflictColumns) == 0 {
+		pk, err := r.primaryField()
+		if err != nil {
+			return model, nil, err
+		}
+		conflictColumns = []string{pk.DBName}
+	}
+	conflictFields := make([]*schema.Field, 0, len(conflictColumns))
+	for _, name := range conflictColumns {
+		field, err := r.lookupColumn(name)
+		if err != nil {
+			return model, nil, err
+		}
+		conflictFields = append(conflictFields, field)
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
+	}
+
+	columns, err := r.upsertColumns(updateColumns, vf)
+	if err != nil {
+		return model, nil, err
+	}
+	onConflict.DoUpdates = clause.AssignmentColumns(columns)
+	if vf != nil {
+		onConflict.DoUpdates = append(onConflict.DoUpdates, clause.Assignment{
+			Column: clause.Column{Name: vf.DBName},
+			Value:  gorm.Expr("? + 1", clause.Column{Table: clause.CurrentTable, Name: vf.DBName}),
+		})
+	}
+	if len(onConflict.DoUpdates) == 0 {
+		onConflict.DoNothing = true
+	}
+
+	// Dialects such as MySQL drop the WHERE of a conflict update, so a
+	// conflicting row of another tenant, or of another version, is turned
+	// down before the write rather than taken over.
+	existing, err := r.findBy(ctx, r.conn(ctx), &model, conflictFields)
+	if errors.Is(err, models.ErrNotFound) {
+		existing = nil
+	} else if err != nil {
+		return model, nil, err
+	}
+	if existing != nil {
+		if ok, err := r.inTenant(ctx, existing); err != nil {
+			return model, nil, err
+		} else if !ok {
+			return model, nil, models.ErrDuplicateKey
+		}
+		if checkVersion {
+			v, _ := vf.ValueOf(ctx, reflect.ValueOf(existing).Elem())
+			if current, _ := toVersion(v); current != version {
+				return model, nil, models.ErrConflict
+			}
+		}
+	}
+
+	// The same conditions guard the update where the dialect keeps them.
+	// DO NOTHING takes none.
+	field, tenant, err := r.tenant(ctx)
+	if err != nil {
+		return model, nil, err
+	}
+	if !onConflict.DoNothing {
+		if field != nil {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, tenantCondition(field, tenant))
+		}
+		if checkVersion {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{
+				Column: clause.Column{Table: clause.CurrentTable, Name: vf.DBName},
+				Value:  version,
+			})
+		}
+	}
+
+	result := r.scoped(ctx).Clauses(onConflict).Create(&model)
+	if result.Error != nil {
+		return model, nil, r.writeError(result.Error)
+	}
+	if result.RowsAffected == 0 && len(onConflict.Where.Exprs) > 0 {
+		if checkVersion {
+			return model, nil, models.ErrConflict
+		}
+		return model, nil, models.ErrDuplicateKey
+	}
+
+	stored, err := r.findBy(ctx, r.scoped(ctx), &model, conflictFields)
+	if err != nil {
+		return model, nil, err
+	}
+	return *stored, existing, nil
+}
+
+// upsertVersion returns the version an existing row must have for Upsert to
+// update it: the one carried by model, otherwise the one in ctx.
+func upsertVersion[T any](ctx context.Context, vf *schema.Field, model *T) (uint64, bool) {
+	if vf == nil {
+		return 0, false
+	}
+	if v, zero := vf.ValueOf(ctx, reflect.ValueOf(model).Elem()); !zero {
+		return toVersion(v)
+	}
+	return ExpectedVersion(ctx)
+}
+
+// upsertColumns returns the columns Upsert assigns on a conflict: the ones
+// named by updateColumns, or the ones gorm would assign for UpdateAll. The
+// version and tenant columns are never among them.
+func (r *genericRepository[T, X]) upsertColumns(updateColumns []string, vf *schema.Field) ([]string, error) {
+	columns := []string{}
+	for _, name := range updateColumns {
+		field, err := r.lookupColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		if field != vf && !r.isTenantField(field.DBName) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if len(updateColumns) > 0 {
+		return columns, nil
+	}
+
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range s.Fields {
+		if field.DBName == "" || !field.Creatable || field.PrimaryKey || field == vf || field.AutoCreateTime > 0 || r.isTenantField(field.DBName) {
+			continue
+		}
+		if field.HasDefaultValue && field.DefaultValueInterface == nil && !strings.EqualFold(field.DefaultValue, "NULL") {
+			continue
+		}
+		columns = append(columns, field.DBName)
+	}
+	return columns, nil
+}
+
+// findBy loads the row of db, soft deleted or not, that has the values of
+// model in fields.
+func (r *genericRepository[T, X]) findBy(ctx context.Context, db *gorm.DB, model *T, fields []*schema.Field) (*T, error) {
+	rv := reflect.ValueOf(model).Elem()
+	query := db.Model(new(T)).Unscoped()
+	for _, field := range fields {
+		value, _ := field.ValueOf(ctx, rv)
+		query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+	}
+
+	var row T
+	if err := query.Take(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrNotFound
+		}
+		return nil, dbError(err)
+	}
+	return &row, nil
+}
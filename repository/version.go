@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/alvarotor/entitier-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// VersionTag marks the integer field used for optimistic locking:
+//
+//	Version uint `entitier:"version"`
+const VersionTag = "version"
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion attaches the version the caller last saw, so writes on
+// versioned models fail with models.ErrConflict if the row has moved on.
+func WithExpectedVersion(ctx context.Context, version uint64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+func ExpectedVersion(ctx context.Context) (uint64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	v, ok := ctx.Value(expectedVersionKey{}).(uint64)
+	return v, ok
+}
+
+// VersionFieldName returns the struct field T uses for optimistic locking,
+// either tagged with `entitier:"version"` or named by models.Versioned.
+func VersionFieldName[T any]() string {
+	if v, ok := any(new(T)).(models.Versioned); ok {
+		return v.VersionField()
+	}
+	if v, ok := any(*new(T)).(models.Versioned); ok {
+		return v.VersionField()
+	}
+
+	typ := reflect.TypeOf(new(T)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Tag.Get("entitier") == VersionTag {
+			return typ.Field(i).Name
+		}
+	}
+	return ""
+}
+
+// VersionOf returns the optimistic-locking version of model, if T has one.
+func VersionOf[T any](model *T) (uint64, bool) {
+	name := VersionFieldName[T]()
+	if name == "" || model == nil {
+		return 0, false
+	}
+	field := reflect.ValueOf(model).Elem().FieldByName(name)
+	if !field.IsValid() {
+		return 0, false
+	}
+	return toVersion(field.Interface())
+}
+
+func toVersion(value interface{}) (uint64, bool) {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	}
+	return 0, false
+}
+
+func (r *genericRepository[T, X]) versionField() (*schema.Field, error) {
+	name := VersionFieldName[T]()
+	if name == "" {
+		return nil, nil
+	}
+	return r.lookupColumn(name)
+}
+
+// versionCheck resolves the version a write must match: the one carried by
+// the model, then the one in ctx, and finally the version just read, which
+// still guards against concurrent writes between the read and the update.
+func versionCheck(ctx context.Context, field *schema.Field, model reflect.Value, current reflect.Value) uint64 {
+	if model.IsValid() {
+		if v, zero := field.ValueOf(ctx, model); !zero {
+			if version, ok := toVersion(v); ok {
+				return version
+			}
+		}
+	}
+	if version, ok := ExpectedVersion(ctx); ok {
+		return version
+	}
+	v, _ := field.ValueOf(ctx, current)
+	version, _ := toVersion(v)
+	return version
+}
+
+func whereVersion(db *gorm.DB, field *schema.Field, version uint64) *gorm.DB {
+	return db.Where(clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+		Value:  version,
+	})
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/outbox"
+	"github.com/alvarotor/entitier-go/validation"
+	"gorm.io/gorm"
+)
+
+// GetAsOf returns the entity with the given id as it was at the given
+// time, from its version history. It needs WithVersioning. Entities that
+// did not exist yet, or were deleted, at that time are models.ErrNotFound;
+// models with a CreatedAt field are checked against it.
+func (r *genericRepository[T, X]) GetAsOf(ctx context.Context, id X, at time.Time) (*T, error) {
+	if !r.versioning {
+		return nil, models.ErrVersioningNotEnabled
+	}
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := history.AsOf(ctx, r.conn(ctx), s.Name, fmt.Sprint(id), at)
+	if err != nil {
+		return nil, dbError(err)
+	}
+
+	var model *T
+	if snapshot != nil {
+		model = new(T)
+		if err := json.Unmarshal(snapshot.Data, model); err != nil {
+			return nil, err
+		}
+		if ok, err := r.inTenant(ctx, model); err != nil || !ok {
+			if err == nil {
+				err = models.ErrNotFound
+			}
+			return nil, err
+		}
+	} else if model, err = r.get(ctx, id, ""); err != nil {
+		return nil, err
+	}
+
+	if !r.existedAt(ctx, model, at) {
+		return nil, models.ErrNotFound
+	}
+	return model, nil
+}
+
+// existedAt reports whether model, as it was at the given time, was a live
+// entity: created by then, when T has a CreatedAt field, and not soft
+// deleted.
+func (r *genericRepository[T, X]) existedAt(ctx context.Context, model *T, at time.Time) bool {
+	s, err := r.schema()
+	if err != nil {
+		return true
+	}
+	rv := reflect.ValueOf(model).Elem()
+	if field := s.LookUpField("CreatedAt"); field != nil {
+		if v := reflect.Indirect(field.ReflectValueOf(ctx, rv)); v.IsValid() {
+			if created, ok := v.Interface().(time.Time); ok && created.After(at) {
+				return false
+			}
+		}
+	}
+	if field, err := r.deletedAtField(); err == nil {
+		if deleted, ok := field.ReflectValueOf(ctx, rv).Interface().(gorm.DeletedAt); ok && deleted.Valid {
+			return false
+		}
+	}
+	return true
+}
+
+// ListVersions returns the previous versions of the entity with the given
+// id, oldest first. The current state is not among them. It needs
+// WithVersioning and keeps working after the entity is deleted. Under
+// WithTenancy only versions of the tenant of ctx are returned.
+func (r *genericRepository[T, X]) ListVersions(ctx context.Context, id X) ([]history.Version[T], error) {
+	if !r.versioning {
+		return nil, models.ErrVersioningNotEnabled
+	}
+	if _, _, err := r.tenant(ctx); err != nil {
+		return nil, err
+	}
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := history.List(ctx, r.conn(ctx), s.Name, fmt.Sprint(id))
+	if err != nil {
+		return nil, dbError(err)
+	}
+	versions := make([]history.Version[T], 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		version, err := history.Decode[T](snapshot)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := r.inTenant(ctx, &version.Entity)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// Revert writes a previous version of the entity back, as an update that
+// is itself versioned, and returns the entity as stored afterwards. A soft
+// deleted entity is restored and a permanently deleted one created again.
+// The update honours optimistic locking like Update does and runs the
+// update hooks, or the create hooks when the entity is created again.
+func (r *genericRepository[T, X]) Revert(ctx context.Context, id X, version uint64) (*T, error) {
+	if !r.versioning {
+		return nil, models.ErrVersioningNotEnabled
+	}
+	if _, _, err := r.tenant(ctx); err != nil {
+		return nil, err
+	}
+
+	var reverted *T
+	err := NewUnitOfWork(r.DB).WithTx(ctx, func(ctx context.Context) error {
+		s, err := r.schema()
+		if err != nil {
+			return err
+		}
+		snapshot, err := history.Find(ctx, r.conn(ctx), s.Name, fmt.Sprint(id), version)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrVersionNotFound
+		}
+		if err != nil {
+			return dbError(err)
+		}
+
+		var amended T
+		if err := json.Unmarshal(snapshot.Data, &amended); err != nil {
+			return err
+		}
+
+		if ok, err := r.inTenant(ctx, &amended); err != nil || !ok {
+			if err == nil {
+				err = models.ErrVersionNotFound
+			}
+			return err
+		}
+
+		current, err := r.find(r.scoped(ctx).Unscoped(), id)
+		if errors.Is(err, models.ErrNotFound) {
+			if err := r.hooks.run(ctx, BeforeCreate, &amended); err != nil {
+				return err
+			}
+			if err := validation.Struct(ctx, &amended); err != nil {
+				return err
+			}
+			if err := r.scoped(ctx).Create(&amended).Error; err != nil {
+				return r.writeError(err)
+			}
+			reverted = &amended
+			if err := r.hooks.run(ctx, AfterCreate, reverted); err != nil {
+				return err
+			}
+			return r.record(ctx, outbox.OpCreate, nil, reverted)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := r.hooks.run(ctx, BeforeUpdate, &amended); err != nil {
+			return err
+		}
+		if reverted, err = r.revert(ctx, id, current, amended); err != nil {
+			return err
+		}
+		if err := r.hooks.run(ctx, AfterUpdate, reverted); err != nil {
+			return err
+		}
+		return r.record(ctx, outbox.OpUpdate, current, reverted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
+
+// revert overwrites every column of current that is part of the JSON
+// document of T with the value in amended, zero values and nulls included.
+func (r *genericRepository[T, X]) revert(ctx context.Context, id X, current *T, amended T) (*T, error) {
+	if err := validation.Struct(ctx, &amended); err != nil {
+		return nil, err
+	}
+
+	pk, err := r.primaryField()
+	if err != nil {
+		return nil, err
+	}
+	vf, err := r.versionField()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := r.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field == pk || field == vf || seen[field.DBName] {
+			continue
+		}
+		seen[field.DBName] = true
+		columns = append(columns, field.DBName)
+	}
+
+	// Updates writes the new values back into its model, and current is
+	// still needed as the version being superseded.
+	existing := *current
+	query := r.scoped(ctx).Unscoped().Model(&existing)
+	if vf != nil {
+		version := versionCheck(ctx, vf, reflect.Value{}, reflect.ValueOf(current).Elem())
+		if err := vf.Set(ctx, reflect.ValueOf(&amended).Elem(), version+1); err != nil {
+			return nil, err
+		}
+		columns = append(columns, vf.DBName)
+		query = whereVersion(query, vf, version)
+	}
+
+	result := query.Select(columns).Updates(&amended)
+	if result.Error != nil {
+		return nil, r.writeError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if vf != nil {
+			return nil, models.ErrConflict
+		}
+		return nil, models.ErrNotFound
+	}
+
+	return r.find(r.scoped(ctx).Unscoped(), id)
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/logger"
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/repository"
+)
+
+type genericService[T any, X string | uint] struct {
+	repo   repository.IGenericRepo[T, X]
+	before map[Operation][]Hook[T, X]
+	after  map[Operation][]Hook[T, X]
+	log    logger.Logger
+}
+
+func NewGenericService[T any, X string | uint](repo repository.IGenericRepo[T, X], opts ...Option[T, X]) IGenericService[T, X] {
+	s := &genericService[T, X]{
+		repo:   repo,
+		before: map[Operation][]Hook[T, X]{},
+		after:  map[Operation][]Hook[T, X]{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *genericService[T, X]) Create(ctx context.Context, model T) (T, error) {
+	call := &Call[T, X]{Op: OpCreate, Entity: &model}
+	if err := runHooks(ctx, s.before[OpCreate], call); err != nil {
+		return model, err
+	}
+
+	created, err := s.repo.Create(ctx, model)
+	if err != nil {
+		return created, err
+	}
+
+	call.Entity = &created
+	return created, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) GetAll(ctx context.Context, opts ...models.QueryOption) ([]*T, error) {
+	call := &Call[T, X]{Op: OpGetAll, Options: opts}
+	if err := runHooks(ctx, s.before[OpGetAll], call); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetAll(ctx, call.Options...)
+	if err != nil {
+		return items, err
+	}
+
+	call.List = items
+	return items, runHooks(ctx, s.after[OpGetAll], call)
+}
+
+func (s *genericService[T, X]) GetAllPaged(ctx context.Context, page int, pageSize int, opts ...models.QueryOption) ([]*T, int64, error) {
+	call := &Call[T, X]{Op: OpGetAllPaged, Options: opts}
+	if err := runHooks(ctx, s.before[OpGetAllPaged], call); err != nil {
+		return nil, 0, err
+	}
+
+	items, total, err := s.repo.GetAllPaged(ctx, page, pageSize, call.Options...)
+	if err != nil {
+		return items, total, err
+	}
+
+	call.List = items
+	return items, total, runHooks(ctx, s.after[OpGetAllPaged], call)
+}
+
+func (s *genericService[T, X]) GetAllCursor(ctx context.Context, cursor string, limit int, sortBy string, opts ...models.QueryOption) ([]*T, string, error) {
+	call := &Call[T, X]{Op: OpGetAllCursor, Options: opts}
+	if err := runHooks(ctx, s.before[OpGetAllCursor], call); err != nil {
+		return nil, "", err
+	}
+
+	items, next, err := s.repo.GetAllCursor(ctx, cursor, limit, sortBy, call.Options...)
+	if err != nil {
+		return items, next, err
+	}
+
+	call.List = items
+	return items, next, runHooks(ctx, s.after[OpGetAllCursor], call)
+}
+
+func (s *genericService[T, X]) Get(ctx context.Context, id X, preload string) (*T, error) {
+	call := &Call[T, X]{Op: OpGet, ID: id, Preload: preload}
+	if err := runHooks(ctx, s.before[OpGet], call); err != nil {
+		return nil, err
+	}
+
+	model, err := s.repo.Get(ctx, call.ID, call.Preload)
+	if err != nil {
+		return model, err
+	}
+
+	call.Entity = model
+	return model, runHooks(ctx, s.after[OpGet], call)
+}
+
+func (s *genericService[T, X]) Update(ctx context.Context, id X, amended T) error {
+	call := &Call[T, X]{Op: OpUpdate, ID: id, Entity: &amended}
+	if err := runHooks(ctx, s.before[OpUpdate], call); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, call.ID, amended); err != nil {
+		return err
+	}
+
+	return s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) Delete(ctx context.Context, id X, permanently bool) error {
+	call := &Call[T, X]{Op: OpDelete, ID: id, Permanently: permanently}
+	if err := runHooks(ctx, s.before[OpDelete], call); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, call.ID, call.Permanently); err != nil {
+		return err
+	}
+
+	return s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) UpdateField(ctx context.Context, id X, field string, amended interface{}) error {
+	call := &Call[T, X]{Op: OpUpdateField, ID: id, Field: field, Value: amended}
+	if err := runHooks(ctx, s.before[OpUpdateField], call); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateField(ctx, call.ID, call.Field, call.Value); err != nil {
+		return err
+	}
+
+	return s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) Restore(ctx context.Context, id X) error {
+	call := &Call[T, X]{Op: OpRestore, ID: id}
+	if err := runHooks(ctx, s.before[OpRestore], call); err != nil {
+		return err
+	}
+
+	if err := s.repo.Restore(ctx, call.ID); err != nil {
+		return err
+	}
+
+	return s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) GetAllTrashed(ctx context.Context, opts ...models.QueryOption) ([]*T, error) {
+	call := &Call[T, X]{Op: OpGetAllTrashed, Options: opts}
+	if err := runHooks(ctx, s.before[OpGetAllTrashed], call); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetAllTrashed(ctx, call.Options...)
+	if err != nil {
+		return items, err
+	}
+
+	call.List = items
+	return items, runHooks(ctx, s.after[OpGetAllTrashed], call)
+}
+
+func (s *genericService[T, X]) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	call := &Call[T, X]{Op: OpPurge, At: olderThan}
+	if err := runHooks(ctx, s.before[OpPurge], call); err != nil {
+		return 0, err
+	}
+
+	purged, err := s.repo.Purge(ctx, call.At)
+	if err != nil {
+		return purged, err
+	}
+
+	call.Affected = purged
+	return purged, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) CreateMany(ctx context.Context, items []T, batchSize int) ([]models.BatchResult[T], error) {
+	call := &Call[T, X]{Op: OpCreateMany, Items: items}
+	if err := runHooks(ctx, s.before[OpCreateMany], call); err != nil {
+		return nil, err
+	}
+
+	results, err := s.repo.CreateMany(ctx, call.Items, batchSize)
+	if err != nil {
+		return results, err
+	}
+
+	call.Results = results
+	return results, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) UpdateMany(ctx context.Context, amended T, ids []X, opts ...models.QueryOption) (int64, error) {
+	call := &Call[T, X]{Op: OpUpdateMany, Entity: &amended, IDs: ids, Options: opts}
+	if err := runHooks(ctx, s.before[OpUpdateMany], call); err != nil {
+		return 0, err
+	}
+
+	updated, err := s.repo.UpdateMany(ctx, amended, call.IDs, call.Options...)
+	if err != nil {
+		return updated, err
+	}
+
+	call.Affected = updated
+	return updated, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) DeleteMany(ctx context.Context, ids []X, permanently bool, opts ...models.QueryOption) (int64, error) {
+	call := &Call[T, X]{Op: OpDeleteMany, IDs: ids, Permanently: permanently, Options: opts}
+	if err := runHooks(ctx, s.before[OpDeleteMany], call); err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.repo.DeleteMany(ctx, call.IDs, call.Permanently, call.Options...)
+	if err != nil {
+		return deleted, err
+	}
+
+	call.Affected = deleted
+	return deleted, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) Upsert(ctx context.Context, model T, conflictColumns []string, updateColumns []string) (T, error) {
+	call := &Call[T, X]{Op: OpUpsert, Entity: &model}
+	if err := runHooks(ctx, s.before[OpUpsert], call); err != nil {
+		return model, err
+	}
+
+	stored, err := s.repo.Upsert(ctx, model, conflictColumns, updateColumns)
+	if err != nil {
+		return stored, err
+	}
+
+	call.Entity = &stored
+	return stored, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) Patch(ctx context.Context, id X, patch models.Patch) (*T, error) {
+	call := &Call[T, X]{Op: OpPatch, ID: id, Patch: patch}
+	if err := runHooks(ctx, s.before[OpPatch], call); err != nil {
+		return nil, err
+	}
+
+	patched, err := s.repo.Patch(ctx, call.ID, call.Patch)
+	if err != nil {
+		return patched, err
+	}
+
+	call.Entity = patched
+	return patched, s.afterWrite(ctx, call)
+}
+
+func (s *genericService[T, X]) History(ctx context.Context, id X) ([]audit.Entry, error) {
+	call := &Call[T, X]{Op: OpHistory, ID: id}
+	if err := runHooks(ctx, s.before[OpHistory], call); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.History(ctx, call.ID)
+	if err != nil {
+		return entries, err
+	}
+
+	call.Entries = entries
+	return entries, runHooks(ctx, s.after[OpHistory], call)
+}
+
+func (s *genericService[T, X]) GetAsOf(ctx context.Context, id X, at time.Time) (*T, error) {
+	call := &Call[T, X]{Op: OpGetAsOf, ID: id, At: at}
+	if err := runHooks(ctx, s.before[OpGetAsOf], call); err != nil {
+		return nil, err
+	}
+
+	model, err := s.repo.GetAsOf(ctx, call.ID, call.At)
+	if err != nil {
+		return model, err
+	}
+
+	call.Entity = model
+	return model, runHooks(ctx, s.after[OpGetAsOf], call)
+}
+
+func (s *genericService[T, X]) ListVersions(ctx context.Context, id X) ([]history.Version[T], error) {
+	call := &Call[T, X]{Op: OpListVersions, ID: id}
+	if err := runHooks(ctx, s.before[OpListVersions], call); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.ListVersions(ctx, call.ID)
+	if err != nil {
+		return versions, err
+	}
+
+	call.Versions = versions
+	return versions, runHooks(ctx, s.after[OpListVersions], call)
+}
+
+func (s *genericService[T, X]) Revert(ctx context.Context, id X, version uint64) (*T, error) {
+	call := &Call[T, X]{Op: OpRevert, ID: id, Version: version}
+	if err := runHooks(ctx, s.before[OpRevert], call); err != nil {
+		return nil, err
+	}
+
+	reverted, err := s.repo.Revert(ctx, call.ID, call.Version)
+	if err != nil {
+		return reverted, err
+	}
+
+	call.Entity = reverted
+	return reverted, s.afterWrite(ctx, call)
+}
+
+// afterWrite runs the after hooks of a write that has already been made.
+// With a logger their error is logged rather than returned, as the change
+// is stored and failing the call would make the client retry it.
+func (s *genericService[T, X]) afterWrite(ctx context.Context, call *Call[T, X]) error {
+	err := runHooks(ctx, s.after[call.Op], call)
+	if err == nil || s.log == nil {
+		return err
+	}
+	s.log.Error(string(call.Op), err.Error())
+	return nil
+}
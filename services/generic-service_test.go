@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/mocks"
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/alvarotor/entitier-go/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+
+func TestGenericService_DelegatesToRepository(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	svc := NewGenericService[mocks.TestModel, uint](repo)
+
+	model := mocks.TestModel{Email: "a@example.com"}
+	repo.On("Create", ctx, model).Return(mocks.TestModel{ID: 1, Email: "a@example.com"}, nil)
+	repo.On("Get", ctx, uint(1), "Orders").Return(&mocks.TestModel{ID: 1}, nil)
+	repo.On("Delete", ctx, uint(1), true).Return(models.ErrNotFound)
+
+	created, err := svc.Create(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), created.ID)
+
+	fetched, err := svc.Get(ctx, 1, "Orders")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), fetched.ID)
+
+	err = svc.Delete(ctx, 1, true)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	repo.AssertExpectations(t)
+}
+
+func TestGenericService_Hooks(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+
+	var order []string
+	svc := NewGenericService(repo,
+		Before(OpCreate, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			order = append(order, "normalise")
+			call.Entity.Email = strings.ToLower(call.Entity.Email)
+			return nil
+		}, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			order = append(order, "before")
+			return nil
+		}),
+		After(OpCreate, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			order = append(order, "after")
+			assert.Equal(t, uint(7), call.Entity.ID)
+			return nil
+		}),
+	)
+
+	repo.On("Create", ctx, mocks.TestModel{Email: "a@example.com"}).Return(mocks.TestModel{ID: 7, Email: "a@example.com"}, nil)
+
+	created, err := svc.Create(ctx, mocks.TestModel{Email: "A@Example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, mocks.TestModel{ID: 7, Email: "a@example.com"}, created)
+	assert.Equal(t, []string{"normalise", "before", "after"}, order)
+}
+
+func TestGenericService_BeforeHookAborts(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	denied := errors.New("denied")
+	afterCalled := false
+
+	svc := NewGenericService(repo,
+		Before(OpUpdate, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			return denied
+		}),
+		After(OpUpdate, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			afterCalled = true
+			return nil
+		}),
+	)
+
+	err := svc.Update(ctx, 1, mocks.TestModel{Email: "a@example.com"})
+	assert.ErrorIs(t, err, denied)
+	assert.False(t, afterCalled)
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestGenericService_AfterHookSkippedOnError(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	afterCalled := false
+
+	svc := NewGenericService(repo,
+		After(OpPatch, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			afterCalled = true
+			return nil
+		}),
+	)
+
+	patch := models.Patch{Type: models.MergePatchType, Document: []byte(`{}`)}
+	repo.On("Patch", ctx, uint(3), patch).Return(nil, models.ErrNotFound)
+
+	_, err := svc.Patch(ctx, 3, patch)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.False(t, afterCalled)
+}
+
+func TestGenericService_AfterHookError(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	log := &mocks.Logger{}
+	failed := errors.New("publish failed")
+
+	svc := NewGenericService(repo,
+		WithLogger[mocks.TestModel, uint](log),
+		After(OpDeleteMany, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			assert.Equal(t, []uint{1, 2}, call.IDs)
+			assert.Equal(t, int64(2), call.Affected)
+			return failed
+		}),
+		After(OpGet, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			return failed
+		}),
+	)
+
+	repo.On("DeleteMany", ctx, []uint{1, 2}, false).Return(int64(2), nil)
+	repo.On("Get", ctx, uint(1), "").Return(&mocks.TestModel{ID: 1}, nil)
+	log.On("Error", "delete_many", "publish failed").Return()
+
+	// The rows are deleted, so the error of the write is only logged.
+	deleted, err := svc.DeleteMany(ctx, []uint{1, 2}, false)
+	assert.Equal(t, int64(2), deleted)
+	assert.NoError(t, err)
+	log.AssertExpectations(t)
+
+	_, err = svc.Get(ctx, 1, "")
+	assert.ErrorIs(t, err, failed)
+
+	// Without a logger the error of the write is returned.
+	svc = NewGenericService(repo,
+		After(OpDeleteMany, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			return failed
+		}),
+	)
+	deleted, err = svc.DeleteMany(ctx, []uint{1, 2}, false)
+	assert.Equal(t, int64(2), deleted)
+	assert.ErrorIs(t, err, failed)
+}
+
+func TestGenericService_BeforeHookArguments(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	readOnly := errors.New("read only")
+	cutOff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	patch := models.Patch{Type: models.MergePatchType, Document: []byte(`{"Email":"b@example.com"}`)}
+
+	svc := NewGenericService(repo,
+		Before(OpGet, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			assert.Equal(t, "Orders", call.Preload)
+			call.Preload = ""
+			return nil
+		}),
+		Before(OpUpdateField, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			if call.Field == "id" {
+				return readOnly
+			}
+			call.Value = strings.ToLower(call.Value.(string))
+			return nil
+		}),
+		Before(OpDelete, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			assert.True(t, call.Permanently)
+			call.Permanently = false
+			return nil
+		}),
+		Before(OpPurge, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			call.At = cutOff
+			return nil
+		}),
+		Before(OpPatch, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			assert.Equal(t, `{"email":"B@Example.com"}`, string(call.Patch.Document))
+			call.Patch = patch
+			return nil
+		}),
+	)
+
+	repo.On("Get", ctx, uint(1), "").Return(&mocks.TestModel{ID: 1}, nil)
+	repo.On("UpdateField", ctx, uint(1), "email", "a@example.com").Return(nil)
+	repo.On("Delete", ctx, uint(1), false).Return(nil)
+	repo.On("Purge", ctx, cutOff).Return(int64(0), nil)
+	repo.On("Patch", ctx, uint(1), patch).Return(&mocks.TestModel{ID: 1, Email: "b@example.com"}, nil)
+
+	_, err := svc.Get(ctx, 1, "Orders")
+	assert.NoError(t, err)
+	assert.NoError(t, svc.UpdateField(ctx, 1, "email", "A@Example.com"))
+	assert.ErrorIs(t, svc.UpdateField(ctx, 1, "id", 2), readOnly)
+	assert.NoError(t, svc.Delete(ctx, 1, true))
+	_, err = svc.Purge(ctx, time.Now())
+	assert.NoError(t, err)
+	_, err = svc.Patch(ctx, 1, models.Patch{Type: models.MergePatchType, Document: []byte(`{"email":"B@Example.com"}`)})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestGenericService_WithRepository(t *testing.T) {
+	db := mocks.SetupGORMSqlite(t, &mocks.TestModel{})
+	svc := NewGenericService(repository.NewGenericRepository[mocks.TestModel, uint](db),
+		After(OpGetAll, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			for _, item := range call.List {
+				item.Email = strings.ToUpper(item.Email)
+			}
+			return nil
+		}),
+	)
+
+	_, err := svc.Create(ctx, mocks.TestModel{Email: "a@example.com"})
+	assert.NoError(t, err)
+
+	items, err := svc.GetAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "A@EXAMPLE.COM", items[0].Email)
+	}
+}
+
+func TestGenericService_Versioning(t *testing.T) {
+	repo := new(mocks.IGenericRepo[mocks.TestModel, uint])
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var seen []Operation
+	record := func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+		seen = append(seen, call.Op)
+		return nil
+	}
+	svc := NewGenericService(repo,
+		Before(OpRevert, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			call.Version = 1
+			return nil
+		}),
+		After(OpGetAsOf, record),
+		After(OpListVersions, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			assert.Len(t, call.Versions, 1)
+			return record(ctx, call)
+		}),
+		After(OpRevert, func(ctx context.Context, call *Call[mocks.TestModel, uint]) error {
+			assert.Equal(t, "a@example.com", call.Entity.Email)
+			return record(ctx, call)
+		}),
+	)
+
+	repo.On("GetAsOf", ctx, uint(1), at).Return(&mocks.TestModel{ID: 1}, nil)
+	repo.On("ListVersions", ctx, uint(1)).Return([]history.Version[mocks.TestModel]{{Version: 1}}, nil)
+	repo.On("Revert", ctx, uint(1), uint64(1)).Return(&mocks.TestModel{ID: 1, Email: "a@example.com"}, nil)
+
+	_, err := svc.GetAsOf(ctx, 1, at)
+	assert.NoError(t, err)
+	_, err = svc.ListVersions(ctx, 1)
+	assert.NoError(t, err)
+	_, err = svc.Revert(ctx, 1, 5)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Operation{OpGetAsOf, OpListVersions, OpRevert}, seen)
+	repo.AssertExpectations(t)
+}
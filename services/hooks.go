@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/alvarotor/entitier-go/audit"
+	"github.com/alvarotor/entitier-go/history"
+	"github.com/alvarotor/entitier-go/logger"
+	"github.com/alvarotor/entitier-go/models"
+)
+
+// Operation identifies the service method a hook runs for.
+type Operation string
+
+const (
+	OpCreate        Operation = "create"
+	OpGetAll        Operation = "get_all"
+	OpGetAllPaged   Operation = "get_all_paged"
+	OpGetAllCursor  Operation = "get_all_cursor"
+	OpGet           Operation = "get"
+	OpUpdate        Operation = "update"
+	OpDelete        Operation = "delete"
+	OpUpdateField   Operation = "update_field"
+	OpRestore       Operation = "restore"
+	OpGetAllTrashed Operation = "get_all_trashed"
+	OpPurge         Operation = "purge"
+	OpCreateMany    Operation = "create_many"
+	OpUpdateMany    Operation = "update_many"
+	OpDeleteMany    Operation = "delete_many"
+	OpUpsert        Operation = "upsert"
+	OpPatch         Operation = "patch"
+	OpHistory       Operation = "history"
+	OpGetAsOf       Operation = "get_as_of"
+	OpListVersions  Operation = "list_versions"
+	OpRevert        Operation = "revert"
+)
+
+// Call describes one service call to its hooks. Before hooks see the
+// arguments and may change them; after hooks also see the outcome.
+type Call[T any, X string | uint] struct {
+	Op Operation
+	// ID is the entity of Get, Update, Delete, UpdateField, Restore, Patch,
+	// History and the versioning methods, and IDs the rows of UpdateMany and
+	// DeleteMany.
+	ID  X
+	IDs []X
+	// Field and Value are the column UpdateField sets and its new value.
+	Field string
+	Value interface{}
+	// Permanently is the flag of Delete and DeleteMany, Preload the
+	// association Get loads and Patch the document Patch applies.
+	Permanently bool
+	Preload     string
+	Patch       models.Patch
+	// At is the time of GetAsOf and the cut-off of Purge, and Version the
+	// version Revert writes back.
+	At      time.Time
+	Version uint64
+	// Entity is the model passed to Create, Update, Upsert and UpdateMany.
+	// After Create, Get, Upsert, Patch, GetAsOf and Revert it is the entity
+	// returned.
+	Entity *T
+	// Items are the models passed to CreateMany.
+	Items   []T
+	Options []models.QueryOption
+	// List holds the entities returned by the GetAll methods, Results the
+	// outcome of CreateMany, Affected the rows changed by UpdateMany,
+	// DeleteMany and Purge, Entries the audit entries of History and
+	// Versions the previous versions returned by ListVersions.
+	List     []*T
+	Results  []models.BatchResult[T]
+	Affected int64
+	Entries  []audit.Entry
+	Versions []history.Version[T]
+}
+
+// Hook runs before or after an operation. An error returned by a before
+// hook stops the operation, and one returned by an after hook skips the
+// remaining hooks and is returned to the caller. After a write, which is
+// already stored, it is logged instead when the service has a logger.
+type Hook[T any, X string | uint] func(ctx context.Context, call *Call[T, X]) error
+
+type Option[T any, X string | uint] func(*genericService[T, X])
+
+// Before registers hooks that run, in order, before op.
+func Before[T any, X string | uint](op Operation, hooks ...Hook[T, X]) Option[T, X] {
+	return func(s *genericService[T, X]) {
+		s.before[op] = append(s.before[op], hooks...)
+	}
+}
+
+// After registers hooks that run, in order, after op succeeded.
+func After[T any, X string | uint](op Operation, hooks ...Hook[T, X]) Option[T, X] {
+	return func(s *genericService[T, X]) {
+		s.after[op] = append(s.after[op], hooks...)
+	}
+}
+
+// WithLogger reports the errors of after hooks of writes to log, and the
+// writes succeed, rather than returning them.
+func WithLogger[T any, X string | uint](log logger.Logger) Option[T, X] {
+	return func(s *genericService[T, X]) {
+		s.log = log
+	}
+}
+
+func runHooks[T any, X string | uint](ctx context.Context, hooks []Hook[T, X], call *Call[T, X]) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, call); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package tenancy
+
+import "context"
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying the tenant whose rows the
+// repositories built with repository.WithTenancy may read and write.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func TenantFrom(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
@@ -0,0 +1,21 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantFrom(t *testing.T) {
+	tenant, ok := TenantFrom(WithTenant(context.Background(), "acme"))
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenant)
+
+	_, ok = TenantFrom(WithTenant(context.Background(), ""))
+	assert.False(t, ok)
+	_, ok = TenantFrom(context.Background())
+	assert.False(t, ok)
+	_, ok = TenantFrom(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,188 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validatable is implemented by models with rules that struct tags cannot
+// express, such as checks across fields. Validate may return a
+// *models.ValidationError to report individual fields; any other error is
+// reported against the model as a whole.
+type Validatable interface {
+	Validate(ctx context.Context) error
+}
+
+var validate = validator.New(validator.WithRequiredStructEnabled())
+
+// Struct checks model against its `validate` struct tags and then, when it
+// implements Validatable, its Validate method. Every failure is collected
+// into a single *models.ValidationError. Context errors returned by
+// Validate are passed through unchanged.
+func Struct(ctx context.Context, model interface{}) error {
+	fields, err := tagErrors(model, validate.StructCtx(ctx, model))
+	if err != nil {
+		return err
+	}
+
+	if v, ok := model.(Validatable); ok {
+		if err := v.Validate(ctx); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			var verr *models.ValidationError
+			if errors.As(err, &verr) {
+				fields = append(fields, verr.Fields...)
+			} else {
+				fields = append(fields, models.FieldError{Message: err.Error()})
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &models.ValidationError{Fields: fields}
+}
+
+// Fields checks only the named struct fields of model against their
+// `validate` tags, for writes that store those fields alone. Validate
+// methods, which need the whole model, are not run.
+func Fields(ctx context.Context, model interface{}, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	fields, err := tagErrors(model, validate.StructPartialCtx(ctx, model, names...))
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	return &models.ValidationError{Fields: fields}
+}
+
+// tagErrors converts the error of validating the tags of model into field
+// errors. Other errors are returned as they are.
+func tagErrors(model interface{}, err error) ([]models.FieldError, error) {
+	var invalid *validator.InvalidValidationError
+	if err == nil || errors.As(err, &invalid) {
+		return nil, nil
+	}
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, err
+	}
+	return fieldErrors(errs, reflect.TypeOf(model)), nil
+}
+
+// FromBinding converts the validation errors of gin's binding of model into
+// a *models.ValidationError. Other errors, such as malformed JSON, are
+// returned unchanged.
+func FromBinding(err error, model interface{}) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	return &models.ValidationError{Fields: fieldErrors(errs, reflect.TypeOf(model))}
+}
+
+func fieldErrors(errs validator.ValidationErrors, t reflect.Type) []models.FieldError {
+	fields := make([]models.FieldError, len(errs))
+	for i, e := range errs {
+		fields[i] = models.FieldError{
+			Field:   jsonPath(t, e.StructNamespace()),
+			Rule:    e.Tag(),
+			Param:   e.Param(),
+			Message: message(e),
+		}
+	}
+	return fields
+}
+
+// jsonPath rewrites a validator namespace such as "User.Address.City" with
+// the JSON member names of t, leaving out embedded structs, which
+// encoding/json flattens.
+func jsonPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 1 {
+		segments = segments[1:]
+	}
+
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		t = indirect(t)
+		var field reflect.StructField
+		found := false
+		if t != nil && t.Kind() == reflect.Struct {
+			field, found = t.FieldByName(name)
+		}
+		if !found {
+			path = append(path, segment)
+			t = nil
+			continue
+		}
+
+		t = field.Type
+		if index != "" {
+			t = indirect(t)
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case tag != "" && tag != "-":
+			name = tag
+		case field.Anonymous && index == "":
+			continue
+		}
+		path = append(path, name+index)
+	}
+	return strings.Join(path, ".")
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func message(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "min", "gte":
+		return "must be at least " + e.Param()
+	case "max", "lte":
+		return "must be at most " + e.Param()
+	case "gt":
+		return "must be greater than " + e.Param()
+	case "lt":
+		return "must be less than " + e.Param()
+	case "len":
+		return "must have length " + e.Param()
+	case "oneof":
+		return "must be one of " + e.Param()
+	}
+	if e.Param() != "" {
+		return fmt.Sprintf("failed the %s=%s rule", e.Tag(), e.Param())
+	}
+	return fmt.Sprintf("failed the %s rule", e.Tag())
+}
@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alvarotor/entitier-go/models"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type base struct {
+	Code string `json:"code" validate:"len=3"`
+}
+
+type customer struct {
+	base
+	Name    string   `json:"name" validate:"required"`
+	Address address  `json:"address"`
+	Tags    []string `json:"tags" validate:"dive,oneof=a b"`
+	Backup  *address `json:"backup,omitempty"`
+	Notes   string   `validate:"max=5"`
+	err     error
+}
+
+func (c customer) Validate(ctx context.Context) error {
+	return c.err
+}
+
+func TestStruct(t *testing.T) {
+	valid := customer{base: base{Code: "abc"}, Name: "n", Address: address{City: "c"}}
+	assert.NoError(t, Struct(context.Background(), &valid))
+
+	invalid := customer{
+		base:   base{Code: "ab"},
+		Tags:   []string{"a", "z"},
+		Backup: &address{},
+		Notes:  "too long",
+		err:    errors.New("name and code do not match"),
+	}
+	err := Struct(context.Background(), &invalid)
+
+	var verr *models.ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []models.FieldError{
+			{Field: "code", Rule: "len", Param: "3", Message: "must have length 3"},
+			{Field: "name", Rule: "required", Message: "is required"},
+			{Field: "address.city", Rule: "required", Message: "is required"},
+			{Field: "tags[1]", Rule: "oneof", Param: "a b", Message: "must be one of a b"},
+			{Field: "backup.city", Rule: "required", Message: "is required"},
+			{Field: "Notes", Rule: "max", Param: "5", Message: "must be at most 5"},
+			{Message: "name and code do not match"},
+		}, verr.Fields)
+	}
+	assert.ErrorIs(t, err, models.ErrValidation)
+}
+
+func TestStruct_ContextError(t *testing.T) {
+	model := customer{base: base{Code: "abc"}, Name: "n", Address: address{City: "c"}, err: context.Canceled}
+	err := Struct(context.Background(), &model)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestStruct_NotAStruct(t *testing.T) {
+	assert.NoError(t, Struct(context.Background(), "value"))
+}
+
+func TestFields(t *testing.T) {
+	model := customer{Notes: "too long", err: errors.New("not run")}
+	assert.NoError(t, Fields(context.Background(), &model))
+	assert.NoError(t, Fields(context.Background(), &model, "Tags"))
+
+	err := Fields(context.Background(), &model, "Notes")
+	var verr *models.ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []models.FieldError{
+			{Field: "Notes", Rule: "max", Param: "5", Message: "must be at most 5"},
+		}, verr.Fields)
+	}
+}
+
+func TestFromBinding(t *testing.T) {
+	type signup struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	var model signup
+	err := binding.Validator.ValidateStruct(&model)
+	var verr *models.ValidationError
+	if assert.ErrorAs(t, FromBinding(err, &model), &verr) {
+		assert.Equal(t, []models.FieldError{{Field: "email", Rule: "required", Message: "is required"}}, verr.Fields)
+	}
+
+	other := errors.New("unexpected EOF")
+	assert.Equal(t, other, FromBinding(other, &model))
+}